@@ -26,6 +26,7 @@ type AudioSpecificConfig struct {
 	ExtensionFrequency   int
 	SBRPresentFlag       bool
 	PSPresentFlag        bool
+	PCE                  *PCE // Program config element if ChannelConfiguration is 0
 }
 
 var frequencyTable = map[byte]int{
@@ -111,8 +112,19 @@ func DecodeAudioSpecificConfig(r io.Reader) (*AudioSpecificConfig, error) {
 	if audioObjectType != AAClc {
 		return nil, fmt.Errorf("Base audioObjectType is %d instead of AAC-LC (2)", audioObjectType)
 	}
-	//GASpecificConfig()
-	_ = br.Read(3) //GASpecificConfig
+	// GASpecificConfig
+	_ = br.Read(1)     // frameLengthFlag
+	if br.ReadFlag() { // dependsOnCoreCoder
+		_ = br.Read(14) // coreCoderDelay
+	}
+	_ = br.Read(1) // extensionFlag
+	if asc.ChannelConfiguration == 0 {
+		pce, err := decodePCE(br)
+		if err != nil {
+			return asc, fmt.Errorf("program config element: %w", err)
+		}
+		asc.PCE = pce
+	}
 	// Done (there may be trailing bits)
 	return asc, nil
 }
@@ -147,10 +159,27 @@ func (a *AudioSpecificConfig) Encode(w io.Writer) error {
 		bw.Write(AAClc, 5) // base audioObjectType
 	}
 	bw.Write(0x00, 3) // GASpecificConfig
+	if a.ChannelConfiguration == 0 && a.PCE != nil {
+		if err := a.PCE.encode(bw); err != nil {
+			return err
+		}
+	}
 	bw.Flush()
 	return bw.Error()
 }
 
+// EffectiveSampleRate - output sample rate after SBR upsampling.
+// For HE-AAC this is the extension frequency, which is normally double the base sampling frequency.
+func (a *AudioSpecificConfig) EffectiveSampleRate() uint32 {
+	if !a.SBRPresentFlag {
+		return uint32(a.SamplingFrequency)
+	}
+	if a.ExtensionFrequency != 0 {
+		return uint32(a.ExtensionFrequency)
+	}
+	return 2 * uint32(a.SamplingFrequency)
+}
+
 // getFrequency - either from 4-bit index or 24-bit value
 func getFrequency(br *bits.AccErrReader) (frequency int, ok bool) {
 	frequencyIndex := br.Read(4)
@@ -60,3 +60,22 @@ func TestAudioSpecificConfigEncodeDecode(t *testing.T) {
 	}
 
 }
+
+func TestEffectiveSampleRate(t *testing.T) {
+	testCases := []struct {
+		asc          AudioSpecificConfig
+		expectedRate uint32
+	}{
+		{AudioSpecificConfig{ObjectType: AAClc, ChannelConfiguration: 2, SamplingFrequency: 48000}, 48000},
+		{AudioSpecificConfig{ObjectType: HEAACv1, ChannelConfiguration: 2, SamplingFrequency: 24000,
+			ExtensionFrequency: 48000, SBRPresentFlag: true}, 48000},
+		{AudioSpecificConfig{ObjectType: HEAACv2, ChannelConfiguration: 1, SamplingFrequency: 22050,
+			SBRPresentFlag: true, PSPresentFlag: true}, 44100},
+	}
+	for _, tc := range testCases {
+		gotRate := tc.asc.EffectiveSampleRate()
+		if gotRate != tc.expectedRate {
+			t.Errorf("got rate %d instead of %d for %+v", gotRate, tc.expectedRate, tc.asc)
+		}
+	}
+}
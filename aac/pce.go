@@ -0,0 +1,194 @@
+package aac
+
+import (
+	"fmt"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// PCE - program_config_element according to ISO/IEC 14496-3 Section 4.4.1.1 Table 4.2.
+// It is present in the AudioSpecificConfig if channelConfiguration is 0 and gives
+// an arbitrary mapping of channel elements to front, side, back, and LFE positions.
+type PCE struct {
+	ElementInstanceTag         byte
+	ObjectType                 byte
+	SamplingFrequencyIndex     byte
+	FrontElements              []ChannelElement
+	SideElements               []ChannelElement
+	BackElements               []ChannelElement
+	LFEElementTags             []byte
+	AssocDataElementTags       []byte
+	CCElements                 []CCElement
+	MonoMixdownPresent         bool
+	MonoMixdownElementNumber   byte
+	StereoMixdownPresent       bool
+	StereoMixdownElementNumber byte
+	MatrixMixdownIdxPresent    bool
+	MatrixMixdownIdx           byte
+	PseudoSurroundEnable       bool
+	Comment                    []byte
+}
+
+// ChannelElement - front, side, or back element in PCE. A CPE (channel pair element) has two channels
+// and an SCE (single channel element) has one.
+type ChannelElement struct {
+	IsCPE     bool
+	TagSelect byte
+}
+
+// CCElement - coupling channel element in PCE
+type CCElement struct {
+	IsIndSw   bool
+	TagSelect byte
+}
+
+// NrChannels - total number of output channels given by front, side, back, and LFE elements
+func (p *PCE) NrChannels() int {
+	nr := len(p.LFEElementTags)
+	for _, elems := range [][]ChannelElement{p.FrontElements, p.SideElements, p.BackElements} {
+		for _, e := range elems {
+			if e.IsCPE {
+				nr += 2
+			} else {
+				nr++
+			}
+		}
+	}
+	return nr
+}
+
+// HasPCE - true if the channel layout is given by a program config element (channelConfiguration 0)
+func (a *AudioSpecificConfig) HasPCE() bool {
+	return a.ChannelConfiguration == 0 && a.PCE != nil
+}
+
+// PCEChannelMapping - program config element with the front, side, back, and LFE elements and their tags.
+// An error is returned if there is no PCE.
+func (a *AudioSpecificConfig) PCEChannelMapping() (*PCE, error) {
+	if !a.HasPCE() {
+		return nil, fmt.Errorf("no program config element (channelConfiguration %d)", a.ChannelConfiguration)
+	}
+	return a.PCE, nil
+}
+
+// decodePCE - decode program_config_element. Byte alignment is relative to the start of br.
+func decodePCE(br *bits.AccErrReader) (*PCE, error) {
+	p := &PCE{}
+	p.ElementInstanceTag = byte(br.Read(4))
+	p.ObjectType = byte(br.Read(2))
+	p.SamplingFrequencyIndex = byte(br.Read(4))
+	nrFront := int(br.Read(4))
+	nrSide := int(br.Read(4))
+	nrBack := int(br.Read(4))
+	nrLFE := int(br.Read(2))
+	nrAssocData := int(br.Read(3))
+	nrValidCC := int(br.Read(4))
+	p.MonoMixdownPresent = br.ReadFlag()
+	if p.MonoMixdownPresent {
+		p.MonoMixdownElementNumber = byte(br.Read(4))
+	}
+	p.StereoMixdownPresent = br.ReadFlag()
+	if p.StereoMixdownPresent {
+		p.StereoMixdownElementNumber = byte(br.Read(4))
+	}
+	p.MatrixMixdownIdxPresent = br.ReadFlag()
+	if p.MatrixMixdownIdxPresent {
+		p.MatrixMixdownIdx = byte(br.Read(2))
+		p.PseudoSurroundEnable = br.ReadFlag()
+	}
+	p.FrontElements = decodeChannelElements(br, nrFront)
+	p.SideElements = decodeChannelElements(br, nrSide)
+	p.BackElements = decodeChannelElements(br, nrBack)
+	for i := 0; i < nrLFE; i++ {
+		p.LFEElementTags = append(p.LFEElementTags, byte(br.Read(4)))
+	}
+	for i := 0; i < nrAssocData; i++ {
+		p.AssocDataElementTags = append(p.AssocDataElementTags, byte(br.Read(4)))
+	}
+	for i := 0; i < nrValidCC; i++ {
+		isIndSw := br.ReadFlag()
+		p.CCElements = append(p.CCElements, CCElement{IsIndSw: isIndSw, TagSelect: byte(br.Read(4))})
+	}
+	br.ByteAlign()
+	commentLen := int(br.Read(8))
+	if commentLen > 0 {
+		p.Comment = make([]byte, commentLen)
+		for i := range p.Comment {
+			p.Comment[i] = byte(br.Read(8))
+		}
+	}
+	if br.AccError() != nil {
+		return nil, br.AccError()
+	}
+	return p, nil
+}
+
+func decodeChannelElements(br *bits.AccErrReader, nr int) []ChannelElement {
+	var elems []ChannelElement
+	for i := 0; i < nr; i++ {
+		isCPE := br.ReadFlag()
+		elems = append(elems, ChannelElement{IsCPE: isCPE, TagSelect: byte(br.Read(4))})
+	}
+	return elems
+}
+
+// encode - write program_config_element. Byte alignment is relative to the start of bw.
+func (p *PCE) encode(bw *bits.Writer) error {
+	if len(p.FrontElements) > 15 || len(p.SideElements) > 15 || len(p.BackElements) > 15 ||
+		len(p.LFEElementTags) > 3 || len(p.AssocDataElementTags) > 7 || len(p.CCElements) > 15 ||
+		len(p.Comment) > 255 {
+		return fmt.Errorf("too many elements or too long comment in program config element")
+	}
+	bw.Write(uint(p.ElementInstanceTag), 4)
+	bw.Write(uint(p.ObjectType), 2)
+	bw.Write(uint(p.SamplingFrequencyIndex), 4)
+	bw.Write(uint(len(p.FrontElements)), 4)
+	bw.Write(uint(len(p.SideElements)), 4)
+	bw.Write(uint(len(p.BackElements)), 4)
+	bw.Write(uint(len(p.LFEElementTags)), 2)
+	bw.Write(uint(len(p.AssocDataElementTags)), 3)
+	bw.Write(uint(len(p.CCElements)), 4)
+	writeFlag(bw, p.MonoMixdownPresent)
+	if p.MonoMixdownPresent {
+		bw.Write(uint(p.MonoMixdownElementNumber), 4)
+	}
+	writeFlag(bw, p.StereoMixdownPresent)
+	if p.StereoMixdownPresent {
+		bw.Write(uint(p.StereoMixdownElementNumber), 4)
+	}
+	writeFlag(bw, p.MatrixMixdownIdxPresent)
+	if p.MatrixMixdownIdxPresent {
+		bw.Write(uint(p.MatrixMixdownIdx), 2)
+		writeFlag(bw, p.PseudoSurroundEnable)
+	}
+	for _, elems := range [][]ChannelElement{p.FrontElements, p.SideElements, p.BackElements} {
+		for _, e := range elems {
+			writeFlag(bw, e.IsCPE)
+			bw.Write(uint(e.TagSelect), 4)
+		}
+	}
+	for _, tag := range p.LFEElementTags {
+		bw.Write(uint(tag), 4)
+	}
+	for _, tag := range p.AssocDataElementTags {
+		bw.Write(uint(tag), 4)
+	}
+	for _, e := range p.CCElements {
+		writeFlag(bw, e.IsIndSw)
+		bw.Write(uint(e.TagSelect), 4)
+	}
+	bw.ByteAlign()
+	bw.Write(uint(len(p.Comment)), 8)
+	for _, c := range p.Comment {
+		bw.Write(uint(c), 8)
+	}
+	return bw.Error()
+}
+
+func writeFlag(bw *bits.Writer, flag bool) {
+	if flag {
+		bw.Write(1, 1)
+	} else {
+		bw.Write(0, 1)
+	}
+}
@@ -0,0 +1,62 @@
+package aac
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestPCE(t *testing.T) {
+	// 5.1 layout with front center SCE, front CPE, back CPE, and LFE
+	asc := AudioSpecificConfig{
+		ObjectType:           AAClc,
+		ChannelConfiguration: 0,
+		SamplingFrequency:    48000,
+		PCE: &PCE{
+			ObjectType:             1,
+			SamplingFrequencyIndex: 3,
+			FrontElements:          []ChannelElement{{IsCPE: false, TagSelect: 0}, {IsCPE: true, TagSelect: 0}},
+			BackElements:           []ChannelElement{{IsCPE: true, TagSelect: 1}},
+			LFEElementTags:         []byte{0},
+			Comment:                []byte("5.1"),
+		},
+	}
+	buf := &bytes.Buffer{}
+	if err := asc.Encode(buf); err != nil {
+		t.Fatal(err)
+	}
+	gotAsc, err := DecodeAudioSpecificConfig(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(*gotAsc, asc); diff != nil {
+		t.Errorf("Diff %v", diff)
+	}
+	if !gotAsc.HasPCE() {
+		t.Fatalf("no PCE after decode")
+	}
+	pce, err := gotAsc.PCEChannelMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pce.FrontElements) != 2 || len(pce.SideElements) != 0 || len(pce.BackElements) != 1 ||
+		len(pce.LFEElementTags) != 1 {
+		t.Errorf("got front/side/back/lfe counts %d/%d/%d/%d instead of 2/0/1/1", len(pce.FrontElements),
+			len(pce.SideElements), len(pce.BackElements), len(pce.LFEElementTags))
+	}
+	if pce.BackElements[0].TagSelect != 1 || !pce.BackElements[0].IsCPE {
+		t.Errorf("wrong back element %+v", pce.BackElements[0])
+	}
+	if pce.NrChannels() != 6 {
+		t.Errorf("got %d channels instead of 6", pce.NrChannels())
+	}
+
+	stereo := AudioSpecificConfig{ObjectType: AAClc, ChannelConfiguration: 2, SamplingFrequency: 48000}
+	if stereo.HasPCE() {
+		t.Errorf("stereo config should not have PCE")
+	}
+	if _, err = stereo.PCEChannelMapping(); err == nil {
+		t.Errorf("expected error for config without PCE")
+	}
+}
@@ -0,0 +1,107 @@
+package avc
+
+// levelLimits - limits per level according to ISO/IEC 14496-10 Table A-1
+type levelLimits struct {
+	levelIDC uint8
+	maxMBPS  uint32 // Max macroblock processing rate (MB/s)
+	maxFS    uint32 // Max frame size (MBs)
+	maxBR    uint32 // Max video bitrate (cpbBrVclFactor or cpbBrNalFactor bits/s)
+}
+
+// level1b - level_idc of level 1b in High profiles.
+// Baseline, Main, and Extended profiles signal level 1b as level_idc 11 with constraint_set3_flag.
+const level1b = 9
+
+var levelTable = []levelLimits{
+	{10, 1485, 99, 64},
+	{level1b, 1485, 99, 128},
+	{11, 3000, 396, 192},
+	{12, 6000, 396, 384},
+	{13, 11880, 396, 768},
+	{20, 11880, 396, 2000},
+	{21, 19800, 792, 4000},
+	{22, 20250, 1620, 4000},
+	{30, 40500, 1620, 10000},
+	{31, 108000, 3600, 14000},
+	{32, 216000, 5120, 20000},
+	{40, 245760, 8192, 20000},
+	{41, 245760, 8192, 50000},
+	{42, 522240, 8704, 50000},
+	{50, 589824, 22080, 135000},
+	{51, 983040, 36864, 240000},
+	{52, 2073600, 36864, 240000},
+	{60, 4177920, 139264, 240000},
+	{61, 8355840, 139264, 480000},
+	{62, 16711680, 139264, 800000},
+}
+
+// CheckLevel - check that the level_idc of the SPS is high enough for the resolution and frame rate.
+// The required level is the lowest level that allows the frame size, macroblock rate and,
+// if signaled by HRD parameters in the VUI, the bitrate. requiredLevel is 0 if no level is sufficient.
+// Level 1b is returned as level_idc 9, and is recognized in the SPS both as level_idc 9 and as
+// level_idc 11 with constraint_set3_flag in Baseline, Main, and Extended profiles.
+func CheckLevel(sps *SPS, fps float64) (ok bool, requiredLevel uint8) {
+	frameSizeInMbs := uint32((sps.Width+15)/16) * uint32((sps.Height+15)/16)
+	mbRate := float64(frameSizeInMbs) * fps
+	bitrate, brFactor := hrdBitrate(sps)
+	for _, l := range levelTable {
+		if frameSizeInMbs > l.maxFS || mbRate > float64(l.maxMBPS) {
+			continue
+		}
+		if bitrate > 0 && bitrate > uint64(l.maxBR)*uint64(brFactor) {
+			continue
+		}
+		return levelOrder(uint32(l.levelIDC)) <= levelOrder(spsLevelIDC(sps)), l.levelIDC
+	}
+	return false, 0
+}
+
+// spsLevelIDC - level_idc of sps with level 1b as level1b for all profiles
+func spsLevelIDC(sps *SPS) uint32 {
+	switch sps.Profile {
+	case 66, 77, 88:
+		if sps.Level == 11 && sps.ProfileCompatibility&0x10 != 0 { // constraint_set3_flag
+			return level1b
+		}
+	}
+	return sps.Level
+}
+
+// levelOrder - number for comparing levels, with level 1b between level 1 and level 1.1
+func levelOrder(levelIDC uint32) uint32 {
+	if levelIDC == level1b {
+		return 105
+	}
+	return levelIDC * 10
+}
+
+// hrdBitrate - max bitrate from NAL or VCL HRD parameters and the corresponding factor from Table A-2
+func hrdBitrate(sps *SPS) (bitrate uint64, brFactor uint32) {
+	vclFactor, nalFactor := uint32(1000), uint32(1200)
+	switch sps.Profile {
+	case 100:
+		vclFactor, nalFactor = 1250, 1500
+	case 110:
+		vclFactor, nalFactor = 3000, 3600
+	case 122, 244:
+		vclFactor, nalFactor = 4000, 4800
+	}
+	vui := sps.VUI
+	if vui == nil {
+		return 0, 0
+	}
+	hrd, brFactor := vui.NalHrdParameters, nalFactor
+	if hrd == nil {
+		hrd, brFactor = vui.VclHrdParameters, vclFactor
+	}
+	if hrd == nil {
+		return 0, 0
+	}
+	for _, entry := range hrd.CpbEntries {
+		br := uint64(entry.BitRateValueMinus1+1) << (6 + hrd.BitRateScale)
+		if br > bitrate {
+			bitrate = br
+		}
+	}
+	return bitrate, brFactor
+}
@@ -0,0 +1,54 @@
+package avc
+
+import "testing"
+
+func TestCheckLevel(t *testing.T) {
+	testCases := []struct {
+		width, height uint
+		fps           float64
+		level         uint32
+		wantedOK      bool
+		wantedLevel   uint8
+	}{
+		{1920, 1080, 60, 40, false, 42},
+		{1920, 1080, 60, 42, true, 42},
+		{1920, 1080, 30, 40, true, 40},
+		{1280, 720, 30, 31, true, 31},
+		{640, 360, 25, 40, true, 30},
+	}
+	for _, tc := range testCases {
+		sps := &SPS{Profile: 100, Level: tc.level, Width: tc.width, Height: tc.height}
+		ok, level := CheckLevel(sps, tc.fps)
+		if ok != tc.wantedOK || level != tc.wantedLevel {
+			t.Errorf("%dx%d@%.0f level %d: got %t, %d instead of %t, %d", tc.width, tc.height, tc.fps,
+				tc.level, ok, level, tc.wantedOK, tc.wantedLevel)
+		}
+	}
+	// QCIF@15 with a 100kbps VCL HRD bitrate requires level 1b
+	for _, tc := range []struct {
+		profile, profileCompatibility, level uint32
+		wantedOK                             bool
+	}{
+		{66, 0x10, 11, true},  // Baseline level 1b
+		{66, 0x00, 11, true},  // Baseline level 1.1
+		{66, 0x00, 10, false}, // Baseline level 1
+		{100, 0x00, 9, true},  // High level 1b
+	} {
+		sps := &SPS{Profile: tc.profile, ProfileCompatibility: tc.profileCompatibility, Level: tc.level,
+			Width: 176, Height: 144, VUI: &VUIParameters{VclHrdParameters: &HrdParameters{BitRateScale: 0,
+				CpbEntries: []CpbEntry{{BitRateValueMinus1: 100000/64 - 1}}}}}
+		ok, level := CheckLevel(sps, 15)
+		if ok != tc.wantedOK || level != level1b {
+			t.Errorf("profile %d level %d: got %t, %d instead of %t, %d", tc.profile, tc.level, ok, level,
+				tc.wantedOK, level1b)
+		}
+	}
+	// 1080p30 with a 35Mbps NAL HRD bitrate requires level 4.1 for High profile
+	sps := &SPS{Profile: 100, Level: 40, Width: 1920, Height: 1080,
+		VUI: &VUIParameters{NalHrdParameters: &HrdParameters{BitRateScale: 0,
+			CpbEntries: []CpbEntry{{BitRateValueMinus1: 35000000/64 - 1}}}}}
+	ok, level := CheckLevel(sps, 30)
+	if ok || level != 41 {
+		t.Errorf("got %t, %d instead of false, 41 for 35Mbps", ok, level)
+	}
+}
@@ -0,0 +1,156 @@
+package avc
+
+// POCState - state carried between pictures in decoding order for picture order count computation.
+// The zero value (or nil) is the state before the first picture.
+type POCState struct {
+	PrevPicOrderCntMsb int    // pic_order_cnt_type 0: PicOrderCntMsb of previous reference picture
+	PrevPicOrderCntLsb int    // pic_order_cnt_type 0: pic_order_cnt_lsb of previous reference picture
+	PrevFrameNumOffset int    // pic_order_cnt_type 1 and 2: FrameNumOffset of previous picture
+	PrevFrameNum       uint32 // pic_order_cnt_type 1 and 2: frame_num of previous picture
+}
+
+// ComputePOC - picture order count of the picture with sliceHeader as defined in ISO/IEC 14496-10 Section 8.2.1
+// for pic_order_cnt_type 0, 1, and 2. prevState is the state returned for the previous picture in decoding order,
+// or nil for the first picture. For frames, the smaller of the top and bottom field order counts is returned.
+// The slice header must come from ParseSliceHeader so that NAL unit type, nal_ref_idc, and mmco 5 are known.
+func (s *SPS) ComputePOC(sliceHeader *SliceHeader, prevState *POCState) (poc int, newState *POCState) {
+	sh := sliceHeader
+	prev := POCState{}
+	if prevState != nil {
+		prev = *prevState
+	}
+	state := prev
+	isIDR := sh.NaluType == NALU_IDR
+	isRef := sh.NalRefIDC != 0
+	var top, bottom int
+	switch s.PicOrderCntType {
+	case 0:
+		if isIDR {
+			prev.PrevPicOrderCntMsb, prev.PrevPicOrderCntLsb = 0, 0
+		}
+		maxPicOrderCntLsb := 1 << (s.Log2MaxPicOrderCntLsbMinus4 + 4)
+		lsb := int(sh.PicOrderCntLsb)
+		msb := prev.PrevPicOrderCntMsb
+		switch {
+		case lsb < prev.PrevPicOrderCntLsb && prev.PrevPicOrderCntLsb-lsb >= maxPicOrderCntLsb/2:
+			msb += maxPicOrderCntLsb
+		case lsb > prev.PrevPicOrderCntLsb && lsb-prev.PrevPicOrderCntLsb > maxPicOrderCntLsb/2:
+			msb -= maxPicOrderCntLsb
+		}
+		top = msb + lsb
+		bottom = top + int(sh.DeltaPicOrderCntBottom)
+		if sh.BottomFieldFlag {
+			bottom = top
+		}
+		if isRef {
+			state.PrevPicOrderCntMsb, state.PrevPicOrderCntLsb = msb, lsb
+		}
+	case 1, 2:
+		maxFrameNum := 1 << (s.Log2MaxFrameNumMinus4 + 4)
+		frameNumOffset := 0
+		if !isIDR {
+			frameNumOffset = prev.PrevFrameNumOffset
+			if prev.PrevFrameNum > sh.FrameNum {
+				frameNumOffset += maxFrameNum
+			}
+		}
+		if s.PicOrderCntType == 1 {
+			top, bottom = s.pocType1(sh, frameNumOffset)
+		} else {
+			tempPicOrderCnt := 0
+			switch {
+			case isIDR:
+			case !isRef:
+				tempPicOrderCnt = 2*(frameNumOffset+int(sh.FrameNum)) - 1
+			default:
+				tempPicOrderCnt = 2 * (frameNumOffset + int(sh.FrameNum))
+			}
+			top, bottom = tempPicOrderCnt, tempPicOrderCnt
+		}
+		state.PrevFrameNumOffset, state.PrevFrameNum = frameNumOffset, sh.FrameNum
+	}
+
+	switch {
+	case !sh.FieldPicFlag:
+		poc = top
+		if bottom < top {
+			poc = bottom
+		}
+	case sh.BottomFieldFlag:
+		poc = bottom
+	default:
+		poc = top
+	}
+
+	if sh.HasMMCO5 {
+		// After mmco 5, the picture is treated as having POC 0 relative to following pictures
+		state.PrevPicOrderCntMsb = 0
+		state.PrevPicOrderCntLsb = 0
+		if !sh.BottomFieldFlag {
+			state.PrevPicOrderCntLsb = top - poc
+		}
+		state.PrevFrameNumOffset, state.PrevFrameNum = 0, 0
+	}
+	return poc, &state
+}
+
+// pocType1 - top and bottom field order counts for pic_order_cnt_type 1
+func (s *SPS) pocType1(sh *SliceHeader, frameNumOffset int) (top, bottom int) {
+	nrRefFramesInCycle := len(s.RefFramesInPicOrderCntCycle)
+	absFrameNum := 0
+	if nrRefFramesInCycle != 0 {
+		absFrameNum = frameNumOffset + int(sh.FrameNum)
+	}
+	if sh.NalRefIDC == 0 && absFrameNum > 0 {
+		absFrameNum--
+	}
+	expectedPicOrderCnt := 0
+	if absFrameNum > 0 {
+		picOrderCntCycleCnt := (absFrameNum - 1) / nrRefFramesInCycle
+		frameNumInCycle := (absFrameNum - 1) % nrRefFramesInCycle
+		expectedDeltaPerCycle := 0
+		for _, offset := range s.RefFramesInPicOrderCntCycle {
+			expectedDeltaPerCycle += int(offset)
+		}
+		expectedPicOrderCnt = picOrderCntCycleCnt * expectedDeltaPerCycle
+		for i := 0; i <= frameNumInCycle; i++ {
+			expectedPicOrderCnt += int(s.RefFramesInPicOrderCntCycle[i])
+		}
+	}
+	if sh.NalRefIDC == 0 {
+		expectedPicOrderCnt += int(s.OffsetForNonRefPic)
+	}
+	switch {
+	case !sh.FieldPicFlag:
+		top = expectedPicOrderCnt + int(sh.DeltaPicOrderCnt[0])
+		bottom = top + int(s.OffsetForTopToBottomField) + int(sh.DeltaPicOrderCnt[1])
+	case !sh.BottomFieldFlag:
+		top = expectedPicOrderCnt + int(sh.DeltaPicOrderCnt[0])
+		bottom = top
+	default:
+		bottom = expectedPicOrderCnt + int(s.OffsetForTopToBottomField) + int(sh.DeltaPicOrderCnt[0])
+		top = bottom
+	}
+	return top, bottom
+}
+
+// IsNewPicture - true if the slice with sliceHeader starts a new primary coded picture (and access unit)
+// compared to the previous slice with prevSliceHeader, as detected by the rules in ISO/IEC 14496-10 Section 7.4.1.2.4.
+// A nil prevSliceHeader always gives true.
+func IsNewPicture(prevSliceHeader, sliceHeader *SliceHeader) bool {
+	p, c := prevSliceHeader, sliceHeader
+	if p == nil {
+		return true
+	}
+	prevIDR, currIDR := p.NaluType == NALU_IDR, c.NaluType == NALU_IDR
+	return p.FrameNum != c.FrameNum ||
+		p.PicParamID != c.PicParamID ||
+		p.FieldPicFlag != c.FieldPicFlag ||
+		p.BottomFieldFlag != c.BottomFieldFlag ||
+		(p.NalRefIDC == 0) != (c.NalRefIDC == 0) ||
+		p.PicOrderCntLsb != c.PicOrderCntLsb ||
+		p.DeltaPicOrderCntBottom != c.DeltaPicOrderCntBottom ||
+		p.DeltaPicOrderCnt != c.DeltaPicOrderCnt ||
+		prevIDR != currIDR ||
+		(prevIDR && currIDR && p.IDRPicID != c.IDRPicID)
+}
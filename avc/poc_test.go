@@ -0,0 +1,120 @@
+package avc
+
+import "testing"
+
+func TestComputePOCType0(t *testing.T) {
+	sps := &SPS{PicOrderCntType: 0, Log2MaxPicOrderCntLsbMinus4: 0, FrameMbsOnlyFlag: true}
+	// Decode order I P B B P B B P B B with 2 * display index as POC and wrap of pic_order_cnt_lsb at 16
+	testCases := []struct {
+		naluType  NaluType
+		nalRefIDC uint32
+		wantedPOC int
+	}{
+		{NALU_IDR, 3, 0},
+		{NALU_NON_IDR, 2, 6},
+		{NALU_NON_IDR, 0, 2},
+		{NALU_NON_IDR, 0, 4},
+		{NALU_NON_IDR, 2, 12},
+		{NALU_NON_IDR, 0, 8},
+		{NALU_NON_IDR, 0, 10},
+		{NALU_NON_IDR, 2, 18},
+		{NALU_NON_IDR, 0, 14},
+		{NALU_NON_IDR, 0, 16},
+		{NALU_IDR, 3, 0},
+	}
+	var state *POCState
+	for i, tc := range testCases {
+		sh := &SliceHeader{
+			NaluType:       tc.naluType,
+			NalRefIDC:      tc.nalRefIDC,
+			PicOrderCntLsb: uint32(tc.wantedPOC % 16),
+		}
+		var poc int
+		poc, state = sps.ComputePOC(sh, state)
+		if poc != tc.wantedPOC {
+			t.Errorf("picture %d: got POC %d instead of %d", i, poc, tc.wantedPOC)
+		}
+	}
+}
+
+func TestComputePOCType1(t *testing.T) {
+	offsetForNonRefPic := -2
+	sps := &SPS{PicOrderCntType: 1, Log2MaxFrameNumMinus4: 0, FrameMbsOnlyFlag: true,
+		OffsetForNonRefPic: uint(offsetForNonRefPic), RefFramesInPicOrderCntCycle: []uint{4}}
+	// Decode order I P B P B with frame_num incremented after reference pictures
+	testCases := []struct {
+		naluType  NaluType
+		nalRefIDC uint32
+		frameNum  uint32
+		wantedPOC int
+	}{
+		{NALU_IDR, 3, 0, 0},
+		{NALU_NON_IDR, 2, 1, 4},
+		{NALU_NON_IDR, 0, 2, 2},
+		{NALU_NON_IDR, 2, 2, 8},
+		{NALU_NON_IDR, 0, 3, 6},
+	}
+	var state *POCState
+	for i, tc := range testCases {
+		sh := &SliceHeader{NaluType: tc.naluType, NalRefIDC: tc.nalRefIDC, FrameNum: tc.frameNum}
+		var poc int
+		poc, state = sps.ComputePOC(sh, state)
+		if poc != tc.wantedPOC {
+			t.Errorf("picture %d: got POC %d instead of %d", i, poc, tc.wantedPOC)
+		}
+	}
+}
+
+func TestComputePOCType2(t *testing.T) {
+	sps := &SPS{PicOrderCntType: 2, Log2MaxFrameNumMinus4: 0, FrameMbsOnlyFlag: true}
+	var state *POCState
+	// frame_num wraps at 16, so the POC continues increasing through FrameNumOffset
+	for i := 0; i < 20; i++ {
+		sh := &SliceHeader{NaluType: NALU_NON_IDR, NalRefIDC: 1, FrameNum: uint32(i % 16)}
+		if i == 0 {
+			sh.NaluType = NALU_IDR
+		}
+		var poc int
+		poc, state = sps.ComputePOC(sh, state)
+		if poc != 2*i {
+			t.Errorf("picture %d: got POC %d instead of %d", i, poc, 2*i)
+		}
+	}
+	sh := &SliceHeader{NaluType: NALU_NON_IDR, NalRefIDC: 0, FrameNum: 4}
+	if poc, _ := sps.ComputePOC(sh, state); poc != 2*(16+4)-1 {
+		t.Errorf("got POC %d for non-reference picture instead of %d", poc, 2*(16+4)-1)
+	}
+	// mmco 5 resets the POC for the following pictures
+	sh = &SliceHeader{NaluType: NALU_NON_IDR, NalRefIDC: 1, FrameNum: 4, HasMMCO5: true}
+	_, state = sps.ComputePOC(sh, state)
+	sh = &SliceHeader{NaluType: NALU_NON_IDR, NalRefIDC: 1, FrameNum: 1}
+	if poc, _ := sps.ComputePOC(sh, state); poc != 2 {
+		t.Errorf("got POC %d after mmco 5 instead of 2", poc)
+	}
+}
+
+func TestIsNewPicture(t *testing.T) {
+	idr := &SliceHeader{NaluType: NALU_IDR, NalRefIDC: 3, IDRPicID: 0}
+	idrSecondSlice := &SliceHeader{NaluType: NALU_IDR, NalRefIDC: 3, IDRPicID: 0, FirstMBInSlice: 100}
+	p := &SliceHeader{NaluType: NALU_NON_IDR, NalRefIDC: 2, FrameNum: 1, PicOrderCntLsb: 6}
+	b := &SliceHeader{NaluType: NALU_NON_IDR, NalRefIDC: 0, FrameNum: 2, PicOrderCntLsb: 2}
+	b2 := &SliceHeader{NaluType: NALU_NON_IDR, NalRefIDC: 0, FrameNum: 2, PicOrderCntLsb: 4}
+	nextIDR := &SliceHeader{NaluType: NALU_IDR, NalRefIDC: 3, IDRPicID: 1}
+	testCases := []struct {
+		prev, curr *SliceHeader
+		wanted     bool
+	}{
+		{nil, idr, true},
+		{idr, idrSecondSlice, false},
+		{idrSecondSlice, p, true},
+		{p, b, true},
+		{b, b2, true},
+		{b2, b2, false},
+		{idr, nextIDR, true},
+	}
+	for i, tc := range testCases {
+		if got := IsNewPicture(tc.prev, tc.curr); got != tc.wanted {
+			t.Errorf("case %d: got %t instead of %t", i, got, tc.wanted)
+		}
+	}
+}
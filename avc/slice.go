@@ -88,6 +88,8 @@ func GetSliceTypeFromNALU(data []byte) (sliceType SliceType, err error) {
 }
 
 type SliceHeader struct {
+	NaluType                      NaluType
+	NalRefIDC                     uint32
 	SliceType                     SliceType
 	FirstMBInSlice                uint32
 	PicParamID                    uint32
@@ -127,8 +129,12 @@ type SliceHeader struct {
 	LongTermReferenceFlag         bool
 	SPForSwitchFlag               bool
 	AdaptiveRefPicMarkingModeFlag bool
+	HasMMCO5                      bool // memory_management_control_operation 5 present
 }
 
+// ParseSliceHeader - parse slice header of a slice NAL unit.
+// The PPS is looked up in ppsMap using pic_parameter_set_id and the SPS in spsMap
+// using the seq_parameter_set_id of that PPS.
 func ParseSliceHeader(nalu []byte, spsMap map[uint32]*SPS, ppsMap map[uint32]*PPS) (*SliceHeader, error) {
 	sh := SliceHeader{}
 	buf := bytes.NewBuffer(nalu)
@@ -144,6 +150,8 @@ func ParseSliceHeader(nalu []byte, spsMap map[uint32]*SPS, ppsMap map[uint32]*PP
 		return nil, err
 	}
 	nalRefIDC := (nalHdr >> 5) & 0x3
+	sh.NaluType = naluType
+	sh.NalRefIDC = uint32(nalRefIDC)
 	sh.FirstMBInSlice = uint32(r.ReadExpGolomb())
 	sh.SliceType = SliceType(r.ReadExpGolomb())
 	sh.PicParamID = uint32(r.ReadExpGolomb())
@@ -151,10 +159,10 @@ func ParseSliceHeader(nalu []byte, spsMap map[uint32]*SPS, ppsMap map[uint32]*PP
 	if !ok {
 		return nil, fmt.Errorf("pps ID %d unknown", sh.PicParamID)
 	}
-	spsID := pps.PicParameterSetID
-	sps, ok := spsMap[uint32(spsID)]
+	sh.SeqParamID = pps.SeqParameterSetID
+	sps, ok := spsMap[sh.SeqParamID]
 	if !ok {
-		return nil, fmt.Errorf("sps ID %d unknown", spsID)
+		return nil, fmt.Errorf("sps ID %d unknown", sh.SeqParamID)
 	}
 	if sps.SeparateColourPlaneFlag {
 		sh.ColorPlaneID = uint32(r.Read(2))
@@ -215,10 +223,8 @@ func ParseSliceHeader(nalu []byte, spsMap map[uint32]*SPS, ppsMap map[uint32]*PP
 					sh.AbsDiffPicNumMinus1 = uint32(r.ReadExpGolomb())
 				case 2:
 					sh.LongTermPicNum = uint32(r.ReadExpGolomb())
-				case 3:
-					break
 				}
-				if r.AccError() != nil {
+				if sh.ModificationOfPicNumsIDC == 3 || r.AccError() != nil {
 					break
 				}
 			}
@@ -234,7 +240,8 @@ func ParseSliceHeader(nalu []byte, spsMap map[uint32]*SPS, ppsMap map[uint32]*PP
 					sh.AbsDiffPicNumMinus1 = uint32(r.ReadExpGolomb())
 				case 2:
 					sh.LongTermPicNum = uint32(r.ReadExpGolomb())
-				case 3:
+				}
+				if sh.ModificationOfPicNumsIDC == 3 || r.AccError() != nil {
 					break
 				}
 			}
@@ -312,7 +319,10 @@ func ParseSliceHeader(nalu []byte, spsMap map[uint32]*SPS, ppsMap map[uint32]*PP
 						sh.LongTermFramIdx = uint32(r.ReadExpGolomb())
 					case 4:
 						sh.MaxLongTermFrameIdxPlus1 = uint32(r.ReadExpGolomb())
-					case 0:
+					case 5:
+						sh.HasMMCO5 = true
+					}
+					if memoryManagementControlOperation == 0 || r.AccError() != nil {
 						break
 					}
 				}
@@ -1,11 +1,13 @@
 package avc
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"testing"
 
+	"github.com/edgeware/mp4ff/bits"
 	"github.com/go-test/deep"
 )
 
@@ -25,6 +27,8 @@ func TestSliceTypeParser(t *testing.T) {
 
 func TestParseSliceHeader(t *testing.T) {
 	wantedHdr := SliceHeader{
+		NaluType:               NALU_IDR,
+		NalRefIDC:              3,
 		SliceType:              7,
 		SliceQPDelta:           6,
 		SliceAlphaC0OffsetDiv2: -3,
@@ -65,3 +69,76 @@ func TestParseSliceHeader(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+// writeSignedGolomb - write se(v) value
+func writeSignedGolomb(w *bits.EBSPWriter, v int) {
+	if v > 0 {
+		w.WriteExpGolomb(uint(2*v - 1))
+	} else {
+		w.WriteExpGolomb(uint(-2 * v))
+	}
+}
+
+// createPSliceNalu - create non-reference P slice NAL unit referencing ppsID
+func createPSliceNalu(t *testing.T, ppsID uint, frameNumBits int, cabac bool) []byte {
+	t.Helper()
+	buf := bytes.Buffer{}
+	w := bits.NewEBSPWriter(&buf)
+	w.Write(0x01, 8)         // NAL header: nal_ref_idc = 0, type = 1 (non-IDR slice)
+	w.WriteExpGolomb(0)      // first_mb_in_slice
+	w.WriteExpGolomb(5)      // slice_type P (all slices)
+	w.WriteExpGolomb(ppsID)  // pic_parameter_set_id
+	w.Write(3, frameNumBits) // frame_num
+	w.Write(0, 1)            // num_ref_idx_active_override_flag
+	w.Write(0, 1)            // ref_pic_list_modification_flag_l0
+	if cabac {
+		w.WriteExpGolomb(2) // cabac_init_idc
+	}
+	writeSignedGolomb(w, -2) // slice_qp_delta
+	w.WriteRbspTrailingBits()
+	if w.AccError() != nil {
+		t.Fatal(w.AccError())
+	}
+	return buf.Bytes()
+}
+
+func TestParseSliceHeaderWithMultiplePPS(t *testing.T) {
+	// PPS IDs and SPS IDs are crossed to verify that the SPS is found via the PPS
+	spsMap := map[uint32]*SPS{
+		0: {ParameterID: 0, Log2MaxFrameNumMinus4: 4, PicOrderCntType: 2, FrameMbsOnlyFlag: true, ChromaFormatIDC: 1},
+		1: {ParameterID: 1, Log2MaxFrameNumMinus4: 0, PicOrderCntType: 2, FrameMbsOnlyFlag: true, ChromaFormatIDC: 1},
+	}
+	ppsMap := map[uint32]*PPS{
+		0: {PicParameterSetID: 0, SeqParameterSetID: 1, EntropyCodingModeFlag: false},
+		1: {PicParameterSetID: 1, SeqParameterSetID: 0, EntropyCodingModeFlag: true},
+	}
+	testCases := []struct {
+		ppsID        uint
+		frameNumBits int
+		cabac        bool
+		wantedSpsID  uint32
+		wantedCabac  uint32
+	}{
+		{0, 4, false, 1, 0},
+		{1, 8, true, 0, 2},
+	}
+	for _, tc := range testCases {
+		nalu := createPSliceNalu(t, tc.ppsID, tc.frameNumBits, tc.cabac)
+		sh, err := ParseSliceHeader(nalu, spsMap, ppsMap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sh.PicParamID != uint32(tc.ppsID) || sh.SeqParamID != tc.wantedSpsID {
+			t.Errorf("got pps %d sps %d instead of %d and %d", sh.PicParamID, sh.SeqParamID, tc.ppsID, tc.wantedSpsID)
+		}
+		if sh.FrameNum != 3 {
+			t.Errorf("pps %d: got frame_num %d instead of 3", tc.ppsID, sh.FrameNum)
+		}
+		if sh.CabacInitIDC != tc.wantedCabac {
+			t.Errorf("pps %d: got cabac_init_idc %d instead of %d", tc.ppsID, sh.CabacInitIDC, tc.wantedCabac)
+		}
+		if sh.SliceQPDelta != -2 {
+			t.Errorf("pps %d: got slice_qp_delta %d instead of -2", tc.ppsID, sh.SliceQPDelta)
+		}
+	}
+}
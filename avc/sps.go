@@ -33,9 +33,9 @@ type SPS struct {
 	PicOrderCntType                 uint
 	Log2MaxPicOrderCntLsbMinus4     uint
 	DeltaPicOrderAlwaysZeroFlag     bool
-	OffsetForNonRefPic              uint
-	OffsetForTopToBottomField       uint
-	RefFramesInPicOrderCntCycle     []uint
+	OffsetForNonRefPic              uint   // Signed value, use int(OffsetForNonRefPic)
+	OffsetForTopToBottomField       uint   // Signed value, use int(OffsetForTopToBottomField)
+	RefFramesInPicOrderCntCycle     []uint // Signed values, use int() for each value
 	NumRefFrames                    uint
 	GapsInFrameNumValueAllowedFlag  bool
 	FrameMbsOnlyFlag                bool
@@ -177,12 +177,12 @@ func ParseSPSNALUnit(data []byte, parseVUIBeyondAspectRatio bool) (*SPS, error)
 		sps.Log2MaxPicOrderCntLsbMinus4 = reader.ReadExpGolomb()
 	} else if sps.PicOrderCntType == 1 {
 		sps.DeltaPicOrderAlwaysZeroFlag = reader.ReadFlag()
-		sps.OffsetForNonRefPic = reader.ReadExpGolomb()
-		sps.OffsetForTopToBottomField = reader.ReadExpGolomb()
+		sps.OffsetForNonRefPic = uint(reader.ReadSignedGolomb())
+		sps.OffsetForTopToBottomField = uint(reader.ReadSignedGolomb())
 		numRefFramesInPicOrderCntCycle := reader.ReadExpGolomb()
 		sps.RefFramesInPicOrderCntCycle = make([]uint, numRefFramesInPicOrderCntCycle)
 		for i := 0; i < int(numRefFramesInPicOrderCntCycle); i++ {
-			sps.RefFramesInPicOrderCntCycle[i] = reader.ReadExpGolomb()
+			sps.RefFramesInPicOrderCntCycle[i] = uint(reader.ReadSignedGolomb())
 		}
 	}
 
@@ -74,6 +74,13 @@ func (r *AccErrReader) ReadVInt(n int) int {
 	return ival
 }
 
+// ByteAlign - skip bits up to the next byte boundary
+func (r *AccErrReader) ByteAlign() {
+	if r.nrBits > 0 {
+		_ = r.Read(r.nrBits)
+	}
+}
+
 // ReadRemainingBytes - read remaining bytes if byte-aligned
 func (r *AccErrReader) ReadRemainingBytes() []byte {
 	if r.err != nil {
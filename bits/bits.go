@@ -59,6 +59,13 @@ func (w *Writer) Flush() {
 	}
 }
 
+// ByteAlign - write zero bits up to the next byte boundary
+func (w *Writer) ByteAlign() {
+	if w.n > 0 {
+		w.Write(0, 8-w.n)
+	}
+}
+
 // Error - error that has occurred and stopped writing
 func (w *Writer) Error() error {
 	return w.err
@@ -0,0 +1,47 @@
+package hevc
+
+import "math"
+
+// levelLimits - general tier and level limits according to ISO/IEC 23008-2 Table A.8 and A.9
+type levelLimits struct {
+	levelIDC  uint8  // 30 times the level number
+	maxLumaPs uint32 // Max luma picture size (samples)
+	maxLumaSr uint64 // Max luma sample rate (samples/s)
+}
+
+var levelTable = []levelLimits{
+	{30, 36864, 552960},
+	{60, 122880, 3686400},
+	{63, 245760, 7372800},
+	{90, 552960, 16588800},
+	{93, 983040, 33177600},
+	{120, 2228224, 66846720},
+	{123, 2228224, 133693440},
+	{150, 8912896, 267386880},
+	{153, 8912896, 534773760},
+	{156, 8912896, 1069547520},
+	{180, 35651584, 1069547520},
+	{183, 35651584, 2139095040},
+	{186, 35651584, 4278190080},
+}
+
+// CheckLevel - check that the general_level_idc of the SPS is high enough for the resolution and frame rate.
+// The required level (30 times the level number) is the lowest level that allows the picture size,
+// picture dimensions, and luma sample rate. requiredLevel is 0 if no level is sufficient.
+func CheckLevel(sps *SPS, fps float64) (ok bool, requiredLevel uint8) {
+	width := uint64(sps.PicWidthInLumaSamples)
+	height := uint64(sps.PicHeightInLumaSamples)
+	lumaPs := width * height
+	lumaSr := float64(lumaPs) * fps
+	for _, l := range levelTable {
+		maxDim := uint64(math.Sqrt(float64(l.maxLumaPs) * 8))
+		if lumaPs > uint64(l.maxLumaPs) || width > maxDim || height > maxDim {
+			continue
+		}
+		if lumaSr > float64(l.maxLumaSr) {
+			continue
+		}
+		return l.levelIDC <= sps.ProfileTierLevel.GeneralLevelIDC, l.levelIDC
+	}
+	return false, 0
+}
@@ -0,0 +1,28 @@
+package hevc
+
+import "testing"
+
+func TestCheckLevel(t *testing.T) {
+	testCases := []struct {
+		width, height uint32
+		fps           float64
+		level         byte
+		wantedOK      bool
+		wantedLevel   uint8
+	}{
+		{1920, 1080, 60, 120, false, 123},
+		{1920, 1080, 60, 123, true, 123},
+		{1920, 1080, 30, 120, true, 120},
+		{3840, 2160, 60, 153, true, 153},
+		{3840, 2160, 30, 120, false, 150},
+	}
+	for _, tc := range testCases {
+		sps := &SPS{PicWidthInLumaSamples: tc.width, PicHeightInLumaSamples: tc.height,
+			ProfileTierLevel: ProfileTierLevel{GeneralLevelIDC: tc.level}}
+		ok, level := CheckLevel(sps, tc.fps)
+		if ok != tc.wantedOK || level != tc.wantedLevel {
+			t.Errorf("%dx%d@%.0f level %d: got %t, %d instead of %t, %d", tc.width, tc.height, tc.fps,
+				tc.level, ok, level, tc.wantedOK, tc.wantedLevel)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// AinfBox - Asset Information Box (ainf)
+// Defined in DECE Common File Format Section 2.2.5 and used by DASH-IF for asset identification.
+//
+// Contained in : File
+type AinfBox struct {
+	Version        byte
+	Flags          uint32
+	ProfileVersion string // 4 characters
+	APID           string // Asset Physical Identifier
+	Children       []Box
+}
+
+// AddChild - Add a child box
+func (b *AinfBox) AddChild(box Box) {
+	b.Children = append(b.Children, box)
+}
+
+// DecodeAinf - box-specific decode
+func DecodeAinf(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeAinfSR(hdr, startPos, sr)
+}
+
+// DecodeAinfSR - box-specific decode
+func DecodeAinfSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := AinfBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.ProfileVersion = sr.ReadFixedLengthString(4)
+	b.APID = sr.ReadZeroTerminatedString(hdr.payloadLen() - 8)
+	if sr.AccError() != nil {
+		return nil, fmt.Errorf("decode ainf: %w", sr.AccError())
+	}
+	pos := startPos + uint64(hdr.Hdrlen) + 8 + uint64(len(b.APID)) + 1
+	endPos := startPos + hdr.Size
+	for pos < endPos {
+		box, err := DecodeBoxSR(pos, sr)
+		if err != nil {
+			return nil, fmt.Errorf("decode ainf child: %w", err)
+		}
+		b.AddChild(box)
+		pos += box.Size()
+	}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *AinfBox) Type() string {
+	return "ainf"
+}
+
+// Size - return calculated size
+func (b *AinfBox) Size() uint64 {
+	return uint64(boxHeaderSize+8+len(b.APID)+1) + containerSize(b.Children) - boxHeaderSize
+}
+
+// GetChildren - list of child boxes
+func (b *AinfBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write box to w
+func (b *AinfBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *AinfBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteString(b.ProfileVersion, false)
+	sw.WriteString(b.APID, true)
+	for _, c := range b.Children {
+		err = c.EncodeSW(sw)
+		if err != nil {
+			return err
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *AinfBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - profileVersion: %q", b.ProfileVersion)
+	bd.write(" - APID: %q", b.APID)
+	if bd.err != nil {
+		return bd.err
+	}
+	for _, c := range b.Children {
+		err := c.Info(w, specificBoxLevels, indent+indentStep, indentStep)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
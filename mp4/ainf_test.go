@@ -0,0 +1,37 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAinf(t *testing.T) {
+	ainf := &AinfBox{ProfileVersion: "hd  ", APID: "urn:dece:apid:org:dece:0123456789"}
+	boxDiffAfterEncodeAndDecode(t, ainf)
+	ainf.AddChild(&FreeBox{Name: "free", notDecoded: []byte{0, 1}})
+	boxDiffAfterEncodeAndDecode(t, ainf)
+
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	var buf bytes.Buffer
+	for _, box := range []Box{init.Ftyp, ainf, init.Moov} {
+		if err := box.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	inData := buf.Bytes()
+	f, err := DecodeFile(bytes.NewBuffer(inData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Ainf == nil || f.Ainf.APID != ainf.APID {
+		t.Fatalf("ainf not decoded")
+	}
+	var outBuf bytes.Buffer
+	if err = f.Encode(&outBuf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(outBuf.Bytes(), inData) {
+		t.Errorf("re-encoded file with ainf differs")
+	}
+}
@@ -0,0 +1,136 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// alacConfigPayloadLen - size of version, flags, and ALACSpecificConfig in alac config box
+const alacConfigPayloadLen = 28
+
+// AlacBox - ALAC Specific Info (magic cookie) box (alac) for Apple Lossless Audio
+// Defined in the ALAC Magic Cookie Description (ALACMagicCookieDescription.txt)
+//
+// Contained in : ALAC Audio Sample Entry (alac)
+//
+// The config box has the same type as the sample entry, so an alac box with a payload of exactly
+// 28 bytes is decoded as config, and other alac boxes are decoded as audio sample entries.
+type AlacBox struct {
+	Version           byte
+	Flags             uint32
+	FrameLength       uint32
+	CompatibleVersion byte
+	BitDepth          byte
+	Pb                byte // Rice parameter tuning (40)
+	Mb                byte // Rice parameter tuning (10)
+	Kb                byte // Rice parameter tuning (14)
+	NumChannels       byte
+	MaxRun            uint16
+	MaxFrameBytes     uint32
+	AvgBitRate        uint32
+	SampleRate        uint32
+}
+
+// DecodeAlac - box-specific decode of alac config or alac audio sample entry
+func DecodeAlac(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	if hdr.payloadLen() != alacConfigPayloadLen {
+		return DecodeAudioSampleEntry(hdr, startPos, r)
+	}
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return decodeAlacConfigSR(sr)
+}
+
+// DecodeAlacSR - box-specific decode of alac config or alac audio sample entry
+func DecodeAlacSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.payloadLen() != alacConfigPayloadLen {
+		return DecodeAudioSampleEntrySR(hdr, startPos, sr)
+	}
+	return decodeAlacConfigSR(sr)
+}
+
+func decodeAlacConfigSR(sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := AlacBox{
+		Version:           byte(versionAndFlags >> 24),
+		Flags:             versionAndFlags & flagsMask,
+		FrameLength:       sr.ReadUint32(),
+		CompatibleVersion: sr.ReadUint8(),
+		BitDepth:          sr.ReadUint8(),
+		Pb:                sr.ReadUint8(),
+		Mb:                sr.ReadUint8(),
+		Kb:                sr.ReadUint8(),
+		NumChannels:       sr.ReadUint8(),
+		MaxRun:            sr.ReadUint16(),
+		MaxFrameBytes:     sr.ReadUint32(),
+		AvgBitRate:        sr.ReadUint32(),
+		SampleRate:        sr.ReadUint32(),
+	}
+	if sr.AccError() != nil {
+		return nil, fmt.Errorf("decode alac: %w", sr.AccError())
+	}
+	return &b, nil
+}
+
+// Type - return box type
+func (b *AlacBox) Type() string {
+	return "alac"
+}
+
+// Size - return calculated size
+func (b *AlacBox) Size() uint64 {
+	return uint64(boxHeaderSize + alacConfigPayloadLen)
+}
+
+// Encode - write box to w
+func (b *AlacBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *AlacBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint32(b.FrameLength)
+	sw.WriteUint8(b.CompatibleVersion)
+	sw.WriteUint8(b.BitDepth)
+	sw.WriteUint8(b.Pb)
+	sw.WriteUint8(b.Mb)
+	sw.WriteUint8(b.Kb)
+	sw.WriteUint8(b.NumChannels)
+	sw.WriteUint16(b.MaxRun)
+	sw.WriteUint32(b.MaxFrameBytes)
+	sw.WriteUint32(b.AvgBitRate)
+	sw.WriteUint32(b.SampleRate)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *AlacBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - frameLength: %d", b.FrameLength)
+	bd.write(" - compatibleVersion: %d", b.CompatibleVersion)
+	bd.write(" - bitDepth: %d", b.BitDepth)
+	bd.write(" - pb: %d, mb: %d, kb: %d", b.Pb, b.Mb, b.Kb)
+	bd.write(" - numChannels: %d", b.NumChannels)
+	bd.write(" - maxRun: %d", b.MaxRun)
+	bd.write(" - maxFrameBytes: %d", b.MaxFrameBytes)
+	bd.write(" - avgBitRate: %d", b.AvgBitRate)
+	bd.write(" - sampleRate: %d", b.SampleRate)
+	return bd.err
+}
@@ -0,0 +1,69 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+func createAlacConfig() *AlacBox {
+	return &AlacBox{
+		FrameLength:   4096,
+		BitDepth:      16,
+		Pb:            40,
+		Mb:            10,
+		Kb:            14,
+		NumChannels:   2,
+		MaxRun:        255,
+		MaxFrameBytes: 16416,
+		AvgBitRate:    850000,
+		SampleRate:    44100,
+	}
+}
+
+func TestAlac(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, createAlacConfig())
+}
+
+func TestAlacInitSegment(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(44100, "audio", "und")
+	alac := CreateAudioSampleEntryBox("alac", 2, 16, 44100, createAlacConfig())
+	init.Moov.Trak.Mdia.Minf.Stbl.Stsd.AddChild(alac)
+
+	var buf bytes.Buffer
+	err := init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encBytes := buf.Bytes()
+	decFiles := make([]*File, 0, 2)
+	f, err := DecodeFile(bytes.NewBuffer(encBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFiles = append(decFiles, f)
+	f, err = DecodeFileSR(bits.NewFixedSliceReader(encBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFiles = append(decFiles, f)
+	for _, f := range decFiles {
+		decEntry, ok := f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.Children[0].(*AudioSampleEntryBox)
+		if !ok || decEntry.Type() != "alac" {
+			t.Fatalf("alac sample entry not decoded")
+		}
+		if decEntry.Alac == nil || decEntry.Alac.FrameLength != 4096 || decEntry.Alac.SampleRate != 44100 {
+			t.Errorf("alac config not decoded correctly")
+		}
+		buf.Reset()
+		err = f.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), encBytes) {
+			t.Errorf("ALAC init segment not identical after round-trip")
+		}
+	}
+}
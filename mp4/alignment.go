@@ -0,0 +1,68 @@
+package mp4
+
+import (
+	"fmt"
+	"math"
+)
+
+// SegmentAlignmentTolerance - maximal difference in seconds between aligned subsegment boundaries
+const SegmentAlignmentTolerance = 0.001
+
+// AlignmentIssue - subsegment boundary in one representation that is not aligned with the first representation
+type AlignmentIssue struct {
+	SidxNr       int     // Zero-based index of sidx in the list
+	SubsegmentNr int     // One-based number of subsegment whose end boundary differs
+	Time         float64 // Boundary time in seconds
+	RefTime      float64 // Boundary time in seconds in first representation
+	Description  string
+}
+
+// CheckSegmentAlignment - check that the subsegment boundaries of all sidx boxes are aligned with
+// those of the first sidx box. A boundary is the earliest presentation time plus the accumulated
+// subsegment durations. Boundaries differing by more than SegmentAlignmentTolerance seconds,
+// and differing numbers of subsegments, are reported.
+func CheckSegmentAlignment(sidxes []*SidxBox) []AlignmentIssue {
+	if len(sidxes) < 2 {
+		return nil
+	}
+	var issues []AlignmentIssue
+	refBoundaries := sidxBoundaries(sidxes[0])
+	for sidxNr := 1; sidxNr < len(sidxes); sidxNr++ {
+		boundaries := sidxBoundaries(sidxes[sidxNr])
+		if len(boundaries) != len(refBoundaries) {
+			issues = append(issues, AlignmentIssue{
+				SidxNr: sidxNr,
+				Description: fmt.Sprintf("%d subsegments instead of %d",
+					len(boundaries)-1, len(refBoundaries)-1),
+			})
+		}
+		for i := 0; i < len(boundaries) && i < len(refBoundaries); i++ {
+			if math.Abs(boundaries[i]-refBoundaries[i]) <= SegmentAlignmentTolerance {
+				continue
+			}
+			issues = append(issues, AlignmentIssue{
+				SidxNr:       sidxNr,
+				SubsegmentNr: i,
+				Time:         boundaries[i],
+				RefTime:      refBoundaries[i],
+				Description:  fmt.Sprintf("boundary at %.3fs instead of %.3fs", boundaries[i], refBoundaries[i]),
+			})
+		}
+	}
+	return issues
+}
+
+// sidxBoundaries - start time and subsegment end times in seconds
+func sidxBoundaries(sidx *SidxBox) []float64 {
+	if sidx.Timescale == 0 {
+		return nil
+	}
+	boundaries := make([]float64, 0, len(sidx.SidxRefs)+1)
+	t := sidx.EarliestPresentationTime
+	boundaries = append(boundaries, float64(t)/float64(sidx.Timescale))
+	for _, ref := range sidx.SidxRefs {
+		t += uint64(ref.SubSegmentDuration)
+		boundaries = append(boundaries, float64(t)/float64(sidx.Timescale))
+	}
+	return boundaries
+}
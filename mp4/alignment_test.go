@@ -0,0 +1,46 @@
+package mp4
+
+import (
+	"testing"
+)
+
+func TestCheckSegmentAlignment(t *testing.T) {
+	createSidx := func(timescale uint32, ept uint64, durs ...uint32) *SidxBox {
+		sidx := CreateSidx(ept)
+		sidx.Timescale = timescale
+		sidx.EarliestPresentationTime = ept
+		for _, dur := range durs {
+			sidx.SidxRefs = append(sidx.SidxRefs, SidxRef{SubSegmentDuration: dur, StartsWithSAP: 1, SAPType: 1})
+		}
+		return sidx
+	}
+
+	aligned := []*SidxBox{
+		createSidx(90000, 0, 180000, 180000, 90000),
+		createSidx(12800, 0, 25600, 25600, 12800),
+	}
+	if issues := CheckSegmentAlignment(aligned); len(issues) != 0 {
+		t.Errorf("got issues for aligned sidxes: %v", issues)
+	}
+
+	misaligned := []*SidxBox{
+		createSidx(90000, 0, 180000, 180000, 90000),
+		createSidx(12800, 0, 25600, 24000, 14400),
+	}
+	issues := CheckSegmentAlignment(misaligned)
+	if len(issues) != 1 {
+		t.Fatalf("got %d issues instead of 1: %v", len(issues), issues)
+	}
+	if issues[0].SidxNr != 1 || issues[0].SubsegmentNr != 2 || issues[0].RefTime != 4.0 {
+		t.Errorf("got issue %+v", issues[0])
+	}
+
+	differentCount := []*SidxBox{
+		createSidx(90000, 0, 180000, 180000),
+		createSidx(90000, 0, 180000, 180000, 180000),
+	}
+	issues = CheckSegmentAlignment(differentCount)
+	if len(issues) != 1 || issues[0].SubsegmentNr != 0 {
+		t.Errorf("got issues %v for different subsegment counts", issues)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/edgeware/mp4ff/aac"
 	"github.com/edgeware/mp4ff/bits"
 )
 
@@ -40,3 +41,117 @@ func TestWriteReadOfAudioSampleEntry(t *testing.T) {
 		t.Errorf("Out sampled rate %d differs from in %d for SliceReader", outAse.SampleRate, ase.SampleRate)
 	}
 }
+
+func TestAudioSampleEntryOutputSampleRate(t *testing.T) {
+	asc := &aac.AudioSpecificConfig{
+		ObjectType:           aac.HEAACv1,
+		ChannelConfiguration: 2,
+		SamplingFrequency:    24000,
+		ExtensionFrequency:   48000,
+		SBRPresentFlag:       true,
+	}
+	buf := &bytes.Buffer{}
+	err := asc.Encode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esds := CreateEsdsBox(buf.Bytes())
+	ase := CreateAudioSampleEntryBox("mp4a", 2, 16, 24000, esds)
+	decAse := boxAfterEncodeAndDecode(t, ase).(*AudioSampleEntryBox)
+	if decAse.SampleRate != 24000 {
+		t.Errorf("got sample rate %d instead of 24000", decAse.SampleRate)
+	}
+	if decAse.OutputSampleRate() != 48000 {
+		t.Errorf("got output sample rate %d instead of 48000", decAse.OutputSampleRate())
+	}
+	ase = CreateAudioSampleEntryBox("mp4a", 2, 16, 48000, nil)
+	if ase.OutputSampleRate() != 48000 {
+		t.Errorf("got output sample rate %d instead of 48000 without esds", ase.OutputSampleRate())
+	}
+}
+
+func TestAudioSampleEntryWithSrat(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, CreateSrat(192000))
+
+	// 96kHz does not fit in the 16-bit SampleRate field, so it is carried in srat
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(96000, "audio", "und")
+	ase := CreateAudioSampleEntryBox("mp4a", 2, 24, 0, CreateSrat(96000))
+	init.Moov.Trak.Mdia.Minf.Stbl.Stsd.AddChild(ase)
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	f, err := DecodeFile(bytes.NewBuffer(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decAse, ok := f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.Children[0].(*AudioSampleEntryBox)
+	if !ok {
+		t.Fatalf("no audio sample entry after decode")
+	}
+	if decAse.Srat == nil || decAse.Srat.SamplingRate != 96000 {
+		t.Fatalf("no srat box with sampling rate 96000 after decode")
+	}
+	if decAse.OutputSampleRate() != 96000 {
+		t.Errorf("got output sample rate %d instead of 96000", decAse.OutputSampleRate())
+	}
+	var outBuf bytes.Buffer
+	if err = f.Encode(&outBuf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(outBuf.Bytes(), encoded) {
+		t.Errorf("re-encoded file differs from original")
+	}
+}
+
+func TestPCMSampleEntry(t *testing.T) {
+	boxDiffAfterEncodeAndDecode(t, CreatePcmC(24, true))
+
+	testCases := []struct {
+		entryType    string
+		bitDepth     byte
+		littleEndian bool
+	}{
+		{"ipcm", 24, true},
+		{"fpcm", 32, false},
+	}
+	for _, tc := range testCases {
+		init := CreateEmptyInit()
+		init.AddEmptyTrack(48000, "audio", "und")
+		pcmC := CreatePcmC(tc.bitDepth, tc.littleEndian)
+		ase := CreateAudioSampleEntryBox(tc.entryType, 2, uint16(tc.bitDepth), 48000, pcmC)
+		init.Moov.Trak.Mdia.Minf.Stbl.Stsd.AddChild(ase)
+		var buf bytes.Buffer
+		if err := init.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		encoded := buf.Bytes()
+		f, err := DecodeFile(bytes.NewBuffer(encoded))
+		if err != nil {
+			t.Fatal(err)
+		}
+		decAse, ok := f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.Children[0].(*AudioSampleEntryBox)
+		if !ok || decAse.PcmC == nil {
+			t.Fatalf("%s: no audio sample entry with pcmC after decode", tc.entryType)
+		}
+		if decAse.PcmC.BitDepth() != int(tc.bitDepth) || decAse.PcmC.IsLittleEndian() != tc.littleEndian {
+			t.Errorf("%s: got bit depth %d little-endian %t", tc.entryType,
+				decAse.PcmC.BitDepth(), decAse.PcmC.IsLittleEndian())
+		}
+		if codec := decAse.PCMCodecString(); codec != tc.entryType {
+			t.Errorf("got codec string %q instead of %q", codec, tc.entryType)
+		}
+		var outBuf bytes.Buffer
+		if err = f.Encode(&outBuf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(outBuf.Bytes(), encoded) {
+			t.Errorf("%s: re-encoded init segment differs from original", tc.entryType)
+		}
+	}
+	if codec := CreateAudioSampleEntryBox("mp4a", 2, 16, 48000, nil).PCMCodecString(); codec != "" {
+		t.Errorf("got PCM codec string %q for mp4a", codec)
+	}
+}
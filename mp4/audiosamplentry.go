@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/edgeware/mp4ff/aac"
 	"github.com/edgeware/mp4ff/bits"
 )
 
@@ -18,7 +19,12 @@ type AudioSampleEntryBox struct {
 	Esds               *EsdsBox
 	Dac3               *Dac3Box
 	Dec3               *Dec3Box
+	Alac               *AlacBox
+	Btrt               *BtrtBox
+	Iacb               *IacbBox
+	PcmC               *PcmCBox
 	Sinf               *SinfBox
+	Srat               *SratBox
 	Children           []Box
 }
 
@@ -54,14 +60,26 @@ func CreateAudioSampleEntryBox(name string, nrChannels, sampleSize, sampleRate u
 // AddChild - add a child box (avcC normally, but clap and pasp could be part of visual entry)
 func (a *AudioSampleEntryBox) AddChild(child Box) {
 	switch child.Type() {
+	case "alac":
+		if alac, ok := child.(*AlacBox); ok {
+			a.Alac = alac
+		}
 	case "esds":
 		a.Esds = child.(*EsdsBox)
+	case "btrt":
+		a.Btrt = child.(*BtrtBox)
 	case "dac3":
 		a.Dac3 = child.(*Dac3Box)
 	case "dec3":
 		a.Dec3 = child.(*Dec3Box)
+	case "iacb":
+		a.Iacb = child.(*IacbBox)
+	case "pcmC":
+		a.PcmC = child.(*PcmCBox)
 	case "sinf":
 		a.Sinf = child.(*SinfBox)
+	case "srat":
+		a.Srat = child.(*SratBox)
 	}
 
 	a.Children = append(a.Children, child)
@@ -148,6 +166,33 @@ func DecodeAudioSampleEntrySR(hdr BoxHeader, startPos uint64, sr bits.SliceReade
 	return a, sr.AccError()
 }
 
+// OutputSampleRate - sample rate after decoding.
+// For AAC with SBR, this is the upsampled rate from the AudioSpecificConfig
+// which may differ from the SampleRate field. If there is an srat box, its full 32-bit sampling rate
+// is returned, since rates above 65535 do not fit in SampleRate. Otherwise SampleRate is returned.
+func (a *AudioSampleEntryBox) OutputSampleRate() uint32 {
+	if a.Srat != nil {
+		return a.Srat.SamplingRate
+	}
+	if a.Esds != nil {
+		decConfig := a.Esds.DecConfigDescriptor.DecSpecificInfo.DecConfig
+		asc, err := aac.DecodeAudioSpecificConfig(bytes.NewBuffer(decConfig))
+		if err == nil {
+			return asc.EffectiveSampleRate()
+		}
+	}
+	return uint32(a.SampleRate)
+}
+
+// PCMCodecString - codecs string for uncompressed PCM audio according to ISO/IEC 23003-5,
+// which is the sample entry type ipcm or fpcm. Empty string if not a PCM sample entry with pcmC box.
+func (a *AudioSampleEntryBox) PCMCodecString() string {
+	if a.PcmC == nil || (a.name != "ipcm" && a.name != "fpcm") {
+		return ""
+	}
+	return a.name
+}
+
 // Type - return box type
 func (a *AudioSampleEntryBox) Type() string {
 	return a.name
@@ -0,0 +1,140 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/edgeware/mp4ff/avc"
+	"github.com/edgeware/mp4ff/hevc"
+)
+
+// rewriteChunk - chunk of one track with its original position in the file and new data
+type rewriteChunk struct {
+	trakNr     int
+	chunkIdx   int
+	origOffset uint64
+	data       []byte
+}
+
+// StripAUDs - remove access unit delimiter NAL units (type 9 for AVC, type 35 for HEVC) from all samples of
+// trackID in a progressive file. The mdat data is rewritten with the chunks of all tracks kept in their original
+// order, and stsz and stco/co64 are updated. Data in the mdat which does not belong to any chunk is dropped.
+// rs is needed if the mdat is lazily decoded. The resulting mdat is held in memory.
+func (f *File) StripAUDs(trackID uint32, rs io.ReadSeeker) error {
+	if f.isFragmented || f.Moov == nil || f.Mdat == nil {
+		return fmt.Errorf("only available for progressive files")
+	}
+	trak := f.Moov.GetTrak(trackID)
+	if trak == nil {
+		return fmt.Errorf("no track with trackID %d", trackID)
+	}
+	var isAUD func(naluHeader byte) bool
+	stsd := trak.Mdia.Minf.Stbl.Stsd
+	switch {
+	case stsd.AvcX != nil:
+		isAUD = func(naluHeader byte) bool { return avc.GetNaluType(naluHeader) == avc.NALU_AUD }
+	case stsd.HvcX != nil:
+		isAUD = func(naluHeader byte) bool { return hevc.GetNaluType(naluHeader) == hevc.NALU_AUD }
+	default:
+		return fmt.Errorf("track %d is not AVC or HEVC", trackID)
+	}
+	return f.rewriteSampleData(trackID, rs, func(sample []byte) ([]byte, error) {
+		return removeNalus(sample, isAUD)
+	})
+}
+
+// rewriteSampleData - replace the data of each sample of trackID in a progressive file by transform(data).
+// The mdat data is rewritten with the chunks of all tracks kept in their original order,
+// and stsz and stco/co64 are updated. Data in the mdat which does not belong to any chunk is dropped.
+// If an error occurs, the file is left unchanged.
+func (f *File) rewriteSampleData(trackID uint32, rs io.ReadSeeker, transform func(sample []byte) ([]byte, error)) error {
+	var chunks []*rewriteChunk
+	var newSizes []uint32
+	for trakNr, tr := range f.Moov.Traks {
+		samples, err := f.fullSamplesForTrack(tr.Tkhd.TrackID, rs)
+		if err != nil {
+			return fmt.Errorf("track %d: %w", tr.Tkhd.TrackID, err)
+		}
+		stbl := tr.Mdia.Minf.Stbl
+		if tr.Tkhd.TrackID == trackID {
+			newSizes = make([]uint32, len(samples))
+			for i := range samples {
+				data, err := transform(samples[i].Data)
+				if err != nil {
+					return fmt.Errorf("sample %d: %w", i+1, err)
+				}
+				samples[i].Data = data
+				newSizes[i] = uint32(len(data))
+			}
+		}
+		var nrChunks int
+		if stbl.Co64 != nil {
+			nrChunks = len(stbl.Co64.ChunkOffset)
+		} else {
+			nrChunks = len(stbl.Stco.ChunkOffset)
+		}
+		for i := 0; i < nrChunks; i++ {
+			c := &rewriteChunk{trakNr: trakNr, chunkIdx: i}
+			if stbl.Co64 != nil {
+				c.origOffset = stbl.Co64.ChunkOffset[i]
+			} else {
+				c.origOffset = uint64(stbl.Stco.ChunkOffset[i])
+			}
+			chunk := stbl.Stsc.GetChunk(uint32(i + 1))
+			for nr := chunk.StartSampleNr; nr < chunk.StartSampleNr+chunk.NrSamples; nr++ {
+				if int(nr) > len(samples) {
+					return fmt.Errorf("track %d: chunk %d has samples beyond last sample", tr.Tkhd.TrackID, i+1)
+				}
+				c.data = append(c.data, samples[nr-1].Data...)
+			}
+			chunks = append(chunks, c)
+		}
+	}
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return chunks[i].origOffset < chunks[j].origOffset
+	})
+	var mdatData []byte
+	newOffsets := make([]uint64, len(chunks))
+	for i, c := range chunks {
+		newOffsets[i] = uint64(len(mdatData))
+		mdatData = append(mdatData, c.data...)
+	}
+	// The stsz box grows if it had a uniform sample size, which moves an mdat after the moov
+	stsz := f.Moov.GetTrak(trackID).Mdia.Minf.Stbl.Stsz
+	newStsz := &StszBox{Version: stsz.Version, Flags: stsz.Flags, SampleNumber: stsz.SampleNumber, SampleSize: newSizes}
+	var mdatStart uint64
+	for _, c := range f.Children {
+		if c == f.Mdat {
+			break
+		}
+		mdatStart += c.Size()
+		if c == f.Moov {
+			mdatStart = mdatStart + newStsz.Size() - stsz.Size()
+		}
+	}
+	payloadStart := mdatStart + f.Mdat.HeaderSize()
+	for i, c := range chunks {
+		stbl := f.Moov.Traks[c.trakNr].Mdia.Minf.Stbl
+		if stbl.Co64 == nil && payloadStart+newOffsets[i] > math.MaxUint32 {
+			return fmt.Errorf("chunk offset %d too big for stco", payloadStart+newOffsets[i])
+		}
+	}
+	// All checks done, so the track and mdat can be changed
+	stsz.SampleUniformSize = 0
+	stsz.SampleSize = newSizes
+	f.Mdat.SetData(mdatData)
+	f.Mdat.DataParts = nil
+	f.Mdat.StartPos = mdatStart
+	for i, c := range chunks {
+		stbl := f.Moov.Traks[c.trakNr].Mdia.Minf.Stbl
+		offset := payloadStart + newOffsets[i]
+		if stbl.Co64 != nil {
+			stbl.Co64.ChunkOffset[c.chunkIdx] = offset
+		} else {
+			stbl.Stco.ChunkOffset[c.chunkIdx] = uint32(offset)
+		}
+	}
+	return nil
+}
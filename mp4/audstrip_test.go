@@ -0,0 +1,176 @@
+package mp4
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/edgeware/mp4ff/avc"
+	"github.com/go-test/deep"
+)
+
+func TestStripAUDs(t *testing.T) {
+	f, err := ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	videoID := f.Moov.Traks[1].Tkhd.TrackID
+	audioID := f.Moov.Traks[0].Tkhd.TrackID
+	origVideo, err := f.fullSamplesForTrack(videoID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	origAudio, err := f.fullSamplesForTrack(audioID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Insert an AUD NAL unit first in every video sample
+	aud := []byte{0, 0, 0, 2, 0x09, 0xf0}
+	err = f.rewriteSampleData(videoID, nil, func(sample []byte) ([]byte, error) {
+		return append(append([]byte{}, aud...), sample...), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withAUDs, err := f.fullSamplesForTrack(videoID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !avc.ContainsNaluType(withAUDs[0].Data, avc.NALU_AUD) {
+		t.Fatalf("no AUD inserted")
+	}
+
+	if err = f.StripAUDs(videoID, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Check the result after encoding and decoding the file
+	var buf bytes.Buffer
+	if err = f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decFile, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	video, err := decFile.fullSamplesForTrack(videoID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(video) != len(origVideo) {
+		t.Fatalf("got %d video samples instead of %d", len(video), len(origVideo))
+	}
+	for i := range video {
+		if avc.ContainsNaluType(video[i].Data, avc.NALU_AUD) {
+			t.Errorf("sample %d still has AUD", i+1)
+		}
+		if !bytes.Equal(video[i].Data, origVideo[i].Data) {
+			t.Errorf("sample %d: NAL units differ from original", i+1)
+		}
+	}
+	audio, err := decFile.fullSamplesForTrack(audioID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(audio, origAudio); diff != nil {
+		t.Errorf("audio samples changed: %v", diff)
+	}
+	if err = f.StripAUDs(audioID, nil); err == nil {
+		t.Errorf("expected error for audio track")
+	}
+}
+
+func TestRewriteSampleDataError(t *testing.T) {
+	f, err := ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	videoID := f.Moov.Traks[1].Tkhd.TrackID
+	stsz := f.Moov.Traks[1].Mdia.Minf.Stbl.Stsz
+	origSizes := append([]uint32{}, stsz.SampleSize...)
+	origSamples, err := f.fullSamplesForTrack(videoID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fail after some samples have been transformed
+	nrCalls := 0
+	err = f.rewriteSampleData(videoID, nil, func(sample []byte) ([]byte, error) {
+		nrCalls++
+		if nrCalls == 3 {
+			return nil, fmt.Errorf("transform failed")
+		}
+		return sample[:1], nil
+	})
+	if err == nil {
+		t.Fatalf("expected error from transform")
+	}
+	if diff := deep.Equal(stsz.SampleSize, origSizes); diff != nil {
+		t.Errorf("stsz changed after failed rewrite: %v", diff)
+	}
+	samples, err := f.fullSamplesForTrack(videoID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(samples, origSamples); diff != nil {
+		t.Errorf("samples changed after failed rewrite: %v", diff)
+	}
+}
+
+func TestRewriteSampleDataUniformSize(t *testing.T) {
+	f, err := ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	audioID := f.Moov.Traks[0].Tkhd.TrackID
+	videoID := f.Moov.Traks[1].Tkhd.TrackID
+	origVideo, err := f.fullSamplesForTrack(videoID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Give all audio samples the same size and signal it as a uniform size in stsz
+	err = f.rewriteSampleData(audioID, nil, func(sample []byte) ([]byte, error) {
+		return []byte{1, 2, 3, 4}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stsz := f.Moov.Traks[0].Mdia.Minf.Stbl.Stsz
+	oldPositions := f.mdatPositions()
+	stsz.SampleUniformSize = 4
+	stsz.SampleSize = nil
+	if err = f.moveAllChunkOffsets(oldPositions); err != nil {
+		t.Fatal(err)
+	}
+	err = f.rewriteSampleData(audioID, nil, func(sample []byte) ([]byte, error) {
+		return append(append([]byte{}, sample...), 5), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stsz.SampleUniformSize != 0 || len(stsz.SampleSize) != int(stsz.SampleNumber) {
+		t.Errorf("stsz not changed to individual sample sizes")
+	}
+	var buf bytes.Buffer
+	if err = f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decFile, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audio, err := decFile.fullSamplesForTrack(audioID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range audio {
+		if !bytes.Equal(audio[i].Data, []byte{1, 2, 3, 4, 5}) {
+			t.Fatalf("audio sample %d: got data %v", i+1, audio[i].Data)
+		}
+	}
+	video, err := decFile.fullSamplesForTrack(videoID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(video, origVideo); diff != nil {
+		t.Errorf("video samples changed: %v", diff)
+	}
+}
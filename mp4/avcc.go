@@ -47,6 +47,16 @@ func DecodeAvcCSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	return &AvcCBox{avcDecConfRec}, nil
 }
 
+// SetSPS - replace all SPS NAL units. Other fields are left unchanged
+func (a *AvcCBox) SetSPS(spsList [][]byte) {
+	a.SPSnalus = spsList
+}
+
+// SetPPS - replace all PPS NAL units. Other fields are left unchanged
+func (a *AvcCBox) SetPPS(ppsList [][]byte) {
+	a.PPSnalus = ppsList
+}
+
 // Type - return box type
 func (a *AvcCBox) Type() string {
 	return "avcC"
@@ -0,0 +1,56 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+func TestAvcCSetSPS(t *testing.T) {
+	sps, _ := hex.DecodeString(sps1nalu)
+	pps, _ := hex.DecodeString(pps1nalu)
+	avcC, err := CreateAvcC([][]byte{sps}, [][]byte{pps}, true)
+	if err != nil {
+		t.Error(err)
+	}
+	var orig bytes.Buffer
+	err = avcC.Encode(&orig)
+	if err != nil {
+		t.Error(err)
+	}
+	origBytes := orig.Bytes()
+
+	newSPS := append(append([]byte{}, sps...), 0x80) // Extra trailing byte to change length
+	avcC.SetSPS([][]byte{newSPS})
+	var patched bytes.Buffer
+	err = avcC.Encode(&patched)
+	if err != nil {
+		t.Error(err)
+	}
+	// Expected payload: 6 byte config start, new SPS with length, and the rest unchanged
+	spsStart := boxHeaderSize + 6
+	var expected []byte
+	expected = append(expected, origBytes[boxHeaderSize:spsStart]...)
+	lenBytes := make([]byte, 2)
+	binary.BigEndian.PutUint16(lenBytes, uint16(len(newSPS)))
+	expected = append(expected, lenBytes...)
+	expected = append(expected, newSPS...)
+	expected = append(expected, origBytes[spsStart+2+len(sps):]...)
+	patchedBytes := patched.Bytes()
+	if int(binary.BigEndian.Uint32(patchedBytes[:4])) != len(patchedBytes) {
+		t.Errorf("box size %d does not match length %d", binary.BigEndian.Uint32(patchedBytes[:4]), len(patchedBytes))
+	}
+	if !bytes.Equal(patchedBytes[boxHeaderSize:], expected) {
+		t.Errorf("got %s instead of %s", hex.EncodeToString(patchedBytes[boxHeaderSize:]), hex.EncodeToString(expected))
+	}
+
+	avcC.SetPPS([][]byte{pps, pps})
+	decAvcC := boxAfterEncodeAndDecode(t, avcC).(*AvcCBox)
+	if len(decAvcC.SPSnalus) != 1 || !bytes.Equal(decAvcC.SPSnalus[0], newSPS) {
+		t.Errorf("wrong SPS after decode")
+	}
+	if len(decAvcC.PPSnalus) != 2 {
+		t.Errorf("got %d PPS instead of 2", len(decAvcC.PPSnalus))
+	}
+}
@@ -0,0 +1,314 @@
+package mp4
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/edgeware/mp4ff/aac"
+	"github.com/edgeware/mp4ff/hevc"
+)
+
+// TrackBitrate - average and maximum bitrate in bits per second for a track
+type TrackBitrate struct {
+	TrackID    uint32
+	Codec      string
+	AvgBitrate uint32
+	MaxBitrate uint32
+	Declared   bool // Values from btrt or esds box instead of calculated from samples
+}
+
+// BitrateReport - report bitrates for all tracks.
+// Declared values from btrt or esds are used if present, otherwise the bitrates are calculated
+// from the sample sizes and durations. The max bitrate is then the highest bitrate of any one-second window
+// of decode time, as in btrt boxes added by AddBitrateBoxes.
+func (f *File) BitrateReport() ([]TrackBitrate, error) {
+	moov := f.Moov
+	if moov == nil {
+		return nil, fmt.Errorf("no moov box")
+	}
+	var report []TrackBitrate
+	for _, trak := range moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		tb := TrackBitrate{TrackID: trackID}
+		stsd := trak.Mdia.Minf.Stbl.Stsd
+		if len(stsd.Children) > 0 {
+			sampleEntry := stsd.Children[0]
+			tb.Codec = codecString(sampleEntry)
+			if avg, max, ok := declaredBitrates(sampleEntry); ok {
+				tb.AvgBitrate, tb.MaxBitrate, tb.Declared = avg, max, true
+				report = append(report, tb)
+				continue
+			}
+		}
+		samples, err := f.sampleSizesAndTimes(trak)
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %w", trackID, err)
+		}
+		if len(samples) > 0 {
+			tb.AvgBitrate, tb.MaxBitrate, err = calcBitrates(samples, trak.Mdia.Mdhd.Timescale)
+			if err != nil {
+				return nil, fmt.Errorf("track %d: %w", trackID, err)
+			}
+		}
+		report = append(report, tb)
+	}
+	return report, nil
+}
+
+// sizeAndTime - size and decode time of a sample
+type sizeAndTime struct {
+	size       uint32
+	decodeTime uint64
+	dur        uint32
+}
+
+// sampleSizesAndTimes - get sample sizes and times from stbl or from fragments
+func (f *File) sampleSizesAndTimes(trak *TrakBox) ([]sizeAndTime, error) {
+	if !f.isFragmented {
+		return trak.sampleSizesAndTimes()
+	}
+	var samples []sizeAndTime
+	trackID := trak.Tkhd.TrackID
+	trex := f.getTrex(trackID)
+	var decTime uint64
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID != trackID {
+					continue
+				}
+				if traf.Tfdt != nil {
+					decTime = traf.Tfdt.BaseMediaDecodeTime
+				}
+				for _, trun := range traf.Truns {
+					trun.AddSampleDefaultValues(traf.Tfhd, trex)
+					for _, s := range trun.Samples {
+						samples = append(samples, sizeAndTime{s.Size, decTime, s.Dur})
+						decTime += uint64(s.Dur)
+					}
+				}
+			}
+		}
+	}
+	return samples, nil
+}
+
+// sampleSizesAndTimes - get sample sizes and times from stsz and stts
+func (t *TrakBox) sampleSizesAndTimes() ([]sizeAndTime, error) {
+	stbl := t.Mdia.Minf.Stbl
+	if stbl.Stsz == nil || stbl.Stts == nil {
+		return nil, fmt.Errorf("no stsz or stts box")
+	}
+	nrSamples := stbl.Stsz.GetNrSamples()
+	samples := make([]sizeAndTime, 0, nrSamples)
+	var decTime uint64
+	for nr := uint32(1); nr <= nrSamples; nr++ {
+		dur := stbl.Stts.GetDur(nr)
+		samples = append(samples, sizeAndTime{stbl.Stsz.GetSampleSize(int(nr)), decTime, dur})
+		decTime += uint64(dur)
+	}
+	return samples, nil
+}
+
+// calcBitrates - average bitrate over all samples and max bitrate over any one-second window of decode time.
+// The samples must be in decode order. The max bitrate is at least the average bitrate.
+func calcBitrates(samples []sizeAndTime, timescale uint32) (avg, max uint32, err error) {
+	if len(samples) == 0 {
+		return 0, 0, fmt.Errorf("no samples")
+	}
+	if timescale == 0 {
+		return 0, 0, fmt.Errorf("zero timescale")
+	}
+	var totSize, totDur, maxBytes, windowBytes uint64
+	start := 0
+	for _, s := range samples {
+		totSize += uint64(s.size)
+		totDur += uint64(s.dur)
+		windowBytes += uint64(s.size)
+		for s.decodeTime >= samples[start].decodeTime+uint64(timescale) {
+			windowBytes -= uint64(samples[start].size)
+			start++
+		}
+		if windowBytes > maxBytes {
+			maxBytes = windowBytes
+		}
+	}
+	if totDur == 0 {
+		return 0, 0, fmt.Errorf("zero duration")
+	}
+	avgBitrate := totSize * 8 * uint64(timescale) / totDur
+	maxBitrate := avgBitrate // Track shorter than a second
+	if maxBytes*8 > maxBitrate {
+		maxBitrate = maxBytes * 8
+	}
+	if maxBitrate > math.MaxUint32 {
+		return 0, 0, fmt.Errorf("bitrate %d too big", maxBitrate)
+	}
+	return uint32(avgBitrate), uint32(maxBitrate), nil
+}
+
+// declaredBitrates - bitrates from btrt or esds box in sample entry
+func declaredBitrates(sampleEntry Box) (avg, max uint32, ok bool) {
+	var children []Box
+	switch se := sampleEntry.(type) {
+	case *VisualSampleEntryBox:
+		if se.Btrt != nil {
+			return se.Btrt.AvgBitrate, se.Btrt.MaxBitrate, true
+		}
+		if se.Esds != nil {
+			children = []Box{se.Esds}
+		}
+	case *AudioSampleEntryBox:
+		children = se.Children
+	case *StppBox:
+		if se.Btrt != nil {
+			return se.Btrt.AvgBitrate, se.Btrt.MaxBitrate, true
+		}
+	}
+	for _, c := range children {
+		switch box := c.(type) {
+		case *BtrtBox:
+			return box.AvgBitrate, box.MaxBitrate, true
+		case *EsdsBox:
+			dcd := box.DecConfigDescriptor
+			if dcd.AvgBitrate != 0 || dcd.MaxBitrate != 0 {
+				return dcd.AvgBitrate, dcd.MaxBitrate, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// codecString - RFC6381 codec string for sample entry if known, otherwise the sample entry type
+func codecString(sampleEntry Box) string {
+	name := sampleEntry.Type()
+	switch se := sampleEntry.(type) {
+	case *VisualSampleEntryBox:
+		if se.AvcC != nil {
+			avcC := se.AvcC
+			return fmt.Sprintf("%s.%02X%02X%02X", name, avcC.AVCProfileIndication,
+				avcC.ProfileCompatibility, avcC.AVCLevelIndication)
+		}
+		if se.HvcC != nil {
+			spss := se.HvcC.GetNalusForType(hevc.NALU_SPS)
+			if len(spss) > 0 {
+				sps, err := hevc.ParseSPSNALUnit(spss[0])
+				if err == nil {
+					return hevc.CodecString(name, sps)
+				}
+			}
+		}
+	case *AudioSampleEntryBox:
+		if se.Esds != nil {
+			decConfig := se.Esds.DecConfigDescriptor.DecSpecificInfo.DecConfig
+			asc, err := aac.DecodeAudioSpecificConfig(bytes.NewBuffer(decConfig))
+			if err == nil {
+				return fmt.Sprintf("%s.40.%d", name, asc.ObjectType)
+			}
+		}
+	}
+	return name
+}
+
+// FragmentedMediaBitrate - average bitrate in bits per second of the sample data for trackID in all
+// fragments of a fragmented file, computed from the trun sample sizes and durations (with tfhd and trex
+// defaults) in the media timescale. This is the fragmented equivalent of TrakBox.MediaBitrate.
+func (f *File) FragmentedMediaBitrate(trackID uint32) (uint32, error) {
+	if !f.isFragmented {
+		return 0, fmt.Errorf("only available for fragmented files")
+	}
+	trak := f.getTrak(trackID)
+	if trak == nil {
+		return 0, fmt.Errorf("no track with trackID %d", trackID)
+	}
+	samples, err := f.sampleSizesAndTimes(trak)
+	if err != nil {
+		return 0, err
+	}
+	avg, _, err := calcBitrates(samples, trak.Mdia.Mdhd.Timescale)
+	return avg, err
+}
+
+// AddBitrateBoxes - add a btrt box to the sample entries of all tracks in a progressive file.
+// The average bitrate and bufferSizeDB (the largest sample size) are computed from the sample tables,
+// and the maximum bitrate is the largest number of bits in any one-second window of decode time.
+// Sample entries that already have a btrt box are left unchanged unless force is set,
+// in which case the existing btrt box is replaced. Only visual, audio, wvtt, stpp, and sbtt
+// sample entries can carry a btrt box, so other sample entries are skipped.
+// The chunk offsets are updated for the new size of moov.
+func (f *File) AddBitrateBoxes(force bool) error {
+	if f.isFragmented || f.Moov == nil {
+		return fmt.Errorf("only available for progressive files")
+	}
+	// Compute all btrt boxes before changing any sample entry
+	btrts := make(map[*TrakBox]*BtrtBox)
+	for _, trak := range f.Moov.Traks {
+		for _, entry := range trak.Mdia.Minf.Stbl.Stsd.Children {
+			old, children := sampleEntryBtrt(entry)
+			if children == nil || (*old != nil && !force) || btrts[trak] != nil {
+				continue
+			}
+			btrt, err := trakBtrt(trak)
+			if err != nil {
+				return fmt.Errorf("track %d: %w", trak.Tkhd.TrackID, err)
+			}
+			btrts[trak] = btrt
+		}
+	}
+	oldMdatPositions := f.mdatPositions()
+	for _, trak := range f.Moov.Traks {
+		for _, entry := range trak.Mdia.Minf.Stbl.Stsd.Children {
+			old, children := sampleEntryBtrt(entry)
+			if children == nil || (*old != nil && !force) {
+				continue
+			}
+			newBtrt := *btrts[trak]
+			if *old != nil {
+				for i, c := range *children {
+					if c == *old {
+						(*children)[i] = &newBtrt
+					}
+				}
+			} else {
+				*children = append(*children, &newBtrt)
+			}
+			*old = &newBtrt
+		}
+	}
+	return f.moveAllChunkOffsets(oldMdatPositions)
+}
+
+// sampleEntryBtrt - btrt field and children of sample entry, or nil if the sample entry has no btrt field
+func sampleEntryBtrt(entry Box) (btrt **BtrtBox, children *[]Box) {
+	switch e := entry.(type) {
+	case *VisualSampleEntryBox:
+		return &e.Btrt, &e.Children
+	case *AudioSampleEntryBox:
+		return &e.Btrt, &e.Children
+	case *WvttBox:
+		return &e.Btrt, &e.Children
+	case *StppBox:
+		return &e.Btrt, &e.Children
+	case *SbttBox:
+		return &e.Btrt, &e.Children
+	}
+	return nil, nil
+}
+
+// trakBtrt - btrt box with values computed from the sample tables of trak
+func trakBtrt(trak *TrakBox) (*BtrtBox, error) {
+	samples, err := trak.sampleSizesAndTimes()
+	if err != nil {
+		return nil, err
+	}
+	avgBitrate, maxBitrate, err := calcBitrates(samples, trak.Mdia.Mdhd.Timescale)
+	if err != nil {
+		return nil, err
+	}
+	bufferSize, err := trak.MaxSampleBytes()
+	if err != nil {
+		return nil, err
+	}
+	return &BtrtBox{BufferSizeDB: bufferSize, MaxBitrate: maxBitrate, AvgBitrate: avgBitrate}, nil
+}
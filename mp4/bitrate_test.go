@@ -0,0 +1,208 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestFragmentedMediaBitrate(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(48000, "audio", "und")
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dur := uint32(1024)
+	for fragNr := 0; fragNr < 3; fragNr++ {
+		frag, err := CreateFragment(uint32(fragNr+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 5; i++ {
+			frag.AddFullSample(FullSample{
+				Sample:     NewSample(SyncSampleFlags, dur, 400, 0),
+				DecodeTime: uint64(fragNr*5+i) * uint64(dur),
+				Data:       make([]byte, 400),
+			})
+		}
+		if err = frag.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 15 samples of 400 bytes in 15*1024/48000 = 0.32s gives 48000 bits / 0.32s = 150000 bps
+	bitrate, err := f.FragmentedMediaBitrate(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bitrate != 150000 {
+		t.Errorf("got bitrate %d instead of 150000", bitrate)
+	}
+	if _, err = f.FragmentedMediaBitrate(2); err == nil {
+		t.Errorf("expected error for unknown track")
+	}
+}
+
+func TestAddBitrateBoxes(t *testing.T) {
+	f, err := ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	origSamples := make(map[uint32][]FullSample)
+	for _, trak := range f.Moov.Traks {
+		origSamples[trak.Tkhd.TrackID], err = f.fullSamplesForTrack(trak.Tkhd.TrackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = f.AddBitrateBoxes(false); err != nil {
+		t.Fatal(err)
+	}
+	video := f.Moov.Traks[1].Mdia.Minf.Stbl.Stsd.AvcX
+	if video.Btrt == nil {
+		t.Fatalf("no btrt added to video sample entry")
+	}
+	preset := BtrtBox{BufferSizeDB: 1, MaxBitrate: 2, AvgBitrate: 3}
+	*video.Btrt = preset
+	if err = f.AddBitrateBoxes(false); err != nil {
+		t.Fatal(err)
+	}
+	if *video.Btrt != preset {
+		t.Errorf("existing btrt changed without force: %+v", *video.Btrt)
+	}
+	if err = f.AddBitrateBoxes(true); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decFile, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for trackID, orig := range origSamples {
+		samples, err := decFile.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(samples, orig); diff != nil {
+			t.Errorf("track %d: samples differ after adding btrt: %v", trackID, diff)
+		}
+	}
+	for _, trak := range decFile.Moov.Traks {
+		var btrt *BtrtBox
+		switch e := trak.Mdia.Minf.Stbl.Stsd.Children[0].(type) {
+		case *VisualSampleEntryBox:
+			btrt = e.Btrt
+		case *AudioSampleEntryBox:
+			btrt = e.Btrt
+		}
+		if btrt == nil {
+			t.Fatalf("track %d: no btrt", trak.Tkhd.TrackID)
+		}
+		avgBitrate, err := trak.MediaBitrate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		maxSampleBytes, err := trak.MaxSampleBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if btrt.AvgBitrate != avgBitrate {
+			t.Errorf("track %d: got avgBitrate %d instead of %d", trak.Tkhd.TrackID, btrt.AvgBitrate, avgBitrate)
+		}
+		if btrt.MaxBitrate < btrt.AvgBitrate || btrt.MaxBitrate > 4*btrt.AvgBitrate {
+			t.Errorf("track %d: implausible maxBitrate %d for avgBitrate %d",
+				trak.Tkhd.TrackID, btrt.MaxBitrate, btrt.AvgBitrate)
+		}
+		if btrt.BufferSizeDB != maxSampleBytes {
+			t.Errorf("track %d: got bufferSizeDB %d instead of %d", trak.Tkhd.TrackID, btrt.BufferSizeDB, maxSampleBytes)
+		}
+	}
+}
+
+func TestBitrateReport(t *testing.T) {
+	f, err := ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Remove declared bitrates from the audio track so that they are calculated
+	audioTrak := f.Moov.Traks[0]
+	esds := audioTrak.Mdia.Minf.Stbl.Stsd.Mp4a.Esds
+	esds.DecConfigDescriptor.AvgBitrate = 0
+	esds.DecConfigDescriptor.MaxBitrate = 0
+
+	report, err := f.BitrateReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 2 {
+		t.Fatalf("got %d tracks instead of 2", len(report))
+	}
+
+	audio := report[0]
+	stbl := audioTrak.Mdia.Minf.Stbl
+	totSize, err := stbl.Stsz.GetTotalSampleSize(1, stbl.Stsz.GetNrSamples())
+	if err != nil {
+		t.Error(err)
+	}
+	var totDur uint64
+	for i := range stbl.Stts.SampleCount {
+		totDur += uint64(stbl.Stts.SampleCount[i]) * uint64(stbl.Stts.SampleTimeDelta[i])
+	}
+	expectedAvg := uint32(totSize * 8 * uint64(audioTrak.Mdia.Mdhd.Timescale) / totDur)
+	if audio.Declared || audio.Codec != "mp4a.40.2" || audio.AvgBitrate != expectedAvg {
+		t.Errorf("got audio %+v, expected calculated mp4a.40.2 with avg %d", audio, expectedAvg)
+	}
+	if audio.MaxBitrate < audio.AvgBitrate {
+		t.Errorf("audio max bitrate %d lower than avg %d", audio.MaxBitrate, audio.AvgBitrate)
+	}
+
+	video := report[1]
+	wanted := TrackBitrate{TrackID: 2, Codec: "avc1.64001E", AvgBitrate: 134384, MaxBitrate: 153016, Declared: true}
+	if video != wanted {
+		t.Errorf("got video %+v instead of %+v", video, wanted)
+	}
+
+	// A btrt box computed from the samples has the same bitrates as the report
+	if err = f.AddBitrateBoxes(true); err != nil {
+		t.Fatal(err)
+	}
+	btrt := audioTrak.Mdia.Minf.Stbl.Stsd.Mp4a.Btrt
+	if btrt.AvgBitrate != audio.AvgBitrate || btrt.MaxBitrate != audio.MaxBitrate {
+		t.Errorf("got btrt bitrates %d, %d instead of %d, %d", btrt.AvgBitrate, btrt.MaxBitrate,
+			audio.AvgBitrate, audio.MaxBitrate)
+	}
+}
+
+func TestCalcBitrates(t *testing.T) {
+	// The largest samples are within one second, but in different calendar seconds
+	samples := []sizeAndTime{
+		{size: 1000, decodeTime: 0, dur: 500},
+		{size: 1000, decodeTime: 500, dur: 500},
+		{size: 1000, decodeTime: 1000, dur: 500},
+		{size: 1000, decodeTime: 1500, dur: 300},
+		{size: 3000, decodeTime: 1800, dur: 700},
+		{size: 3000, decodeTime: 2500, dur: 500},
+	}
+	avg, max, err := calcBitrates(samples, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if avg != 26666 || max != 48000 {
+		t.Errorf("got avg %d and max %d instead of 26666 and 48000", avg, max)
+	}
+	if _, _, err = calcBitrates(nil, 1000); err == nil {
+		t.Errorf("expected error for no samples")
+	}
+	if _, _, err = calcBitrates(samples, 0); err == nil {
+		t.Errorf("expected error for zero timescale")
+	}
+}
@@ -20,13 +20,17 @@ var decoders map[string]BoxDecoder
 func init() {
 	decoders = map[string]BoxDecoder{
 		"ac-3":    DecodeAudioSampleEntry,
+		"ainf":    DecodeAinf,
+		"alac":    DecodeAlac,
 		"avc1":    DecodeVisualSampleEntry,
 		"avc3":    DecodeVisualSampleEntry,
 		"avcC":    DecodeAvcC,
 		"btrt":    DecodeBtrt,
+		"bxml":    DecodeBxml,
 		"cdat":    DecodeCdat,
 		"cdsc":    DecodeTrefType,
 		"clap":    DecodeClap,
+		"csgp":    DecodeCsgp,
 		"cslg":    DecodeCslg,
 		"co64":    DecodeCo64,
 		"ctim":    DecodeCtim,
@@ -37,6 +41,11 @@ func init() {
 		"dinf":    DecodeDinf,
 		"dpnd":    DecodeTrefType,
 		"dref":    DecodeDref,
+		"dvcC":    DecodeDvcC,
+		"dvh1":    DecodeVisualSampleEntry,
+		"dvhe":    DecodeVisualSampleEntry,
+		"dvvC":    DecodeDvcC,
+		"dvwC":    DecodeDvcC,
 		"ec-3":    DecodeAudioSampleEntry,
 		"elng":    DecodeElng,
 		"esds":    DecodeEsds,
@@ -46,20 +55,26 @@ func init() {
 		"encv":    DecodeVisualSampleEntry,
 		"emsg":    DecodeEmsg,
 		"font":    DecodeTrefType,
+		"fpcm":    DecodeAudioSampleEntry,
 		"free":    DecodeFree,
 		"frma":    DecodeFrma,
 		"ftyp":    DecodeFtyp,
+		"grpl":    DecodeGrpl,
 		"hdlr":    DecodeHdlr,
 		"hev1":    DecodeVisualSampleEntry,
 		"hind":    DecodeTrefType,
 		"hint":    DecodeTrefType,
 		"hvcC":    DecodeHvcC,
 		"hvc1":    DecodeVisualSampleEntry,
+		"iacb":    DecodeIacb,
+		"iamf":    DecodeAudioSampleEntry,
 		"iden":    DecodeIden,
 		"ilst":    DecodeIlst,
 		"iods":    DecodeUnknown,
+		"ipcm":    DecodeAudioSampleEntry,
 		"ipir":    DecodeTrefType,
 		"kind":    DecodeKind,
+		"leva":    DecodeLeva,
 		"mdat":    DecodeMdat,
 		"mehd":    DecodeMehd,
 		"mdhd":    DecodeMdhd,
@@ -75,15 +90,21 @@ func init() {
 		"mpod":    DecodeTrefType,
 		"mvex":    DecodeMvex,
 		"mvhd":    DecodeMvhd,
+		"mp4s":    DecodeMp4s,
 		"mp4a":    DecodeAudioSampleEntry,
+		"mp4v":    DecodeVisualSampleEntry,
 		"nmhd":    DecodeNmhd,
 		"pasp":    DecodePasp,
 		"payl":    DecodePayl,
+		"pcmC":    DecodePcmC,
 		"prft":    DecodePrft,
 		"pssh":    DecodePssh,
+		"resv":    DecodeVisualSampleEntry,
+		"rinf":    DecodeRinf,
 		"saio":    DecodeSaio,
 		"saiz":    DecodeSaiz,
 		"sbgp":    DecodeSbgp,
+		"sbtt":    DecodeSbtt,
 		"schi":    DecodeSchi,
 		"schm":    DecodeSchm,
 		"sdtp":    DecodeSdtp,
@@ -93,6 +114,7 @@ func init() {
 		"sinf":    DecodeSinf,
 		"skip":    DecodeFree,
 		"smhd":    DecodeSmhd,
+		"srat":    DecodeSrat,
 		"sthd":    DecodeSthd,
 		"stbl":    DecodeStbl,
 		"stco":    DecodeStco,
@@ -117,6 +139,7 @@ func init() {
 		"tref":    DecodeTref,
 		"trep":    DecodeTrep,
 		"trex":    DecodeTrex,
+		"trik":    DecodeTrik,
 		"trun":    DecodeTrun,
 		"udta":    DecodeUdta,
 		"url ":    DecodeURLBox,
@@ -131,6 +154,7 @@ func init() {
 		"vttC":    DecodeVttC,
 		"vtte":    DecodeVtte,
 		"wvtt":    DecodeWvtt,
+		"xml ":    DecodeXml,
 		"\xa9too": DecodeCToo,
 	}
 }
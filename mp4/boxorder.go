@@ -0,0 +1,48 @@
+package mp4
+
+// BoxOrderReport - box types of the file in order. Top-level boxes are listed by type,
+// and the children of moov are listed directly after moov with a "moov/" prefix.
+func (f *File) BoxOrderReport() []string {
+	var report []string
+	for _, box := range f.Children {
+		report = append(report, box.Type())
+		if moov, ok := box.(*MoovBox); ok {
+			for _, child := range moov.Children {
+				report = append(report, "moov/"+child.Type())
+			}
+		}
+	}
+	return report
+}
+
+// IsCanonicalOrder - true if the boxes are in the order recommended by ISO/IEC 14496-12.
+// ftyp must be the first box if present, moov must come before any mdat or moof, each moof must be
+// directly followed by its mdat, and mvhd must be the first box in moov.
+// Other boxes such as free, sidx, styp, emsg, and prft may appear in between.
+func (f *File) IsCanonicalOrder() bool {
+	seenMedia := false
+	for i, box := range f.Children {
+		switch box.Type() {
+		case "ftyp":
+			if i != 0 {
+				return false
+			}
+		case "moov":
+			if seenMedia {
+				return false
+			}
+			moov := box.(*MoovBox)
+			if len(moov.Children) == 0 || moov.Children[0].Type() != "mvhd" {
+				return false
+			}
+		case "moof":
+			seenMedia = true
+			if i+1 == len(f.Children) || f.Children[i+1].Type() != "mdat" {
+				return false
+			}
+		case "mdat":
+			seenMedia = true
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBoxOrder(t *testing.T) {
+	f, err := ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.IsCanonicalOrder() {
+		t.Errorf("progressive file reported as non-canonical: %v", f.BoxOrderReport())
+	}
+	report := f.BoxOrderReport()
+	if len(report) < 3 || report[0] != "ftyp" || report[1] != "moov" || report[2] != "moov/mvhd" {
+		t.Errorf("unexpected box order report %v", report)
+	}
+
+	// Move moov after mdat
+	var children []Box
+	for _, c := range f.Children {
+		if c != f.Moov {
+			children = append(children, c)
+		}
+	}
+	f.Children = append(children, f.Moov)
+	var buf bytes.Buffer
+	if err = f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	moovLast, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moovLast.IsCanonicalOrder() {
+		t.Errorf("file with moov after mdat reported as canonical")
+	}
+	report = moovLast.BoxOrderReport()
+	mdatIdx, moovIdx := -1, -1
+	for i, boxType := range report {
+		switch boxType {
+		case "mdat":
+			mdatIdx = i
+		case "moov":
+			moovIdx = i
+		}
+	}
+	if mdatIdx < 0 || moovIdx < mdatIdx {
+		t.Errorf("report %v does not have moov after mdat", report)
+	}
+
+	frag, err := ReadMP4File("testdata/prog_8s_dec_dashinit.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !frag.IsCanonicalOrder() {
+		t.Errorf("fragmented file reported as non-canonical: %v", frag.BoxOrderReport())
+	}
+}
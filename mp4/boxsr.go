@@ -11,13 +11,17 @@ var decodersSR map[string]BoxDecoderSR
 func init() {
 	decodersSR = map[string]BoxDecoderSR{
 		"ac-3":    DecodeAudioSampleEntrySR,
+		"ainf":    DecodeAinfSR,
+		"alac":    DecodeAlacSR,
 		"avc1":    DecodeVisualSampleEntrySR,
 		"avc3":    DecodeVisualSampleEntrySR,
 		"avcC":    DecodeAvcCSR,
 		"btrt":    DecodeBtrtSR,
+		"bxml":    DecodeBxmlSR,
 		"cdat":    DecodeCdatSR,
 		"cdsc":    DecodeTrefTypeSR,
 		"clap":    DecodeClapSR,
+		"csgp":    DecodeCsgpSR,
 		"cslg":    DecodeCslgSR,
 		"co64":    DecodeCo64SR,
 		"ctim":    DecodeCtimSR,
@@ -28,6 +32,11 @@ func init() {
 		"dinf":    DecodeDinfSR,
 		"dpnd":    DecodeTrefTypeSR,
 		"dref":    DecodeDrefSR,
+		"dvcC":    DecodeDvcCSR,
+		"dvh1":    DecodeVisualSampleEntrySR,
+		"dvhe":    DecodeVisualSampleEntrySR,
+		"dvvC":    DecodeDvcCSR,
+		"dvwC":    DecodeDvcCSR,
 		"ec-3":    DecodeAudioSampleEntrySR,
 		"elng":    DecodeElngSR,
 		"esds":    DecodeEsdsSR,
@@ -37,20 +46,26 @@ func init() {
 		"encv":    DecodeVisualSampleEntrySR,
 		"emsg":    DecodeEmsgSR,
 		"font":    DecodeTrefTypeSR,
+		"fpcm":    DecodeAudioSampleEntrySR,
 		"free":    DecodeFreeSR,
 		"frma":    DecodeFrmaSR,
 		"ftyp":    DecodeFtypSR,
+		"grpl":    DecodeGrplSR,
 		"hdlr":    DecodeHdlrSR,
 		"hev1":    DecodeVisualSampleEntrySR,
 		"hind":    DecodeTrefTypeSR,
 		"hint":    DecodeTrefTypeSR,
 		"hvcC":    DecodeHvcCSR,
 		"hvc1":    DecodeVisualSampleEntrySR,
+		"iacb":    DecodeIacbSR,
+		"iamf":    DecodeAudioSampleEntrySR,
 		"iden":    DecodeIdenSR,
 		"ilst":    DecodeIlstSR,
 		"iods":    DecodeUnknownSR,
+		"ipcm":    DecodeAudioSampleEntrySR,
 		"ipir":    DecodeTrefTypeSR,
 		"kind":    DecodeKindSR,
+		"leva":    DecodeLevaSR,
 		"mdat":    DecodeMdatSR,
 		"mehd":    DecodeMehdSR,
 		"mdhd":    DecodeMdhdSR,
@@ -66,15 +81,21 @@ func init() {
 		"mpod":    DecodeTrefTypeSR,
 		"mvex":    DecodeMvexSR,
 		"mvhd":    DecodeMvhdSR,
+		"mp4s":    DecodeMp4sSR,
 		"mp4a":    DecodeAudioSampleEntrySR,
+		"mp4v":    DecodeVisualSampleEntrySR,
 		"nmhd":    DecodeNmhdSR,
 		"pasp":    DecodePaspSR,
 		"payl":    DecodePaylSR,
+		"pcmC":    DecodePcmCSR,
 		"prft":    DecodePrftSR,
 		"pssh":    DecodePsshSR,
+		"resv":    DecodeVisualSampleEntrySR,
+		"rinf":    DecodeRinfSR,
 		"saio":    DecodeSaioSR,
 		"saiz":    DecodeSaizSR,
 		"sbgp":    DecodeSbgpSR,
+		"sbtt":    DecodeSbttSR,
 		"schi":    DecodeSchiSR,
 		"schm":    DecodeSchmSR,
 		"sdtp":    DecodeSdtpSR,
@@ -84,6 +105,7 @@ func init() {
 		"sinf":    DecodeSinfSR,
 		"skip":    DecodeFreeSR,
 		"smhd":    DecodeSmhdSR,
+		"srat":    DecodeSratSR,
 		"sthd":    DecodeSthdSR,
 		"stbl":    DecodeStblSR,
 		"stco":    DecodeStcoSR,
@@ -108,6 +130,7 @@ func init() {
 		"tref":    DecodeTrefSR,
 		"trep":    DecodeTrepSR,
 		"trex":    DecodeTrexSR,
+		"trik":    DecodeTrikSR,
 		"trun":    DecodeTrunSR,
 		"udta":    DecodeUdtaSR,
 		"url ":    DecodeURLBoxSR,
@@ -122,6 +145,7 @@ func init() {
 		"vttC":    DecodeVttCSR,
 		"vtte":    DecodeVtteSR,
 		"wvtt":    DecodeWvttSR,
+		"xml ":    DecodeXmlSR,
 		"\xa9too": DecodeCTooSR,
 	}
 }
@@ -201,6 +225,14 @@ LoopBoxes:
 		switch boxType {
 		case "mdat":
 			if f.isFragmented {
+				if f.isEarlyMdat(lastBoxType) {
+					if err := f.keepEarlyMdat(box.(*MdatBox), boxStartPos); err != nil {
+						return nil, err
+					}
+					lastBoxType = boxType
+					boxStartPos += boxSize
+					continue LoopBoxes
+				}
 				if lastBoxType != "moof" {
 					return nil, fmt.Errorf("Does not support %v between moof and mdat", lastBoxType)
 				}
@@ -225,8 +257,18 @@ LoopBoxes:
 			}
 		}
 		f.AddChild(box, boxStartPos)
+		if boxType == "moof" {
+			f.attachEarlyMdat()
+		}
 		lastBoxType = boxType
 		boxStartPos += boxSize
 	}
+	f.dropEarlyMdat()
+	if f.AssumeContinuousTime {
+		err := f.synthesizeMissingTfdts()
+		if err != nil {
+			return nil, err
+		}
+	}
 	return f, nil
 }
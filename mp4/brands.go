@@ -0,0 +1,28 @@
+package mp4
+
+// CheckBrands - required brands that are not among the compatible brands of the ftyp box
+// or of any styp box of the media segments, in the order given by requiredBrands.
+// Major brands are not counted, since a brand should also be listed as compatible to be signaled.
+func CheckBrands(f *File, requiredBrands []string) []string {
+	present := make(map[string]bool)
+	if f.Ftyp != nil {
+		for _, brand := range f.Ftyp.CompatibleBrands() {
+			present[brand] = true
+		}
+	}
+	for _, seg := range f.Segments {
+		if seg.Styp == nil {
+			continue
+		}
+		for _, brand := range seg.Styp.CompatibleBrands() {
+			present[brand] = true
+		}
+	}
+	var missing []string
+	for _, brand := range requiredBrands {
+		if !present[brand] {
+			missing = append(missing, brand)
+		}
+	}
+	return missing
+}
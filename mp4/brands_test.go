@@ -0,0 +1,38 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestCheckBrands(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(48000, "audio", "und")
+	trex := init.Moov.Mvex.Trex
+	trex.DefaultSampleDuration = 1024
+	seg := CreateSingleSampleMediaSegment(trex.TrackID, 1, 0, trex, []byte{0x21}, 0)
+	var buf bytes.Buffer
+	if err := init.PrependTo(seg).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	f, err := DecodeFile(bytes.NewBuffer(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ftyp has major brand cmfc but only dash and iso6 as compatible brands, and styp has dash and msdh
+	missing := CheckBrands(f, []string{"cmfc", "msdh"})
+	if diff := deep.Equal(missing, []string{"cmfc"}); diff != nil {
+		t.Errorf("missing brands: %v", diff)
+	}
+	f.Ftyp = NewFtyp("cmfc", 0, []string{"cmfc", "dash", "iso6"})
+	if missing = CheckBrands(f, []string{"cmfc", "msdh"}); missing != nil {
+		t.Errorf("got missing brands %v instead of none", missing)
+	}
+	f.Segments[0].Styp = NewStyp("cmfs", 0, []string{"cmfs"})
+	if diff := deep.Equal(CheckBrands(f, []string{"cmfc", "msdh"}), []string{"msdh"}); diff != nil {
+		t.Errorf("missing brands without msdh: %v", diff)
+	}
+}
@@ -0,0 +1,67 @@
+package mp4
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChunkIssue - problem with the byte range of a chunk given by stco/co64, stsc, and stsz
+type ChunkIssue struct {
+	ChunkNr     uint32 // One-based chunk number, 0 if not related to a specific chunk
+	Offset      uint64
+	Size        uint64
+	Description string
+}
+
+// CheckChunkOffsets - check that all chunks are inside the mdat payload [mdatStart, mdatEnd),
+// that the chunk offsets are increasing, and that the chunk byte ranges do not overlap.
+// The chunk sizes are the sums of the sizes of the samples in the chunks.
+func (s *StblBox) CheckChunkOffsets(mdatStart, mdatEnd uint64) []ChunkIssue {
+	var offsets []uint64
+	switch {
+	case s.Stco != nil:
+		for _, offset := range s.Stco.ChunkOffset {
+			offsets = append(offsets, uint64(offset))
+		}
+	case s.Co64 != nil:
+		offsets = s.Co64.ChunkOffset
+	default:
+		return []ChunkIssue{{Description: "no stco or co64 box"}}
+	}
+	if s.Stsc == nil || s.Stsz == nil {
+		return []ChunkIssue{{Description: "no stsc or stsz box"}}
+	}
+	var issues []ChunkIssue
+	chunks := make([]ChunkIssue, 0, len(offsets)) // Chunk ranges without description
+	for i, offset := range offsets {
+		chunkNr := uint32(i + 1)
+		chunk := s.Stsc.GetChunk(chunkNr)
+		endSampleNr := chunk.StartSampleNr + chunk.NrSamples - 1
+		size, err := s.Stsz.GetTotalSampleSize(chunk.StartSampleNr, endSampleNr)
+		if err != nil {
+			issues = append(issues, ChunkIssue{chunkNr, offset, 0,
+				fmt.Sprintf("samples %d-%d not in stsz", chunk.StartSampleNr, endSampleNr)})
+			continue
+		}
+		if offset < mdatStart || offset+size > mdatEnd {
+			issues = append(issues, ChunkIssue{chunkNr, offset, size,
+				fmt.Sprintf("chunk outside mdat payload %d-%d", mdatStart, mdatEnd)})
+		}
+		if i > 0 && offset < offsets[i-1] {
+			issues = append(issues, ChunkIssue{chunkNr, offset, size,
+				fmt.Sprintf("offset lower than offset %d of previous chunk", offsets[i-1])})
+		}
+		chunks = append(chunks, ChunkIssue{ChunkNr: chunkNr, Offset: offset, Size: size})
+	}
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return chunks[i].Offset < chunks[j].Offset
+	})
+	for i := 1; i < len(chunks); i++ {
+		prev, curr := chunks[i-1], chunks[i]
+		if curr.Size > 0 && curr.Offset < prev.Offset+prev.Size {
+			issues = append(issues, ChunkIssue{curr.ChunkNr, curr.Offset, curr.Size,
+				fmt.Sprintf("chunk overlaps chunk %d", prev.ChunkNr)})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,49 @@
+package mp4
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckChunkOffsets(t *testing.T) {
+	fd, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	f, err := DecodeFile(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdatStart := f.Mdat.PayloadAbsoluteOffset()
+	mdatEnd := mdatStart + f.Mdat.DataLength()
+	for _, trak := range f.Moov.Traks {
+		if issues := trak.Mdia.Minf.Stbl.CheckChunkOffsets(mdatStart, mdatEnd); len(issues) != 0 {
+			t.Errorf("track %d: got issues %v", trak.Tkhd.TrackID, issues)
+		}
+	}
+
+	stbl := f.Moov.Traks[0].Mdia.Minf.Stbl
+	stco := stbl.Stco
+	stco.ChunkOffset[1] = uint32(mdatEnd + 100)
+	issues := stbl.CheckChunkOffsets(mdatStart, mdatEnd)
+	var outside, order bool
+	for _, issue := range issues {
+		switch {
+		case issue.ChunkNr == 2 && strings.Contains(issue.Description, "outside mdat"):
+			outside = true
+		case issue.ChunkNr == 3 && strings.Contains(issue.Description, "lower than"):
+			order = true
+		}
+	}
+	if !outside || !order {
+		t.Errorf("missing out-of-range or order issue in %v", issues)
+	}
+
+	stco.ChunkOffset[1] = stco.ChunkOffset[0] + 1
+	issues = stbl.CheckChunkOffsets(mdatStart, mdatEnd)
+	if len(issues) != 1 || !strings.Contains(issues[0].Description, "overlaps chunk 1") {
+		t.Errorf("got issues %v instead of overlap", issues)
+	}
+}
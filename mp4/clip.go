@@ -0,0 +1,290 @@
+package mp4
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+)
+
+// clipTrack - samples and timing of one track in a clip
+type clipTrack struct {
+	trak      *TrakBox
+	origStbl  *StblBox
+	mediaTime int64 // media time of the first non-empty edit in the original track
+	samples   []FullSample
+	firstNr   uint32 // one-based number of first kept sample in original track
+}
+
+// Clip - extract the time range [startSec, endSec) of a progressive file as a new self-contained progressive file.
+// The clip starts at the last sync sample at or before startSec in the first video track, and the other tracks
+// start at their last sync sample at or before that time, so that all tracks are decodable from the start.
+// Samples with decode time at or after endSec are dropped. The sample tables are rebuilt with one chunk per track
+// in a new mdat, and an edit list in each track trims the presentation to start exactly at startSec.
+// Times are relative to the presentation start given by the first non-empty edit of each track.
+// sbgp, csgp, subs, saiz, and saio boxes are not kept. rs is needed if the mdat is lazily decoded.
+func (f *File) Clip(startSec, endSec float64, rs io.ReadSeeker) (*File, error) {
+	if f.isFragmented || f.Moov == nil || f.Mdat == nil {
+		return nil, fmt.Errorf("only available for progressive files")
+	}
+	if startSec < 0 || endSec <= startSec {
+		return nil, fmt.Errorf("bad clip interval %.3f-%.3fs", startSec, endSec)
+	}
+	moov, err := copyMoov(f.Moov)
+	if err != nil {
+		return nil, err
+	}
+	var tracks []*clipTrack
+	videoIdx := -1
+	for i, trak := range moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		samples, err := f.fullSamplesForTrack(trackID, rs)
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %w", trackID, err)
+		}
+		tracks = append(tracks, &clipTrack{
+			trak:      trak,
+			origStbl:  f.Moov.Traks[i].Mdia.Minf.Stbl,
+			mediaTime: trakMediaTime(trak),
+			samples:   samples,
+		})
+		if videoIdx < 0 && trak.Mdia.Hdlr.HandlerType == "vide" {
+			videoIdx = i
+		}
+	}
+
+	alignSec := startSec
+	if videoIdx >= 0 {
+		ct := tracks[videoIdx]
+		if err := ct.trimStart(startSec); err != nil {
+			return nil, err
+		}
+		first := ct.samples[0]
+		alignSec = float64(int64(first.PresentationTime())-ct.mediaTime) / float64(ct.trak.Mdia.Mdhd.Timescale)
+	}
+	movieTimescale := float64(moov.Mvhd.Timescale)
+	var movieDur uint64
+	for i, ct := range tracks {
+		trackID := ct.trak.Tkhd.TrackID
+		if i != videoIdx {
+			if err := ct.trimStart(alignSec); err != nil {
+				return nil, err
+			}
+		}
+		timescale := float64(ct.trak.Mdia.Mdhd.Timescale)
+		endTime := uint64(endSec * timescale)
+		nrKept := 0
+		for nrKept < len(ct.samples) && ct.samples[nrKept].DecodeTime < endTime {
+			nrKept++
+		}
+		if nrKept == 0 {
+			return nil, fmt.Errorf("track %d: no samples in clip interval", trackID)
+		}
+		ct.samples = ct.samples[:nrKept]
+		if err := ct.rebuildStbl(); err != nil {
+			return nil, fmt.Errorf("track %d: %w", trackID, err)
+		}
+
+		first, last := ct.samples[0], ct.samples[nrKept-1]
+		mediaStart := int64(startSec*timescale) + ct.mediaTime - int64(first.DecodeTime)
+		if mediaStart < 0 {
+			mediaStart = 0
+		}
+		trackEndSec := float64(int64(last.DecodeTime+uint64(last.Dur))-ct.mediaTime) / timescale
+		if trackEndSec > endSec {
+			trackEndSec = endSec
+		}
+		segDur := uint64(0)
+		if trackEndSec > startSec {
+			segDur = uint64((trackEndSec - startSec) * movieTimescale)
+		}
+		elst := &ElstBox{Entries: []ElstEntry{{SegmentDuration: segDur, MediaTime: mediaStart, MediaRateInteger: 1}}}
+		if segDur > math.MaxUint32 || mediaStart > math.MaxInt32 {
+			elst.Version = 1
+		}
+		edts := &EdtsBox{}
+		edts.AddChild(elst)
+		ct.trak.setEdts(edts)
+		ct.trak.Tkhd.Duration = segDur
+		ct.trak.Mdia.Mdhd.Duration = last.DecodeTime + uint64(last.Dur) - first.DecodeTime
+		if segDur > movieDur {
+			movieDur = segDur
+		}
+	}
+	moov.Mvhd.Duration = movieDur
+
+	out := NewFile()
+	var pos uint64
+	if f.Ftyp != nil {
+		out.AddChild(f.Ftyp, pos)
+		pos += f.Ftyp.Size()
+	}
+	out.AddChild(moov, pos)
+	pos += moov.Size()
+	var mdatData []byte
+	chunkOffsets := make([]uint64, len(tracks))
+	for i, ct := range tracks {
+		chunkOffsets[i] = uint64(len(mdatData))
+		for _, s := range ct.samples {
+			mdatData = append(mdatData, s.Data...)
+		}
+	}
+	mdat := &MdatBox{}
+	mdat.SetData(mdatData)
+	if uint64(len(mdatData))+boxHeaderSize > math.MaxUint32 {
+		mdat.LargeSize = true
+	}
+	mdat.StartPos = pos
+	out.AddChild(mdat, pos)
+	payloadStart := mdat.PayloadAbsoluteOffset()
+	for i, ct := range tracks {
+		stbl := ct.trak.Mdia.Minf.Stbl
+		offset := payloadStart + chunkOffsets[i]
+		if stbl.Co64 != nil {
+			stbl.Co64.ChunkOffset[0] = offset
+			continue
+		}
+		if offset > math.MaxUint32 {
+			return nil, fmt.Errorf("chunk offset %d too big for stco", offset)
+		}
+		stbl.Stco.ChunkOffset[0] = uint32(offset)
+	}
+	return out, nil
+}
+
+// copyMoov - deep copy of moov made by encoding and decoding
+func copyMoov(moov *MoovBox) (*MoovBox, error) {
+	box, err := copyBox(moov)
+	if err != nil {
+		return nil, err
+	}
+	return box.(*MoovBox), nil
+}
+
+// copyBox - deep copy of b made by encoding and decoding
+func copyBox(b Box) (Box, error) {
+	var buf bytes.Buffer
+	if err := b.Encode(&buf); err != nil {
+		return nil, err
+	}
+	return DecodeBox(0, &buf)
+}
+
+// trakMediaTime - media time of first non-empty edit, or 0 if there is none
+func trakMediaTime(trak *TrakBox) int64 {
+	if trak.Edts == nil {
+		return 0
+	}
+	for _, elst := range trak.Edts.Elst {
+		for _, entry := range elst.Entries {
+			if entry.MediaTime >= 0 {
+				return entry.MediaTime
+			}
+		}
+	}
+	return 0
+}
+
+// trimStart - drop samples before the last sync sample with presentation time at or before startSec
+func (ct *clipTrack) trimStart(startSec float64) error {
+	startTime := int64(startSec * float64(ct.trak.Mdia.Mdhd.Timescale))
+	stss := ct.origStbl.Stss
+	firstIdx := -1
+	for i, s := range ct.samples {
+		if stss != nil && !stss.IsSyncSample(uint32(i+1)) {
+			continue
+		}
+		if int64(s.PresentationTime())-ct.mediaTime > startTime {
+			break
+		}
+		firstIdx = i
+	}
+	if firstIdx < 0 {
+		return fmt.Errorf("track %d: no sync sample at or before %.3fs", ct.trak.Tkhd.TrackID, startSec)
+	}
+	ct.samples = ct.samples[firstIdx:]
+	ct.firstNr = uint32(firstIdx + 1)
+	return nil
+}
+
+// rebuildStbl - replace sample tables with tables for the kept samples in one chunk
+func (ct *clipTrack) rebuildStbl() error {
+	orig := ct.origStbl
+	if len(orig.Stsc.SampleDescriptionID) > 0 {
+		return fmt.Errorf("multiple sample descriptions not supported")
+	}
+	stbl := NewStblBox()
+	stbl.AddChild(ct.trak.Mdia.Minf.Stbl.Stsd)
+	stts := &SttsBox{}
+	stsz := &StszBox{SampleNumber: uint32(len(ct.samples))}
+	var syncNrs []uint32
+	var cttsCounts []uint32
+	var cttsOffsets []int32
+	var sdtpEntries []SdtpEntry
+	for i, s := range ct.samples {
+		nrEntries := len(stts.SampleCount)
+		if nrEntries > 0 && stts.SampleTimeDelta[nrEntries-1] == s.Dur {
+			stts.SampleCount[nrEntries-1]++
+		} else {
+			stts.SampleCount = append(stts.SampleCount, 1)
+			stts.SampleTimeDelta = append(stts.SampleTimeDelta, s.Dur)
+		}
+		stsz.SampleSize = append(stsz.SampleSize, s.Size)
+		origNr := ct.firstNr + uint32(i)
+		if orig.Stss != nil && orig.Stss.IsSyncSample(origNr) {
+			syncNrs = append(syncNrs, uint32(i+1))
+		}
+		if orig.Ctts != nil {
+			nrCtts := len(cttsOffsets)
+			if nrCtts > 0 && cttsOffsets[nrCtts-1] == s.CompositionTimeOffset {
+				cttsCounts[nrCtts-1]++
+			} else {
+				cttsCounts = append(cttsCounts, 1)
+				cttsOffsets = append(cttsOffsets, s.CompositionTimeOffset)
+			}
+		}
+		if orig.Sdtp != nil {
+			sdtpEntries = append(sdtpEntries, orig.Sdtp.Entries[origNr-1])
+		}
+	}
+	stbl.AddChild(stts)
+	if orig.Ctts != nil {
+		ctts := &CttsBox{Version: orig.Ctts.Version}
+		if err := ctts.AddSampleCountsAndOffset(cttsCounts, cttsOffsets); err != nil {
+			return err
+		}
+		stbl.AddChild(ctts)
+	}
+	if orig.Stss != nil {
+		stss, err := CreateStss(syncNrs, uint32(len(ct.samples)))
+		if err != nil {
+			return err
+		}
+		if stss != nil {
+			stbl.AddChild(stss)
+		}
+	}
+	if orig.Sdtp != nil {
+		stbl.AddChild(CreateSdtpBox(sdtpEntries))
+	}
+	stsc := &StscBox{FirstChunk: []uint32{1}, SamplesPerChunk: []uint32{uint32(len(ct.samples))}}
+	stsc.SetSingleSampleDescriptionID(orig.Stsc.GetSampleDescriptionID(1))
+	stbl.AddChild(stsc)
+	stbl.AddChild(stsz)
+	if orig.Co64 != nil {
+		stbl.AddChild(&Co64Box{ChunkOffset: []uint64{0}})
+	} else {
+		stbl.AddChild(&StcoBox{ChunkOffset: []uint32{0}})
+	}
+	for _, sgpd := range ct.trak.Mdia.Minf.Stbl.Sgpds {
+		stbl.AddChild(sgpd)
+	}
+	minf := ct.trak.Mdia.Minf
+	for i, c := range minf.Children {
+		if c == minf.Stbl {
+			minf.Children[i] = stbl
+		}
+	}
+	minf.Stbl = stbl
+	return nil
+}
@@ -0,0 +1,143 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestClip(t *testing.T) {
+	f, err := ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	origSamples := make(map[uint32][]FullSample)
+	for _, trak := range f.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		origSamples[trackID], err = f.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	startSec, endSec := 2.0, 5.0
+	clip, err := f.Clip(startSec, endSec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = clip.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decClip, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	movieTimescale := uint64(decClip.Moov.Mvhd.Timescale)
+	if decClip.Moov.Mvhd.Duration != uint64(endSec-startSec)*movieTimescale {
+		t.Errorf("got movie duration %d instead of %d", decClip.Moov.Mvhd.Duration,
+			uint64(endSec-startSec)*movieTimescale)
+	}
+	for _, trak := range decClip.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		samples, err := decClip.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(samples) == 0 || !samples[0].IsSync() {
+			t.Fatalf("track %d: clip does not start with sync sample", trackID)
+		}
+		timescale := uint64(trak.Mdia.Mdhd.Timescale)
+		orig := origSamples[trackID]
+		// Find first clip sample in original by data and check that all following samples match
+		firstIdx := -1
+		for i := range orig {
+			if bytes.Equal(orig[i].Data, samples[0].Data) && orig[i].IsSync() {
+				firstIdx = i
+			}
+			if orig[i].DecodeTime > uint64(startSec)*timescale {
+				break
+			}
+		}
+		if firstIdx < 0 {
+			t.Fatalf("track %d: first clip sample not found in original", trackID)
+		}
+		origStart := orig[firstIdx].DecodeTime
+		if origStart > uint64(startSec)*timescale {
+			t.Errorf("track %d: clip starts at %d after start time", trackID, origStart)
+		}
+		for i, s := range samples {
+			o := orig[firstIdx+i]
+			if !bytes.Equal(s.Data, o.Data) || s.Dur != o.Dur || s.CompositionTimeOffset != o.CompositionTimeOffset {
+				t.Fatalf("track %d: clip sample %d differs from original", trackID, i+1)
+			}
+			if o.DecodeTime >= uint64(endSec)*timescale {
+				t.Fatalf("track %d: clip sample %d after end time", trackID, i+1)
+			}
+		}
+		next := firstIdx + len(samples)
+		if next < len(orig) && orig[next].DecodeTime < uint64(endSec)*timescale {
+			t.Errorf("track %d: sample with decode time %d before end missing", trackID, orig[next].DecodeTime)
+		}
+		elst := trak.Edts.Elst[0]
+		if len(elst.Entries) != 1 {
+			t.Fatalf("track %d: got %d edit list entries instead of 1", trackID, len(elst.Entries))
+		}
+		wantMediaTime := int64(uint64(startSec)*timescale-origStart) + trakMediaTime(f.Moov.GetTrak(trackID))
+		if elst.Entries[0].MediaTime != wantMediaTime {
+			t.Errorf("track %d: got edit media time %d instead of %d", trackID, elst.Entries[0].MediaTime, wantMediaTime)
+		}
+	}
+
+	if _, err = f.Clip(5, 2, nil); err == nil {
+		t.Errorf("expected error for bad clip interval")
+	}
+}
+
+func TestClipSingleGop(t *testing.T) {
+	f, err := ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trak := f.Moov.Traks[1]
+	stbl := trak.Mdia.Minf.Stbl
+	if stbl.Stss == nil || len(stbl.Stss.SampleNumber) < 3 {
+		t.Fatalf("video track has too few sync samples")
+	}
+	// Clip the first half of the second GOP, which has one sync sample
+	timescale := float64(trak.Mdia.Mdhd.Timescale)
+	gopStart, _ := stbl.Stts.GetDecodeTime(stbl.Stss.SampleNumber[1])
+	gopEnd, _ := stbl.Stts.GetDecodeTime(stbl.Stss.SampleNumber[2])
+	if stbl.Ctts != nil {
+		gopStart += uint64(stbl.Ctts.GetCompositionTimeOffset(stbl.Stss.SampleNumber[1]))
+	}
+	mediaTime := float64(trakMediaTime(trak))
+	startSec := (float64(gopStart) - mediaTime) / timescale
+	endSec := (float64(gopStart+(gopEnd-gopStart)/2) - mediaTime) / timescale
+	if startSec < 0 {
+		startSec = 0
+	}
+	clip, err := f.Clip(startSec, endSec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = clip.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decClip, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clipStbl := decClip.Moov.Traks[1].Mdia.Minf.Stbl
+	if clipStbl.Stsz.GetNrSamples() < 2 {
+		t.Fatalf("clip has too few samples")
+	}
+	if clipStbl.Stss == nil {
+		t.Fatalf("stss missing in single-GOP clip")
+	}
+	if len(clipStbl.Stss.SampleNumber) != 1 || clipStbl.Stss.SampleNumber[0] != 1 {
+		t.Errorf("got sync samples %v instead of [1]", clipStbl.Stss.SampleNumber)
+	}
+	if clipStbl.Stss.IsSyncSample(2) {
+		t.Errorf("second clip sample marked as sync")
+	}
+}
@@ -33,58 +33,73 @@ func DecryptSampleCenc(sample []byte, key []byte, iv []byte, subSamplePatterns [
 	return nil
 }
 
-// DecryptSampleCenc - decrypt cenc-schema encrypted sample in place provided key, iv, and subSamplePatterns
+// EncryptSampleCenc - encrypt sample in place with cenc scheme provided key, iv, and subSamplePatterns.
+// AES-CTR is symmetric, so this is the same operation as decryption.
+func EncryptSampleCenc(sample []byte, key []byte, iv []byte, subSamplePatterns []SubSamplePattern) error {
+	return DecryptSampleCenc(sample, key, iv, subSamplePatterns)
+}
+
+// DecryptSampleCbcs - decrypt cbcs-schema encrypted sample in place provided key, iv, and subSamplePatterns
 func DecryptSampleCbcs(sample []byte, key []byte, iv []byte, subSamplePatterns []SubSamplePattern, tenc *TencBox) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	cryptSampleCbcs(sample, func() cipher.BlockMode { return cipher.NewCBCDecrypter(block, iv) },
+		subSamplePatterns, tenc)
+	return nil
+}
+
+// EncryptSampleCbcs - encrypt sample in place with cbcs scheme provided key, iv, and subSamplePatterns.
+// The crypt and skip pattern is given by tenc.
+func EncryptSampleCbcs(sample []byte, key []byte, iv []byte, subSamplePatterns []SubSamplePattern, tenc *TencBox) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	cryptSampleCbcs(sample, func() cipher.BlockMode { return cipher.NewCBCEncrypter(block, iv) },
+		subSamplePatterns, tenc)
+	return nil
+}
+
+// cryptSampleCbcs - in place striped CBC encryption or decryption of the protected parts of a sample.
+// The CBC chain is restarted with the IV for every subsample.
+func cryptSampleCbcs(sample []byte, newMode func() cipher.BlockMode, subSamplePatterns []SubSamplePattern,
+	tenc *TencBox) {
 	nrInCryptBlock := int(tenc.DefaultCryptByteBlock) * 16
 	nrInSkipBlock := int(tenc.DefaultSkipByteBlock) * 16
+	if len(subSamplePatterns) == 0 { // Full cbcs - this should not happen for video since the first part should be in clear
+		cbcsCrypt(sample, newMode(), nrInCryptBlock, nrInSkipBlock)
+		return
+	}
 	var pos uint32 = 0
-	if len(subSamplePatterns) != 0 {
-		for j := 0; j < len(subSamplePatterns); j++ {
-			ss := subSamplePatterns[j]
-			nrClear := uint32(ss.BytesOfClearData)
-			pos += nrClear
-			if ss.BytesOfProtectedData > 0 {
-				err := cbcsDecrypt(sample[pos:pos+ss.BytesOfProtectedData], key,
-					iv, nrInCryptBlock, nrInSkipBlock)
-				if err != nil {
-					return err
-				}
-			}
-			pos += ss.BytesOfProtectedData
-		}
-	} else { // Full cbcs - this should not happen since the first part should be in clear
-		err := cbcsDecrypt(sample, key, iv, nrInCryptBlock, nrInSkipBlock)
-		if err != nil {
-			return err
+	for _, ss := range subSamplePatterns {
+		pos += uint32(ss.BytesOfClearData)
+		if ss.BytesOfProtectedData > 0 {
+			cbcsCrypt(sample[pos:pos+ss.BytesOfProtectedData], newMode(), nrInCryptBlock, nrInSkipBlock)
 		}
+		pos += ss.BytesOfProtectedData
 	}
-	return nil
 }
 
-// cbcDecrypt - in place striped or full CBC decryption. Full if nrInSkipBlock == 0
-func cbcsDecrypt(data []byte, key []byte, iv []byte, nrInCryptBlock, nrInSkipBlock int) error {
+// cbcsCrypt - in place striped or full CBC encryption or decryption. Full if nrInSkipBlock == 0
+func cbcsCrypt(data []byte, mode cipher.BlockMode, nrInCryptBlock, nrInSkipBlock int) {
 	pos := 0
 	size := len(data) // This is the bytes that we should stripe decrypt
-	aesCbcCrypto, err := aes.NewCipher(key)
-	if err != nil {
-		return err
-	}
-	dec := cipher.NewCBCDecrypter(aesCbcCrypto, iv)
 	if nrInSkipBlock == 0 {
-		nrToDecrypt := size & ^0xf // Drops 4 last bits -> multiple of 16
-		dec.CryptBlocks(data[:nrToDecrypt], data[:nrToDecrypt])
-		return nil
+		nrToCrypt := size & ^0xf // Drops 4 last bits -> multiple of 16
+		mode.CryptBlocks(data[:nrToCrypt], data[:nrToCrypt])
+		return
 	}
 	for {
 		if size-pos < nrInCryptBlock { // Leave the rest
 			break
 		}
-		dec.CryptBlocks(data[pos:pos+nrInCryptBlock], data[pos:pos+nrInCryptBlock])
+		mode.CryptBlocks(data[pos:pos+nrInCryptBlock], data[pos:pos+nrInCryptBlock])
 		pos += nrInCryptBlock
 		if size-pos < nrInSkipBlock {
 			break
 		}
 		pos += nrInSkipBlock
 	}
-	return nil
 }
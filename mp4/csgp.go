@@ -0,0 +1,209 @@
+package mp4
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// CsgpBox - Compact Sample To Group Box, ISO/IEC 14496-12 7'th edition 2022 Section 8.9.5
+//
+// Contained in : Sample Table Box (stbl) or Track Fragment Box (traf)
+//
+// Samples are mapped to group description indices by patterns that are repeated for sample_count samples.
+// The sizes of the pattern length, sample count, and index fields are given by the flags.
+type CsgpBox struct {
+	Version                       byte
+	Flags                         uint32
+	GroupingType                  string // uint32, but takes values such as seig
+	GroupingTypeParameter         uint32
+	PatternLengths                []uint32
+	SampleCounts                  []uint32
+	SampleGroupDescriptionIndices [][]uint32 // Raw field values. Use GroupDescriptionIndex for sbgp-style values
+}
+
+const (
+	csgpIndexMsbFragmentLocalFlag        = 0x80
+	csgpGroupingTypeParameterPresentFlag = 0x40
+)
+
+// DecodeCsgp - box-specific decode
+func DecodeCsgp(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeCsgpSR(hdr, startPos, sr)
+}
+
+// DecodeCsgpSR - box-specific decode
+func DecodeCsgpSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := CsgpBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	b.GroupingType = sr.ReadFixedLengthString(4)
+	nrBytes := 12
+	if b.GroupingTypeParameterPresent() {
+		b.GroupingTypeParameter = sr.ReadUint32()
+		nrBytes += 4
+	}
+	patternCount := sr.ReadUint32()
+	if sr.AccError() != nil {
+		return nil, sr.AccError()
+	}
+	rest := sr.ReadBytes(hdr.payloadLen() - nrBytes)
+	br := bits.NewAccErrReader(bytes.NewBuffer(rest))
+	patternSize, countSize, indexSize := b.fieldSizes()
+	if uint64(patternCount)*uint64(patternSize+countSize) > uint64(8*len(rest)) {
+		return nil, fmt.Errorf("csgp pattern count %d too big", patternCount)
+	}
+	b.PatternLengths = make([]uint32, patternCount)
+	b.SampleCounts = make([]uint32, patternCount)
+	for i := range b.PatternLengths {
+		b.PatternLengths[i] = uint32(br.Read(patternSize))
+		b.SampleCounts[i] = uint32(br.Read(countSize))
+	}
+	b.SampleGroupDescriptionIndices = make([][]uint32, patternCount)
+	for i, patternLength := range b.PatternLengths {
+		if uint64(patternLength)*uint64(indexSize) > uint64(8*len(rest)) {
+			return nil, fmt.Errorf("csgp pattern length %d too big", patternLength)
+		}
+		b.SampleGroupDescriptionIndices[i] = make([]uint32, patternLength)
+		for j := range b.SampleGroupDescriptionIndices[i] {
+			b.SampleGroupDescriptionIndices[i][j] = uint32(br.Read(indexSize))
+		}
+	}
+	if br.AccError() != nil {
+		return nil, fmt.Errorf("decode csgp: %w", br.AccError())
+	}
+	return &b, sr.AccError()
+}
+
+// GroupingTypeParameterPresent - is grouping_type_parameter present (flag bit)
+func (b *CsgpBox) GroupingTypeParameterPresent() bool {
+	return b.Flags&csgpGroupingTypeParameterPresentFlag != 0
+}
+
+// IndexMsbIndicatesFragmentLocal - does the most significant bit of index fields signal fragment-local sgpd
+func (b *CsgpBox) IndexMsbIndicatesFragmentLocal() bool {
+	return b.Flags&csgpIndexMsbFragmentLocalFlag != 0
+}
+
+// fieldSizes - number of bits for pattern_length, sample_count, and sample_group_description_index
+func (b *CsgpBox) fieldSizes() (patternSize, countSize, indexSize int) {
+	return csgpFieldSize(b.Flags >> 4), csgpFieldSize(b.Flags >> 2), csgpFieldSize(b.Flags)
+}
+
+// csgpFieldSize - field size 4, 8, 16, or 32 bits from 2-bit size code in lowest bits
+func csgpFieldSize(code uint32) int {
+	return 4 << (code & 3)
+}
+
+// GroupDescriptionIndex - group description index for one-based sampleNr, 0 if not in any group.
+// A fragment-local index is returned with an offset of 65536 as in sbgp.
+func (b *CsgpBox) GroupDescriptionIndex(sampleNr uint32) uint32 {
+	if sampleNr == 0 {
+		return 0
+	}
+	_, _, indexSize := b.fieldSizes()
+	sampleIdx := sampleNr - 1
+	for i, count := range b.SampleCounts {
+		if sampleIdx >= count {
+			sampleIdx -= count
+			continue
+		}
+		patternLength := b.PatternLengths[i]
+		if patternLength == 0 {
+			return 0
+		}
+		index := b.SampleGroupDescriptionIndices[i][sampleIdx%patternLength]
+		if b.IndexMsbIndicatesFragmentLocal() {
+			msb := uint32(1) << uint(indexSize-1)
+			if index&msb != 0 {
+				index = index&^msb + sbgpInsideOffset
+			}
+		}
+		return index
+	}
+	return 0
+}
+
+// Type - return box type
+func (b *CsgpBox) Type() string {
+	return "csgp"
+}
+
+// Size - return calculated size
+func (b *CsgpBox) Size() uint64 {
+	size := uint64(boxHeaderSize + 12)
+	if b.GroupingTypeParameterPresent() {
+		size += 4
+	}
+	patternSize, countSize, indexSize := b.fieldSizes()
+	nrBits := uint64(len(b.PatternLengths)) * uint64(patternSize+countSize)
+	for _, indices := range b.SampleGroupDescriptionIndices {
+		nrBits += uint64(len(indices)) * uint64(indexSize)
+	}
+	return size + (nrBits+7)/8
+}
+
+// Encode - write box to w
+func (b *CsgpBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *CsgpBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteString(b.GroupingType, false)
+	if b.GroupingTypeParameterPresent() {
+		sw.WriteUint32(b.GroupingTypeParameter)
+	}
+	sw.WriteUint32(uint32(len(b.PatternLengths)))
+	patternSize, countSize, indexSize := b.fieldSizes()
+	for i := range b.PatternLengths {
+		sw.WriteBits(uint(b.PatternLengths[i]), patternSize)
+		sw.WriteBits(uint(b.SampleCounts[i]), countSize)
+	}
+	for _, indices := range b.SampleGroupDescriptionIndices {
+		for _, index := range indices {
+			sw.WriteBits(uint(index), indexSize)
+		}
+	}
+	sw.FlushBits()
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *CsgpBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) (err error) {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - groupingType: %s", b.GroupingType)
+	if b.GroupingTypeParameterPresent() {
+		bd.write(" - groupingTypeParameter: %d", b.GroupingTypeParameter)
+	}
+	bd.write(" - patternCount: %d", len(b.PatternLengths))
+	level := getInfoLevel(b, specificBoxLevels)
+	if level > 0 {
+		for i := range b.PatternLengths {
+			bd.write(" - pattern[%d]: sampleCount=%d indices=%v", i+1, b.SampleCounts[i],
+				b.SampleGroupDescriptionIndices[i])
+		}
+	}
+	return bd.err
+}
@@ -0,0 +1,81 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// csgpHex - csgp box for seig with 4-bit pattern lengths and indices, 8-bit sample counts,
+// and msb of index signaling fragment-local sgpd. Patterns: [local 1, 0] x 6 samples, [1] x 2 samples
+const csgpHex = "00000019637367700000008473656967000000022061029010"
+
+func TestCsgp(t *testing.T) {
+	data, _ := hex.DecodeString(csgpHex)
+	box, err := DecodeBox(0, bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	csgp := box.(*CsgpBox)
+	if csgp.GroupingType != "seig" || len(csgp.PatternLengths) != 2 {
+		t.Fatalf("csgp not decoded properly: %+v", csgp)
+	}
+	wantedIndices := []uint32{65537, 0, 65537, 0, 65537, 0, 1, 1, 0}
+	for i, wanted := range wantedIndices {
+		if got := csgp.GroupDescriptionIndex(uint32(i + 1)); got != wanted {
+			t.Errorf("sample %d: got index %d instead of %d", i+1, got, wanted)
+		}
+	}
+	var buf bytes.Buffer
+	err = csgp.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("got %x instead of %x", buf.Bytes(), data)
+	}
+	boxDiffAfterEncodeAndDecode(t, csgp)
+	boxDiffAfterEncodeAndDecode(t, &CsgpBox{Flags: 0x40 | 0x3f, GroupingType: "roll", GroupingTypeParameter: 7,
+		PatternLengths: []uint32{3}, SampleCounts: []uint32{100}, SampleGroupDescriptionIndices: [][]uint32{{1, 2, 0}}})
+
+	// Key IDs from fragment-local and stbl-level seig entries
+	kidLocal := UUID(bytes.Repeat([]byte{0x11}, 16))
+	kidGlobal := UUID(bytes.Repeat([]byte{0x22}, 16))
+	traf := &TrafBox{}
+	_ = traf.AddChild(csgp)
+	_ = traf.AddChild(&SgpdBox{Version: 1, GroupingType: "seig", DefaultLength: 20,
+		SampleGroupEntries: []SampleGroupEntry{&SeigSampleGroupEntry{IsProtected: 1, PerSampleIVSize: 16, KID: kidLocal}}})
+	stbl := NewStblBox()
+	stbl.AddChild(&SgpdBox{Version: 1, GroupingType: "seig", DefaultLength: 20,
+		SampleGroupEntries: []SampleGroupEntry{&SeigSampleGroupEntry{IsProtected: 1, PerSampleIVSize: 16, KID: kidGlobal}}})
+	if kid, ok := traf.KeyIDForSample(1, stbl); !ok || !bytes.Equal(kid, kidLocal) {
+		t.Errorf("sample 1: got KID %s, %t instead of local KID", kid, ok)
+	}
+	if kid, ok := traf.KeyIDForSample(7, stbl); !ok || !bytes.Equal(kid, kidGlobal) {
+		t.Errorf("sample 7: got KID %s, %t instead of stbl KID", kid, ok)
+	}
+	if _, ok := traf.KeyIDForSample(2, stbl); ok {
+		t.Errorf("sample 2: expected no KID")
+	}
+	if _, ok := traf.KeyIDForSample(7, nil); ok {
+		t.Errorf("sample 7: expected no KID without stbl")
+	}
+
+	// Roll distance with sbgp and csgp
+	for _, sampleToGroup := range []Box{
+		&SbgpBox{GroupingType: "roll", SampleCounts: []uint32{1, 2}, GroupDescriptionIndices: []uint32{0, 1}},
+		&CsgpBox{Flags: 0x04, GroupingType: "roll", PatternLengths: []uint32{1, 1},
+			SampleCounts: []uint32{1, 2}, SampleGroupDescriptionIndices: [][]uint32{{0}, {1}}},
+	} {
+		stbl := NewStblBox()
+		stbl.AddChild(sampleToGroup)
+		stbl.AddChild(&SgpdBox{Version: 1, GroupingType: "roll", DefaultLength: 2,
+			SampleGroupEntries: []SampleGroupEntry{&RollSampleGroupEntry{RollDistance: -2}}})
+		if _, ok := stbl.RollDistance(1); ok {
+			t.Errorf("%s: expected no roll distance for sample 1", sampleToGroup.Type())
+		}
+		if roll, ok := stbl.RollDistance(3); !ok || roll != -2 {
+			t.Errorf("%s: got roll distance %d, %t instead of -2", sampleToGroup.Type(), roll, ok)
+		}
+	}
+}
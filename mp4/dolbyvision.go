@@ -0,0 +1,177 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Dolby Vision RPU and enhancement layer NAL unit types in HEVC streams (unspecified types in ISO/IEC 23008-2)
+const (
+	dvRPUNaluType = 62
+	dvELNaluType  = 63
+)
+
+// ExtractDolbyVisionBaseLayer - copy of the file where trackID is a plain HEVC track with the Dolby Vision
+// base layer only. The Dolby Vision configuration box (dvcC, dvvC, or dvwC) is removed, and dvh1/dvhe sample
+// entries are changed to hvc1/hev1. The base layer must be signaled as present and backward compatible.
+// In fragmented files, RPU and enhancement layer NAL units (types 62 and 63) are removed from the samples,
+// and subs boxes of the track are dropped since they no longer match the sample data.
+// In progressive files, the sample data is kept, since HEVC decoders discard these NAL unit types.
+// The mdat data must be available, i.e. the file must not have been decoded lazily.
+func (f *File) ExtractDolbyVisionBaseLayer(trackID uint32) (*File, error) {
+	var buf bytes.Buffer
+	if err := f.Encode(&buf); err != nil {
+		return nil, err
+	}
+	out, err := DecodeFile(&buf)
+	if err != nil {
+		return nil, err
+	}
+	trak := out.getTrak(trackID)
+	if trak == nil {
+		return nil, fmt.Errorf("no track with trackID %d", trackID)
+	}
+	nrDV := 0
+	for _, c := range trak.Mdia.Minf.Stbl.Stsd.Children {
+		vse, ok := c.(*VisualSampleEntryBox)
+		if !ok || vse.DvcC == nil {
+			continue
+		}
+		dvcC := vse.DvcC
+		if !dvcC.BLPresent || dvcC.BLSignalCompatibilityID == 0 {
+			return nil, fmt.Errorf("Dolby Vision profile %d has no backward-compatible base layer", dvcC.Profile)
+		}
+		if vse.HvcC == nil {
+			return nil, fmt.Errorf("no hvcC box in %s sample entry", vse.Type())
+		}
+		removeChildBox(&vse.Children, dvcC.Type())
+		vse.DvcC = nil
+		switch vse.Type() {
+		case "dvh1":
+			vse.SetType("hvc1")
+		case "dvhe":
+			vse.SetType("hev1")
+		}
+		nrDV++
+	}
+	if nrDV == 0 {
+		return nil, fmt.Errorf("no Dolby Vision configuration in track %d", trackID)
+	}
+	// Set stsd fields for changed sample entry types
+	stsd := trak.Mdia.Minf.Stbl.Stsd
+	children := stsd.Children
+	stsd.Children, stsd.SampleCount = nil, 0
+	for _, c := range children {
+		stsd.AddChild(c)
+	}
+
+	for _, seg := range out.Segments {
+		for _, frag := range seg.Fragments {
+			if err := out.stripDolbyVisionNalus(frag, trackID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// stripDolbyVisionNalus - remove RPU and EL NAL units from samples of trackID in frag and rebuild the mdat
+func (f *File) stripDolbyVisionNalus(frag *Fragment, trackID uint32) error {
+	if frag.Moof == nil || frag.Mdat == nil {
+		return nil
+	}
+	var newData []byte
+	newSizes := make(map[*TrunBox][]uint32)
+	changed := false
+	for _, traf := range frag.Moof.Trafs {
+		trex := f.getTrex(traf.Tfhd.TrackID)
+		if trex == nil {
+			trex = &TrexBox{TrackID: traf.Tfhd.TrackID}
+		}
+		samples, err := frag.GetFullSamples(trex)
+		if err != nil {
+			return err
+		}
+		idx := 0
+		for _, trun := range traf.Truns {
+			sizes := make([]uint32, 0, len(trun.Samples))
+			for range trun.Samples {
+				data := samples[idx].Data
+				if traf.Tfhd.TrackID == trackID {
+					stripped, err := removeNalus(data, isDolbyVisionNalu)
+					if err != nil {
+						return err
+					}
+					changed = changed || len(stripped) != len(data)
+					data = stripped
+				}
+				sizes = append(sizes, uint32(len(data)))
+				newData = append(newData, data...)
+				idx++
+			}
+			newSizes[trun] = sizes
+		}
+	}
+	if !changed {
+		return nil
+	}
+	writeOrderNr := uint32(1)
+	for _, traf := range frag.Moof.Trafs {
+		for _, trun := range traf.Truns {
+			if traf.Tfhd.TrackID == trackID {
+				for i, size := range newSizes[trun] {
+					trun.Samples[i].Size = size
+				}
+				trun.Flags |= TrunSampleSizePresentFlag
+			}
+			trun.writeOrderNr = writeOrderNr
+			writeOrderNr++
+		}
+		if traf.Tfhd.TrackID == trackID {
+			removeChildBox(&traf.Children, "subs")
+		}
+	}
+	frag.Mdat.SetData(newData)
+	frag.DataOffsetMode = DataOffsetDefaultBaseIsMoof
+	return nil
+}
+
+// isDolbyVisionNalu - true if naluHeader is the first byte of a Dolby Vision RPU or EL NAL unit
+func isDolbyVisionNalu(naluHeader byte) bool {
+	naluType := (naluHeader >> 1) & 0x3f
+	return naluType == dvRPUNaluType || naluType == dvELNaluType
+}
+
+// removeNalus - remove NAL units for which remove is true from a sample with 4-byte NAL unit lengths.
+// remove is called with the first byte of the NAL unit header.
+func removeNalus(sample []byte, remove func(naluHeader byte) bool) ([]byte, error) {
+	out := make([]byte, 0, len(sample))
+	pos := 0
+	for pos < len(sample) {
+		if pos+4 > len(sample) {
+			return nil, fmt.Errorf("incomplete NAL unit length")
+		}
+		naluLen := int(binary.BigEndian.Uint32(sample[pos : pos+4]))
+		end := pos + 4 + naluLen
+		if naluLen == 0 || end > len(sample) {
+			return nil, fmt.Errorf("bad NAL unit length %d", naluLen)
+		}
+		if !remove(sample[pos+4]) {
+			out = append(out, sample[pos:end]...)
+		}
+		pos = end
+	}
+	return out, nil
+}
+
+// removeChildBox - remove all boxes of boxType from children
+func removeChildBox(children *[]Box, boxType string) {
+	kept := (*children)[:0]
+	for _, c := range *children {
+		if c.Type() != boxType {
+			kept = append(kept, c)
+		}
+	}
+	*children = kept
+}
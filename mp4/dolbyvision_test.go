@@ -0,0 +1,98 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestDvcC(t *testing.T) {
+	for _, profile := range []byte{5, 8, 20} {
+		dvcC := CreateDvcC(profile, 6, true, false, profile != 5, 1)
+		boxDiffAfterEncodeAndDecode(t, dvcC)
+	}
+}
+
+func TestExtractDolbyVisionBaseLayer(t *testing.T) {
+	vps, _ := hex.DecodeString(vpsHex)
+	sps, _ := hex.DecodeString(spsHex)
+	pps, _ := hex.DecodeString(ppsHex)
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	trak := init.Moov.Trak
+	err := trak.SetHEVCDescriptor("hvc1", [][]byte{vps}, [][]byte{sps}, [][]byte{pps}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Profile 8.1 with HDR10-compatible base layer
+	trak.Mdia.Minf.Stbl.Stsd.HvcX.AddChild(CreateDvcC(8, 6, true, false, true, 1))
+	var buf bytes.Buffer
+	if err = init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	idrNalu := []byte{0x26, 0x01, 0xaf, 0x00}
+	rpuNalu := []byte{0x7c, 0x01, 0x19, 0x08}
+	sample := []byte{0, 0, 0, 4}
+	sample = append(sample, idrNalu...)
+	sample = append(sample, 0, 0, 0, 4)
+	sample = append(sample, rpuNalu...)
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frag.AddFullSample(FullSample{
+		Sample: NewSample(SyncSampleFlags, 3000, uint32(len(sample)), 0),
+		Data:   sample,
+	})
+	if err = frag.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dvcC := f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.HvcX.DvcC
+	if dvcC == nil || dvcC.Type() != "dvvC" || dvcC.Profile != 8 {
+		t.Fatalf("dvvC not decoded")
+	}
+
+	out, err := f.ExtractDolbyVisionBaseLayer(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hvcX := out.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.HvcX
+	if hvcX == nil || hvcX.Type() != "hvc1" || hvcX.HvcC == nil || hvcX.DvcC != nil {
+		t.Fatalf("not a plain hvc1 sample entry")
+	}
+	for _, c := range hvcX.Children {
+		if c.Type() == "dvvC" {
+			t.Errorf("dvvC box not removed")
+		}
+	}
+	buf.Reset()
+	if err = out.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	outFile, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples, err := outFile.Segments[0].Fragments[0].GetFullSamples(outFile.Init.Moov.Mvex.Trex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantedSample := append([]byte{0, 0, 0, 4}, idrNalu...)
+	if len(samples) != 1 || !bytes.Equal(samples[0].Data, wantedSample) {
+		t.Errorf("RPU NAL unit not removed from sample")
+	}
+	// The original file is not changed
+	if f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.HvcX.DvcC == nil {
+		t.Errorf("original file changed")
+	}
+
+	// Profile 5 has no backward-compatible base layer
+	f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.HvcX.DvcC.BLSignalCompatibilityID = 0
+	if _, err = f.ExtractDolbyVisionBaseLayer(1); err == nil {
+		t.Errorf("expected error for non-compatible base layer")
+	}
+}
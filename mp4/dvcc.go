@@ -0,0 +1,139 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// dvcCPayloadLen - size of DOVIDecoderConfigurationRecord including reserved bytes
+const dvcCPayloadLen = 24
+
+// DvcCBox - Dolby Vision configuration box (dvcC, dvvC, or dvwC)
+// Defined in Dolby Vision Streams Within the ISO Base Media File Format, Section 3.2
+//
+// Contained in : Visual Sample Entry (hvc1, hev1, dvh1, dvhe, avc1, avc3, dvav, dva1)
+//
+// dvcC is used for profiles up to 7, dvvC for profiles 8-10, and dvwC for higher profiles.
+// All have the same DOVIDecoderConfigurationRecord payload.
+type DvcCBox struct {
+	name                    string
+	VersionMajor            byte
+	VersionMinor            byte
+	Profile                 byte
+	Level                   byte
+	RPUPresent              bool
+	ELPresent               bool
+	BLPresent               bool
+	BLSignalCompatibilityID byte
+}
+
+// CreateDvcC - create Dolby Vision configuration box of right type (dvcC, dvvC, or dvwC) for profile
+func CreateDvcC(profile, level byte, rpuPresent, elPresent, blPresent bool, blSignalCompatibilityID byte) *DvcCBox {
+	name := "dvcC"
+	switch {
+	case profile > 10:
+		name = "dvwC"
+	case profile > 7:
+		name = "dvvC"
+	}
+	return &DvcCBox{
+		name:                    name,
+		VersionMajor:            1,
+		VersionMinor:            0,
+		Profile:                 profile,
+		Level:                   level,
+		RPUPresent:              rpuPresent,
+		ELPresent:               elPresent,
+		BLPresent:               blPresent,
+		BLSignalCompatibilityID: blSignalCompatibilityID,
+	}
+}
+
+// DecodeDvcC - box-specific decode
+func DecodeDvcC(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeDvcCSR(hdr, startPos, sr)
+}
+
+// DecodeDvcCSR - box-specific decode
+func DecodeDvcCSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.payloadLen() < dvcCPayloadLen {
+		return nil, fmt.Errorf("decode %s: too short payload %d bytes", hdr.Name, hdr.payloadLen())
+	}
+	b := DvcCBox{name: hdr.Name}
+	b.VersionMajor = sr.ReadUint8()
+	b.VersionMinor = sr.ReadUint8()
+	word := sr.ReadUint16()
+	b.Profile = byte(word >> 9)
+	b.Level = byte((word >> 3) & 0x3f)
+	b.RPUPresent = word&0x04 != 0
+	b.ELPresent = word&0x02 != 0
+	b.BLPresent = word&0x01 != 0
+	b.BLSignalCompatibilityID = sr.ReadUint8() >> 4
+	sr.SkipBytes(hdr.payloadLen() - 5) // reserved
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *DvcCBox) Type() string {
+	return b.name
+}
+
+// Size - return calculated size
+func (b *DvcCBox) Size() uint64 {
+	return uint64(boxHeaderSize + dvcCPayloadLen)
+}
+
+// Encode - write box to w
+func (b *DvcCBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *DvcCBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint8(b.VersionMajor)
+	sw.WriteUint8(b.VersionMinor)
+	word := uint16(b.Profile)<<9 | uint16(b.Level&0x3f)<<3
+	if b.RPUPresent {
+		word |= 0x04
+	}
+	if b.ELPresent {
+		word |= 0x02
+	}
+	if b.BLPresent {
+		word |= 0x01
+	}
+	sw.WriteUint16(word)
+	sw.WriteUint8(b.BLSignalCompatibilityID << 4)
+	sw.WriteZeroBytes(dvcCPayloadLen - 5)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *DvcCBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - dvVersion: %d.%d", b.VersionMajor, b.VersionMinor)
+	bd.write(" - dvProfile: %d", b.Profile)
+	bd.write(" - dvLevel: %d", b.Level)
+	bd.write(" - rpuPresent: %t", b.RPUPresent)
+	bd.write(" - elPresent: %t", b.ELPresent)
+	bd.write(" - blPresent: %t", b.BLPresent)
+	bd.write(" - blSignalCompatibilityID: %d", b.BLSignalCompatibilityID)
+	return bd.err
+}
@@ -57,6 +57,9 @@ func DecodeEdtsSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 
 // AddChild - Add a child box and update EntryCount
 func (e *EdtsBox) AddChild(child Box) {
+	if elst, ok := child.(*ElstBox); ok {
+		e.Elst = append(e.Elst, elst)
+	}
 	e.Children = append(e.Children, child)
 }
 
@@ -16,6 +16,7 @@ type ElstBox struct {
 	Entries []ElstEntry
 }
 
+// ElstEntry - single edit list entry. The media rate is a 16.16 fixed-point value split into two parts
 type ElstEntry struct {
 	SegmentDuration   uint64
 	MediaTime         int64
@@ -23,6 +24,11 @@ type ElstEntry struct {
 	MediaRateFraction int16
 }
 
+// MediaRate - media rate as float where the fraction is the unsigned lower 16 bits of the 16.16 value
+func (e ElstEntry) MediaRate() float64 {
+	return float64(e.MediaRateInteger) + float64(uint16(e.MediaRateFraction))/65536
+}
+
 // DecodeElst - box-specific decode
 func DecodeElst(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
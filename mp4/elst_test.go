@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -27,3 +28,42 @@ func TestElst(t *testing.T) {
 		boxDiffAfterEncodeAndDecode(t, elst)
 	}
 }
+
+func TestElstMediaRateFraction(t *testing.T) {
+	// Slow motion edit with media rate 0.5 (16.16 value 0x00008000)
+	elst := &ElstBox{
+		Version: 0,
+		Entries: []ElstEntry{
+			{2000, 0, 0, -0x8000},
+			{1000, 4000, 1, 0x4000},
+		},
+	}
+	rates := []float64{0.5, 1.25}
+	for i, e := range elst.Entries {
+		if e.MediaRate() != rates[i] {
+			t.Errorf("entry %d: got media rate %f instead of %f", i, e.MediaRate(), rates[i])
+		}
+	}
+	var buf bytes.Buffer
+	err := elst.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if !bytes.Equal(data[24:28], []byte{0x00, 0x00, 0x80, 0x00}) {
+		t.Errorf("got media rate bytes %x instead of 00008000", data[24:28])
+	}
+	decBox, err := DecodeBox(0, bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reBuf bytes.Buffer
+	err = decBox.Encode(&reBuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, reBuf.Bytes()) {
+		t.Errorf("round-trip not byte-exact: %x != %x", reBuf.Bytes(), data)
+	}
+	boxDiffAfterEncodeAndDecode(t, elst)
+}
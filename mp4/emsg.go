@@ -155,3 +155,16 @@ func (b *EmsgBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 
 	return bd.err
 }
+
+// CollectEmsgSchemes - number of emsg boxes (version 0 or 1) per scheme_id_uri in the fragments of segs
+func CollectEmsgSchemes(segs []*MediaSegment) map[string]int {
+	schemes := make(map[string]int)
+	for _, seg := range segs {
+		for _, frag := range seg.Fragments {
+			for _, emsg := range frag.Emsgs {
+				schemes[emsg.SchemeIDURI]++
+			}
+		}
+	}
+	return schemes
+}
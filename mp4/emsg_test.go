@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/go-test/deep"
@@ -91,3 +92,60 @@ func TestEmsgMessageDataIsEncoded(t *testing.T) {
 	}
 
 }
+
+func TestCollectEmsgSchemes(t *testing.T) {
+	id3 := "https://aomedia.org/emsg/ID3"
+	scte35 := "urn:scte:scte35:2013:bin"
+	emsgs := [][]*EmsgBox{
+		{
+			{Version: 0, TimeScale: 90000, SchemeIDURI: id3, Value: "1"},
+			{Version: 1, TimeScale: 90000, PresentationTime: 0, SchemeIDURI: scte35},
+		},
+		{
+			{Version: 1, TimeScale: 90000, PresentationTime: 90000, SchemeIDURI: id3},
+		},
+	}
+	var buf bytes.Buffer
+	for i, segEmsgs := range emsgs {
+		seg := NewMediaSegment()
+		frag, err := CreateFragment(uint32(i+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frag.AddFullSample(FullSample{
+			Sample:     NewSample(SyncSampleFlags, 1000, 1, 0),
+			DecodeTime: uint64(i * 1000),
+			Data:       []byte{0x01},
+		})
+		seg.AddFragment(frag)
+		if err = seg.Styp.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		for _, emsg := range segEmsgs {
+			if err = emsg.Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = frag.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	encBytes := buf.Bytes()
+	f, err := DecodeFile(bytes.NewBuffer(encBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemes := CollectEmsgSchemes(f.Segments)
+	if len(schemes) != 2 || schemes[id3] != 2 || schemes[scte35] != 1 {
+		t.Errorf("got schemes %v", schemes)
+	}
+
+	// emsg boxes are kept in segments when encoding
+	var outBuf bytes.Buffer
+	if err = f.Encode(&outBuf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(outBuf.Bytes(), encBytes) {
+		t.Errorf("segments with emsg not identical after round-trip")
+	}
+}
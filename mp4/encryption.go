@@ -0,0 +1,413 @@
+package mp4
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+)
+
+// EncryptionIssue - inconsistency in encryption metadata of a track
+type EncryptionIssue struct {
+	TrackID        uint32
+	SequenceNumber uint32 // Sequence number of fragment. 0 for issues in moov
+	Description    string
+}
+
+// String - issue as string
+func (e EncryptionIssue) String() string {
+	if e.SequenceNumber == 0 {
+		return fmt.Sprintf("track %d: %s", e.TrackID, e.Description)
+	}
+	return fmt.Sprintf("track %d fragment %d: %s", e.TrackID, e.SequenceNumber, e.Description)
+}
+
+// ValidateEncryption - check that the encryption metadata of a file is self-consistent.
+// Every encrypted sample entry must have a sinf box with frma, schm, and tenc, and the tenc KID
+// must be listed in a pssh box. The KID check is skipped if all pssh boxes are version 0 without KIDs.
+// For every fragment of an encrypted track, the senc sample count must match the trun sample count,
+// the saiz sizes must sum to the senc auxiliary data size, and the saio offset must point to that data.
+func ValidateEncryption(f *File) []EncryptionIssue {
+	var issues []EncryptionIssue
+	moov := f.Moov
+	if f.isFragmented && f.Init != nil {
+		moov = f.Init.Moov
+	}
+	if moov == nil {
+		return []EncryptionIssue{{Description: "no moov box"}}
+	}
+	var psshs []*PsshBox
+	psshs = append(psshs, moov.Psshs...)
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			psshs = append(psshs, frag.Moof.Psshs...)
+		}
+	}
+	encryptedTracks := make(map[uint32]bool)
+	for _, trak := range moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		stsd := trak.Mdia.Minf.Stbl.Stsd
+		if len(stsd.Children) == 0 {
+			continue
+		}
+		sampleEntryType := stsd.Children[0].Type()
+		if sampleEntryType != "encv" && sampleEntryType != "enca" {
+			continue
+		}
+		encryptedTracks[trackID] = true
+		addIssue := func(format string, args ...interface{}) {
+			issues = append(issues, EncryptionIssue{TrackID: trackID, Description: fmt.Sprintf(format, args...)})
+		}
+		sinf := moov.GetSinf(trackID)
+		if sinf == nil {
+			addIssue("%s sample entry without sinf", sampleEntryType)
+			continue
+		}
+		if sinf.Frma == nil {
+			addIssue("no frma in sinf")
+		}
+		if sinf.Schm == nil {
+			addIssue("no schm in sinf")
+		}
+		if sinf.Schi == nil || sinf.Schi.Tenc == nil {
+			addIssue("no tenc in sinf")
+			continue
+		}
+		kid := sinf.Schi.Tenc.DefaultKID
+		if !kidInPsshs(kid, psshs) {
+			addIssue("KID %s not found in any pssh", kid)
+		}
+	}
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			moof := frag.Moof
+			for _, traf := range moof.Trafs {
+				trackID := traf.Tfhd.TrackID
+				if !encryptedTracks[trackID] {
+					continue
+				}
+				issues = append(issues, validateTrafEncryption(traf, moof)...)
+			}
+		}
+	}
+	return issues
+}
+
+// kidInPsshs - is kid listed in a pssh box. True if there are version 0 psshs only, since they have no KIDs
+func kidInPsshs(kid UUID, psshs []*PsshBox) bool {
+	onlyVersion0 := len(psshs) > 0
+	for _, pssh := range psshs {
+		if pssh.Version == 0 {
+			continue
+		}
+		onlyVersion0 = false
+		for _, psshKID := range pssh.KIDs {
+			if bytes.Equal(psshKID, kid) {
+				return true
+			}
+		}
+	}
+	return onlyVersion0
+}
+
+// validateTrafEncryption - check senc, saiz, and saio consistency for an encrypted track fragment
+func validateTrafEncryption(traf *TrafBox, moof *MoofBox) []EncryptionIssue {
+	var issues []EncryptionIssue
+	addIssue := func(format string, args ...interface{}) {
+		issues = append(issues, EncryptionIssue{TrackID: traf.Tfhd.TrackID, SequenceNumber: moof.Mfhd.SequenceNumber,
+			Description: fmt.Sprintf(format, args...)})
+	}
+	senc := traf.Senc
+	if senc == nil {
+		addIssue("no senc box")
+		return issues
+	}
+	var nrSamples uint32
+	for _, trun := range traf.Truns {
+		nrSamples += trun.SampleCount()
+	}
+	if senc.SampleCount != nrSamples {
+		addIssue("senc sample count %d differs from trun sample count %d", senc.SampleCount, nrSamples)
+	}
+	auxDataStart := senc.StartPos + 16 // After header, version and flags, and sample count
+	auxDataSize := senc.Size() - 16
+	if traf.Saiz == nil {
+		addIssue("no saiz box")
+	} else {
+		saiz := traf.Saiz
+		if saiz.SampleCount != senc.SampleCount {
+			addIssue("saiz sample count %d differs from senc sample count %d", saiz.SampleCount, senc.SampleCount)
+		}
+		var totSize uint64
+		if saiz.DefaultSampleInfoSize != 0 {
+			totSize = uint64(saiz.DefaultSampleInfoSize) * uint64(saiz.SampleCount)
+		} else {
+			for _, size := range saiz.SampleInfo {
+				totSize += uint64(size)
+			}
+		}
+		if totSize != auxDataSize {
+			addIssue("saiz sizes sum to %d but senc data size is %d", totSize, auxDataSize)
+		}
+	}
+	if traf.Saio == nil {
+		addIssue("no saio box")
+	} else if len(traf.Saio.Offset) != 1 {
+		addIssue("saio has %d offsets instead of 1", len(traf.Saio.Offset))
+	} else {
+		baseOffset := moof.StartPos
+		if traf.Tfhd.HasBaseDataOffset() {
+			baseOffset = traf.Tfhd.BaseDataOffset
+		}
+		offset := uint64(int64(baseOffset) + traf.Saio.Offset[0])
+		if offset != auxDataStart {
+			addIssue("saio points to %d but senc data starts at %d", offset, auxDataStart)
+		}
+	}
+	return issues
+}
+
+// encTrackInfo - encryption information for a track during scheme conversion
+type encTrackInfo struct {
+	sinf       *SinfBox
+	trex       *TrexBox
+	srcScheme  string
+	srcTenc    TencBox // copy of tenc before conversion
+	isVideo    bool
+	nextIV     uint64 // next 8-byte per-sample IV for cenc
+	constantIV []byte // constant IV for cbcs
+}
+
+// ConvertEncryptionScheme - re-encrypt all encrypted tracks of a fragmented file with target scheme "cenc" or "cbcs".
+// The samples are decrypted with key using the source scheme and encrypted with the target scheme.
+// cenc uses random 8-byte per-sample IVs, and cbcs uses a random 16-byte constant IV with the
+// crypt/skip pattern 1:9 for video and full-sample encryption for audio. Subsample patterns are kept.
+// Tracks already encrypted with the target scheme are left unchanged.
+// tenc, schm, senc, saiz, saio, and trun data offsets are updated, but sidx boxes are not.
+// The mdat data must be in memory (not lazily decoded), and base offsets must be relative to moof.
+func ConvertEncryptionScheme(f *File, key []byte, target string) error {
+	if target != "cenc" && target != "cbcs" {
+		return fmt.Errorf("target scheme %q not supported", target)
+	}
+	if !f.isFragmented || f.Init == nil {
+		return fmt.Errorf("only fragmented files supported")
+	}
+	moov := f.Init.Moov
+	tracks := make(map[uint32]*encTrackInfo)
+	for _, trak := range moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		sinf := moov.GetSinf(trackID)
+		if sinf == nil {
+			continue
+		}
+		if sinf.Schm == nil || sinf.Schi == nil || sinf.Schi.Tenc == nil {
+			return fmt.Errorf("track %d: incomplete sinf", trackID)
+		}
+		srcScheme := sinf.Schm.SchemeType
+		if srcScheme != "cenc" && srcScheme != "cbcs" {
+			return fmt.Errorf("track %d: scheme %s not supported", trackID, srcScheme)
+		}
+		if srcScheme == target {
+			continue
+		}
+		ti := &encTrackInfo{
+			sinf:      sinf,
+			trex:      f.getTrex(trackID),
+			srcScheme: srcScheme,
+			srcTenc:   *sinf.Schi.Tenc,
+			isVideo:   trak.Mdia.Minf.Stbl.Stsd.Children[0].Type() == "encv",
+		}
+		ivStart := make([]byte, 8)
+		if _, err := rand.Read(ivStart); err != nil {
+			return err
+		}
+		ti.nextIV = binary.BigEndian.Uint64(ivStart)
+		ti.constantIV = make([]byte, 16)
+		if _, err := rand.Read(ti.constantIV); err != nil {
+			return err
+		}
+		tracks[trackID] = ti
+	}
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			err := convertFragmentEncryption(frag, tracks, key, target)
+			if err != nil {
+				return fmt.Errorf("fragment %d: %w", frag.Moof.Mfhd.SequenceNumber, err)
+			}
+		}
+	}
+	for _, ti := range tracks {
+		ti.sinf.Schm.SchemeType = target
+		tenc := ti.sinf.Schi.Tenc
+		switch target {
+		case "cenc":
+			tenc.Version = 0
+			tenc.DefaultCryptByteBlock, tenc.DefaultSkipByteBlock = 0, 0
+			tenc.DefaultPerSampleIVSize = 8
+			tenc.DefaultConstantIV = nil
+		case "cbcs":
+			tenc.Version = 1
+			tenc.DefaultCryptByteBlock, tenc.DefaultSkipByteBlock = 0, 0
+			if ti.isVideo {
+				tenc.DefaultCryptByteBlock, tenc.DefaultSkipByteBlock = 1, 9
+			}
+			tenc.DefaultPerSampleIVSize = 0
+			tenc.DefaultConstantIV = ti.constantIV
+		}
+	}
+	return nil
+}
+
+// convertFragmentEncryption - re-encrypt samples of encrypted tracks in fragment and update offsets
+func convertFragmentEncryption(frag *Fragment, tracks map[uint32]*encTrackInfo, key []byte, target string) error {
+	moof := frag.Moof
+	oldMoofSize := moof.Size()
+	for _, traf := range moof.Trafs {
+		ti, ok := tracks[traf.Tfhd.TrackID]
+		if !ok {
+			continue
+		}
+		if traf.Tfhd.HasBaseDataOffset() {
+			return fmt.Errorf("track %d: base data offset not supported", traf.Tfhd.TrackID)
+		}
+		if findSgpd(traf.Children, "seig") != nil {
+			return fmt.Errorf("track %d: seig sample group not supported", traf.Tfhd.TrackID)
+		}
+		hasSenc, isParsed := traf.ContainsSencBox()
+		if !hasSenc {
+			return fmt.Errorf("track %d: no senc box", traf.Tfhd.TrackID)
+		}
+		if !isParsed {
+			err := traf.ParseReadSenc(ti.srcTenc.DefaultPerSampleIVSize, moof.StartPos)
+			if err != nil {
+				return err
+			}
+		}
+		samples, err := frag.GetFullSamples(ti.trex)
+		if err != nil {
+			return err
+		}
+		oldSenc := traf.Senc
+		if int(oldSenc.SampleCount) != len(samples) {
+			return fmt.Errorf("track %d: senc has %d samples, but trun has %d",
+				traf.Tfhd.TrackID, oldSenc.SampleCount, len(samples))
+		}
+		newSenc := CreateSencBox()
+		for i := range samples {
+			var subSamples []SubSamplePattern
+			if len(oldSenc.SubSamples) != 0 {
+				subSamples = oldSenc.SubSamples[i]
+			}
+			srcIV := ti.srcTenc.DefaultConstantIV
+			if len(oldSenc.IVs) == len(samples) {
+				srcIV = oldSenc.IVs[i]
+			}
+			if len(srcIV) == 8 {
+				srcIV = append(append([]byte{}, srcIV...), make([]byte, 8)...)
+			}
+			if len(srcIV) != 16 {
+				return fmt.Errorf("track %d: no IV for sample %d", traf.Tfhd.TrackID, i+1)
+			}
+			data := samples[i].Data
+			switch ti.srcScheme {
+			case "cenc":
+				err = DecryptSampleCenc(data, key, srcIV, subSamples)
+			case "cbcs":
+				err = DecryptSampleCbcs(data, key, srcIV, subSamples, &ti.srcTenc)
+			}
+			if err != nil {
+				return err
+			}
+			var sencIV InitializationVector
+			switch target {
+			case "cenc":
+				sencIV = make([]byte, 8)
+				binary.BigEndian.PutUint64(sencIV, ti.nextIV)
+				ti.nextIV++
+				err = EncryptSampleCenc(data, key, append(append([]byte{}, sencIV...), make([]byte, 8)...), subSamples)
+			case "cbcs":
+				tenc := TencBox{}
+				if ti.isVideo {
+					tenc.DefaultCryptByteBlock, tenc.DefaultSkipByteBlock = 1, 9
+				}
+				err = EncryptSampleCbcs(data, key, ti.constantIV, subSamples, &tenc)
+			}
+			if err != nil {
+				return err
+			}
+			err = newSenc.AddSample(SencSample{IV: sencIV, SubSamples: subSamples})
+			if err != nil {
+				return err
+			}
+		}
+		replaceChild(traf.Children, oldSenc, newSenc)
+		traf.Senc = newSenc
+		if traf.Saiz != nil {
+			setSaizFromSenc(traf.Saiz, newSenc)
+		}
+	}
+	sizeDiff := int32(moof.Size()) - int32(oldMoofSize)
+	for _, traf := range moof.Trafs {
+		for _, trun := range traf.Truns {
+			if trun.HasDataOffset() {
+				trun.DataOffset += sizeDiff
+			}
+		}
+		if _, ok := tracks[traf.Tfhd.TrackID]; ok && traf.Saio != nil && traf.Senc != nil {
+			traf.Saio.Offset = []int64{int64(sencAuxDataOffsetInMoof(moof, traf))}
+		}
+	}
+	return nil
+}
+
+// replaceChild - replace oldBox with newBox in children
+func replaceChild(children []Box, oldBox, newBox Box) {
+	for i, c := range children {
+		if c == oldBox {
+			children[i] = newBox
+			return
+		}
+	}
+}
+
+// setSaizFromSenc - set saiz sample count and sizes to match senc
+func setSaizFromSenc(saiz *SaizBox, senc *SencBox) {
+	sizes := make([]byte, senc.SampleCount)
+	allEqual := true
+	for i := range sizes {
+		size := senc.GetPerSampleIVSize()
+		if senc.Flags&UseSubSampleEncryption != 0 {
+			size += 2 + 6*len(senc.SubSamples[i])
+		}
+		sizes[i] = byte(size)
+		if sizes[i] != sizes[0] {
+			allEqual = false
+		}
+	}
+	saiz.SampleCount = senc.SampleCount
+	saiz.DefaultSampleInfoSize = 0
+	saiz.SampleInfo = sizes
+	if allEqual && len(sizes) > 0 && sizes[0] != 0 {
+		saiz.DefaultSampleInfoSize = sizes[0]
+		saiz.SampleInfo = nil
+	}
+}
+
+// sencAuxDataOffsetInMoof - offset from moof start to the auxiliary data in the senc box of traf
+func sencAuxDataOffsetInMoof(moof *MoofBox, traf *TrafBox) uint64 {
+	offset := uint64(boxHeaderSize)
+	for _, c := range moof.Children {
+		if c == traf {
+			break
+		}
+		offset += c.Size()
+	}
+	offset += boxHeaderSize
+	for _, c := range traf.Children {
+		if c == traf.Senc {
+			break
+		}
+		offset += c.Size()
+	}
+	return offset + 16 // senc header, version and flags, and sample count
+}
@@ -0,0 +1,187 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestValidateEncryption(t *testing.T) {
+	decode := func() *File {
+		fd, err := os.Open("testdata/prog_8s_enc_dashinit.mp4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fd.Close()
+		f, err := DecodeFile(fd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		f := decode()
+		issues := ValidateEncryption(f)
+		if len(issues) != 0 {
+			t.Errorf("got issues for valid file: %v", issues)
+		}
+	})
+
+	t.Run("broken", func(t *testing.T) {
+		f := decode()
+		f.Init.Moov.RemovePsshs()
+		frag := f.Segments[0].Fragments[0]
+		traf := frag.Moof.Trafs[0]
+		traf.Truns[0].AddSample(NewSample(NonSyncSampleFlags, 1024, 100, 0))
+		traf.Saiz.SampleCount--
+		traf.Saio.Offset[0] += 4
+		issues := ValidateEncryption(f)
+		expected := []string{
+			"not found in any pssh",
+			"senc sample count",
+			"saiz sample count",
+			"saiz sizes sum to",
+			"saio points to",
+		}
+		for _, exp := range expected {
+			found := false
+			for _, issue := range issues {
+				if strings.Contains(issue.Description, exp) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("no issue containing %q in %v", exp, issues)
+			}
+		}
+		for _, issue := range issues {
+			if issue.SequenceNumber != 0 && issue.SequenceNumber != frag.Moof.Mfhd.SequenceNumber {
+				t.Errorf("unexpected issue %s", issue)
+			}
+		}
+	})
+}
+
+func TestConvertEncryptionScheme(t *testing.T) {
+	key, _ := hex.DecodeString("63cb5f7184dd4b689a5c5ff11ee6a328")
+	decodeFile := func(path string) *File {
+		fd, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fd.Close()
+		f, err := DecodeFile(fd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	clearData := decryptedTrackData(t, decodeFile("testdata/prog_8s_dec_dashinit.mp4"), key)
+
+	if len(clearData) != 2 {
+		t.Fatalf("got %d clear tracks instead of 2", len(clearData))
+	}
+	f := decodeFile("testdata/prog_8s_enc_dashinit.mp4")
+	// Converting to the current scheme should leave the tracks unchanged
+	for i, target := range []string{"cenc", "cbcs", "cbcs", "cenc"} {
+		var oldTencs []TencBox
+		if i == 0 || i == 2 {
+			for _, trak := range f.Init.Moov.Traks {
+				oldTencs = append(oldTencs, *f.Init.Moov.GetSinf(trak.Tkhd.TrackID).Schi.Tenc)
+			}
+		}
+		err := ConvertEncryptionScheme(f, key, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, oldTenc := range oldTencs {
+			trak := f.Init.Moov.Traks[j]
+			if diff := deep.Equal(*f.Init.Moov.GetSinf(trak.Tkhd.TrackID).Schi.Tenc, oldTenc); diff != nil {
+				t.Errorf("%s to %s: track %d: tenc changed: %v", target, target, trak.Tkhd.TrackID, diff)
+			}
+		}
+		buf := bytes.Buffer{}
+		err = f.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err = DecodeFile(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, trak := range f.Init.Moov.Traks {
+			sinf := f.Init.Moov.GetSinf(trak.Tkhd.TrackID)
+			if sinf.Schm.SchemeType != target {
+				t.Errorf("track %d: got scheme %s instead of %s", trak.Tkhd.TrackID, sinf.Schm.SchemeType, target)
+			}
+		}
+		if issues := ValidateEncryption(f); len(issues) != 0 {
+			t.Errorf("%s: got issues %v", target, issues)
+		}
+		gotData := decryptedTrackData(t, f, key)
+		for trackID, data := range clearData {
+			if !bytes.Equal(gotData[trackID], data) {
+				t.Errorf("%s: track %d: decrypted data differs from plaintext", target, trackID)
+			}
+		}
+	}
+	err := ConvertEncryptionScheme(f, key, "cens")
+	if err == nil {
+		t.Errorf("expected error for unsupported target scheme")
+	}
+}
+
+// decryptedTrackData - concatenated decrypted sample data per track
+func decryptedTrackData(t *testing.T, f *File, key []byte) map[uint32][]byte {
+	t.Helper()
+	data := make(map[uint32][]byte)
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				trackID := traf.Tfhd.TrackID
+				samples, err := frag.GetFullSamples(f.getTrex(trackID))
+				if err != nil {
+					t.Fatal(err)
+				}
+				sinf := f.Init.Moov.GetSinf(trackID)
+				if _, isParsed := traf.ContainsSencBox(); sinf != nil && !isParsed {
+					tenc := sinf.Schi.Tenc
+					if err := traf.ParseReadSenc(tenc.DefaultPerSampleIVSize, frag.Moof.StartPos); err != nil {
+						t.Fatal(err)
+					}
+				}
+				for i, s := range samples {
+					sampleData := append([]byte{}, s.Data...)
+					if sinf != nil {
+						var subSamples []SubSamplePattern
+						if len(traf.Senc.SubSamples) != 0 {
+							subSamples = traf.Senc.SubSamples[i]
+						}
+						tenc := sinf.Schi.Tenc
+						switch sinf.Schm.SchemeType {
+						case "cenc":
+							iv := append([]byte{}, traf.Senc.IVs[i]...)
+							if len(iv) == 8 {
+								iv = append(iv, make([]byte, 8)...)
+							}
+							err = DecryptSampleCenc(sampleData, key, iv, subSamples)
+						case "cbcs":
+							err = DecryptSampleCbcs(sampleData, key, tenc.DefaultConstantIV, subSamples, tenc)
+						}
+						if err != nil {
+							t.Fatal(err)
+						}
+					}
+					data[trackID] = append(data[trackID], sampleData...)
+				}
+			}
+		}
+	}
+	return data
+}
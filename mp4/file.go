@@ -3,10 +3,14 @@ package mp4
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/edgeware/mp4ff/avc"
 	"github.com/edgeware/mp4ff/bits"
+	"github.com/edgeware/mp4ff/hevc"
 )
 
 // File - an MPEG-4 file asset
@@ -25,17 +29,25 @@ import (
 // To Encode the same data as Decoded, this flag must therefore be set.
 // In all cases, Children contain all top-level boxes
 type File struct {
-	Ftyp         *FtypBox
-	Moov         *MoovBox
-	Mdat         *MdatBox        // Only used for non-fragmented files
-	Init         *InitSegment    // Init data (ftyp + moov for fragmented file)
-	Sidx         *SidxBox        // SidxBox for a DASH OnDemand file
-	Segments     []*MediaSegment // Media segments
-	Children     []Box           // All top-level boxes in order
-	FragEncMode  EncFragFileMode // Determine how fragmented files are encoded
-	EncOptimize  EncOptimize     // Bit field with optimizations being done at encoding
-	isFragmented bool
-	fileDecMode  DecFileMode
+	Ftyp                 *FtypBox
+	Ainf                 *AinfBox // Asset information, part of Init for fragmented files
+	Moov                 *MoovBox
+	Mdat                 *MdatBox        // Only used for non-fragmented files
+	Init                 *InitSegment    // Init data (ftyp + moov for fragmented file)
+	Sidx                 *SidxBox        // SidxBox for a DASH OnDemand file
+	Segments             []*MediaSegment // Media segments
+	Mfra                 *MfraBox        // Movie fragment random access box at end of fragmented file
+	Children             []Box           // All top-level boxes in order
+	FragEncMode          EncFragFileMode // Determine how fragmented files are encoded
+	EncOptimize          EncOptimize     // Bit field with optimizations being done at encoding
+	AssumeContinuousTime bool            // Synthesize missing tfdt boxes from fragment durations when decoding
+	LenientMdatOrder     bool            // Accept mdat before its moof in fragmented files when decoding
+	Warnings             []string        // Non-fatal problems found when decoding
+	continuousStartTime  uint64
+	earlyMdat            *MdatBox // mdat waiting for the next moof when decoding with LenientMdatOrder
+	isFragmented         bool
+	fileDecMode          DecFileMode
+	pendingEmsgs         []*EmsgBox // emsg boxes to be added to the fragment of the next moof
 }
 
 // EncFragFileMode - mode for writing file
@@ -168,6 +180,14 @@ LoopBoxes:
 		switch boxType {
 		case "mdat":
 			if f.isFragmented {
+				if f.isEarlyMdat(lastBoxType) {
+					if err := f.keepEarlyMdat(box.(*MdatBox), boxStartPos); err != nil {
+						return nil, err
+					}
+					lastBoxType = boxType
+					boxStartPos += boxSize
+					continue LoopBoxes
+				}
 				if lastBoxType != "moof" {
 					return nil, fmt.Errorf("Does not support %v between moof and mdat", lastBoxType)
 				}
@@ -192,12 +212,53 @@ LoopBoxes:
 			}
 		}
 		f.AddChild(box, boxStartPos)
+		if boxType == "moof" {
+			f.attachEarlyMdat()
+		}
 		lastBoxType = boxType
 		boxStartPos += boxSize
 	}
+	f.dropEarlyMdat()
+	if f.AssumeContinuousTime {
+		err := f.synthesizeMissingTfdts()
+		if err != nil {
+			return nil, err
+		}
+	}
 	return f, nil
 }
 
+// ParseUntilMdat - decode top-level boxes until the first mdat box and return its start position and size.
+// The mdat header is read from r, but not the payload, so the rest of the file can be fetched separately.
+func ParseUntilMdat(r io.Reader) (f *File, mdatStart, mdatSize uint64, err error) {
+	f = NewFile()
+	var boxStartPos uint64 = 0
+	for {
+		hdr, err := DecodeHeader(r)
+		if err == io.EOF {
+			return nil, 0, 0, fmt.Errorf("no mdat box found")
+		}
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if hdr.Name == "mdat" {
+			return f, boxStartPos, hdr.Size, nil
+		}
+		var box Box
+		d, ok := decoders[hdr.Name]
+		if !ok {
+			box, err = DecodeUnknown(hdr, boxStartPos, r)
+		} else {
+			box, err = d(hdr, boxStartPos, r)
+		}
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("decode %s: %w", hdr.Name, err)
+		}
+		f.AddChild(box, boxStartPos)
+		boxStartPos += box.Size()
+	}
+}
+
 // Size - total size of all boxes
 func (f *File) Size() uint64 {
 	var totSize uint64 = 0
@@ -212,12 +273,22 @@ func (f *File) AddChild(box Box, boxStartPos uint64) {
 	switch box.Type() {
 	case "ftyp":
 		f.Ftyp = box.(*FtypBox)
+	case "ainf":
+		f.Ainf = box.(*AinfBox)
+		if f.Init != nil && len(f.Segments) == 0 {
+			f.Init.AddChild(f.Ainf)
+		}
 	case "moov":
 		f.Moov = box.(*MoovBox)
 		if len(f.Moov.Trak.Mdia.Minf.Stbl.Stts.SampleCount) == 0 {
 			f.isFragmented = true
 			f.Init = NewMP4Init()
-			f.Init.AddChild(f.Ftyp)
+			if f.Ftyp != nil {
+				f.Init.AddChild(f.Ftyp)
+			}
+			if f.Ainf != nil {
+				f.Init.AddChild(f.Ainf)
+			}
 			f.Init.AddChild(f.Moov)
 		}
 	case "sidx":
@@ -231,6 +302,7 @@ func (f *File) AddChild(box Box, boxStartPos uint64) {
 		f.isFragmented = true
 		newSeg := NewMediaSegment()
 		newSeg.Styp = box.(*StypBox)
+		newSeg.StartPos = boxStartPos
 		f.AddMediaSegment(newSeg)
 	case "moof":
 		f.isFragmented = true
@@ -241,14 +313,31 @@ func (f *File) AddChild(box Box, boxStartPos uint64) {
 
 		if len(f.Segments) == 0 || f.Segments[0].Styp == nil {
 			// No styp present, so one fragment per segment
-			currentSegment = NewMediaSegment()
+			if f.Moov == nil {
+				// Headerless stream. Do not add styp, so that it is preserved when encoded
+				currentSegment = NewMediaSegmentWithoutStyp()
+			} else {
+				currentSegment = NewMediaSegment()
+			}
+			currentSegment.StartPos = boxStartPos
+			for _, emsg := range f.pendingEmsgs {
+				currentSegment.StartPos -= emsg.Size()
+			}
 			f.AddMediaSegment(currentSegment)
 		} else {
 			currentSegment = f.LastSegment()
 		}
 		newFragment := NewFragment()
 		currentSegment.AddFragment(newFragment)
+		for _, emsg := range f.pendingEmsgs {
+			newFragment.AddChild(emsg)
+		}
+		f.pendingEmsgs = nil
 		newFragment.AddChild(moof)
+	case "emsg":
+		f.pendingEmsgs = append(f.pendingEmsgs, box.(*EmsgBox))
+	case "mfra":
+		f.Mfra = box.(*MfraBox)
 	case "mdat":
 		mdat := box.(*MdatBox)
 		if !f.isFragmented {
@@ -306,26 +395,37 @@ func (f *File) Encode(w io.Writer) error {
 	if f.isFragmented {
 		switch f.FragEncMode {
 		case EncModeSegment:
+			var pos uint64
 			if f.Init != nil {
 				err := f.Init.Encode(w)
 				if err != nil {
 					return err
 				}
+				pos += f.Init.Size()
 			}
 			if f.Sidx != nil {
 				err := f.Sidx.Encode(w)
 				if err != nil {
 					return err
 				}
+				pos += f.Sidx.Size()
 			}
 			for _, seg := range f.Segments {
 				if f.EncOptimize&OptimizeTrun != 0 {
 					seg.EncOptimize = f.EncOptimize
 				}
+				seg.StartPos = pos
 				err := seg.Encode(w)
 				if err != nil {
 					return err
 				}
+				pos += seg.Size()
+			}
+			if f.Mfra != nil {
+				err := f.Mfra.Encode(w)
+				if err != nil {
+					return err
+				}
 			}
 		case EncModeBoxTree:
 			for _, b := range f.Children {
@@ -355,26 +455,37 @@ func (f *File) EncodeSW(sw bits.SliceWriter) error {
 	if f.isFragmented {
 		switch f.FragEncMode {
 		case EncModeSegment:
+			var pos uint64
 			if f.Init != nil {
 				err := f.Init.EncodeSW(sw)
 				if err != nil {
 					return err
 				}
+				pos += f.Init.Size()
 			}
 			if f.Sidx != nil {
 				err := f.Sidx.EncodeSW(sw)
 				if err != nil {
 					return err
 				}
+				pos += f.Sidx.Size()
 			}
 			for _, seg := range f.Segments {
 				if f.EncOptimize&OptimizeTrun != 0 {
 					seg.EncOptimize = f.EncOptimize
 				}
+				seg.StartPos = pos
 				err := seg.EncodeSW(sw)
 				if err != nil {
 					return err
 				}
+				pos += seg.Size()
+			}
+			if f.Mfra != nil {
+				err := f.Mfra.EncodeSW(sw)
+				if err != nil {
+					return err
+				}
 			}
 		case EncModeBoxTree:
 			for _, b := range f.Children {
@@ -414,6 +525,11 @@ func (f *File) LastSegment() *MediaSegment {
 	return f.Segments[len(f.Segments)-1]
 }
 
+// HasFtyp - is there an ftyp box. Live streams may lack it and start directly with styp or moof
+func (f *File) HasFtyp() bool {
+	return f.Ftyp != nil
+}
+
 // IsFragmented - is file made of multiple segments (Mp4 fragments)
 func (f *File) IsFragmented() bool {
 	return f.isFragmented
@@ -440,6 +556,595 @@ func WithDecodeMode(mode DecFileMode) Option {
 	return func(f *File) { f.fileDecMode = mode }
 }
 
+// WithAssumeContinuousTime - synthesize tfdt boxes for fragments lacking them.
+// The times are chained from startTime by accumulating the fragment durations of each track.
+func WithAssumeContinuousTime(startTime uint64) Option {
+	return func(f *File) {
+		f.AssumeContinuousTime = true
+		f.continuousStartTime = startTime
+	}
+}
+
+// WithLenientMdatOrder - accept fragmented files where an mdat comes before its moof instead of after it.
+// Such an mdat is kept until the next moof and then associated with it, and a warning is added to Warnings.
+// The fragment is written in the conforming moof-mdat order when the file is encoded in EncModeSegment.
+func WithLenientMdatOrder() Option {
+	return func(f *File) { f.LenientMdatOrder = true }
+}
+
+// isEarlyMdat - true if an mdat after a box of lastBoxType should be kept for the next moof.
+// This is the case with LenientMdatOrder if the mdat does not follow a moof, or if the fragment of the
+// previous moof already got an mdat that came before it.
+func (f *File) isEarlyMdat(lastBoxType string) bool {
+	if !f.LenientMdatOrder {
+		return false
+	}
+	return lastBoxType != "moof" || f.LastSegment().LastFragment().Mdat != nil
+}
+
+// keepEarlyMdat - keep mdat at boxStartPos until the next moof and add it to Children
+func (f *File) keepEarlyMdat(mdat *MdatBox, boxStartPos uint64) error {
+	if f.earlyMdat != nil {
+		return fmt.Errorf("two mdat boxes without moof at offset %d", boxStartPos)
+	}
+	f.earlyMdat = mdat
+	f.Warnings = append(f.Warnings, fmt.Sprintf("mdat at offset %d before its moof", boxStartPos))
+	f.Children = append(f.Children, mdat)
+	return nil
+}
+
+// attachEarlyMdat - add a kept mdat to the fragment of the latest moof
+func (f *File) attachEarlyMdat() {
+	if f.earlyMdat == nil {
+		return
+	}
+	frag := f.LastSegment().LastFragment()
+	frag.AddChild(f.earlyMdat)
+	frag.moveTrunDataOffsetsToEarlyMdat()
+	f.earlyMdat = nil
+}
+
+// dropEarlyMdat - warn about a kept mdat at the end of the file, which does not belong to any moof
+func (f *File) dropEarlyMdat() {
+	if f.earlyMdat != nil {
+		f.Warnings = append(f.Warnings, "mdat at end of file without following moof")
+		f.earlyMdat = nil
+	}
+}
+
+// synthesizeMissingTfdts - add tfdt boxes to all tracks of a fragmented file
+func (f *File) synthesizeMissingTfdts() error {
+	if !f.isFragmented {
+		return nil
+	}
+	for _, trackID := range f.fragmentTrackIDs() {
+		err := f.ComputeTfdtFromDurations(trackID, f.continuousStartTime)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fragmentTrackIDs - trackIDs in order of first appearance in the fragments
+func (f *File) fragmentTrackIDs() []uint32 {
+	var trackIDs []uint32
+	found := make(map[uint32]bool)
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				trackID := traf.Tfhd.TrackID
+				if !found[trackID] {
+					found[trackID] = true
+					trackIDs = append(trackIDs, trackID)
+				}
+			}
+		}
+	}
+	return trackIDs
+}
+
+// getTrex - get trex for trackID from init segment, or nil if not available
+func (f *File) getTrex(trackID uint32) *TrexBox {
+	if f.Init == nil || f.Init.Moov == nil || f.Init.Moov.Mvex == nil {
+		return nil
+	}
+	for _, trex := range f.Init.Moov.Mvex.Trexs {
+		if trex.TrackID == trackID {
+			return trex
+		}
+	}
+	return nil
+}
+
+// ComputeTfdtFromDurations - synthesize tfdt boxes for trackID in fragments lacking them.
+// The first fragment starts at startTime and each following fragment starts where the previous ended.
+// An existing tfdt box resets the accumulated time to its value.
+// The data offsets are moved for the larger moof, so that the samples can still be read and encoded.
+func (f *File) ComputeTfdtFromDurations(trackID uint32, startTime uint64) error {
+	if !f.isFragmented {
+		return fmt.Errorf("only available for fragmented files")
+	}
+	trex := f.getTrex(trackID)
+	nextTime := startTime
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID != trackID {
+					continue
+				}
+				if traf.Tfdt != nil {
+					nextTime = traf.Tfdt.BaseMediaDecodeTime
+				} else {
+					oldMoofSize := frag.Moof.Size()
+					traf.insertTfdt(CreateTfdt(nextTime))
+					frag.growMoof(int32(frag.Moof.Size() - oldMoofSize))
+				}
+				for _, trun := range traf.Truns {
+					nextTime += trun.AddSampleDefaultValues(traf.Tfhd, trex)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Overhead - number of bytes of media data (mdat payloads) and of container data (all other bytes)
+// in the file. For fragmented files, the moof boxes and other segment boxes are counted as container data.
+func (f *File) Overhead() (mediaBytes, containerBytes uint64, err error) {
+	if len(f.Children) == 0 {
+		return 0, 0, fmt.Errorf("no boxes in file")
+	}
+	for _, c := range f.Children {
+		size := c.Size()
+		if mdat, ok := c.(*MdatBox); ok {
+			hdrSize := mdat.HeaderSize()
+			mediaBytes += size - hdrSize
+			containerBytes += hdrSize
+			continue
+		}
+		containerBytes += size
+	}
+	return mediaBytes, containerBytes, nil
+}
+
+// FragmentKeyframeFlags - for every fragment with trackID, whether its first sample is a sync sample.
+// The flags of the first sample are given by trun first_sample_flags, trun sample flags,
+// tfhd default_sample_flags, or trex default_sample_flags in that order of precedence.
+// A sample is sync if its sample_is_non_sync_sample flag is not set.
+func (f *File) FragmentKeyframeFlags(trackID uint32) ([]bool, error) {
+	if !f.isFragmented {
+		return nil, fmt.Errorf("only available for fragmented files")
+	}
+	trex := f.getTrex(trackID)
+	var keyframeFlags []bool
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID != trackID {
+					continue
+				}
+				var firstTrun *TrunBox
+				for _, trun := range traf.Truns {
+					if trun.SampleCount() > 0 {
+						firstTrun = trun
+						break
+					}
+				}
+				if firstTrun == nil {
+					return nil, fmt.Errorf("fragment %d: no samples for track %d", frag.Moof.Mfhd.SequenceNumber, trackID)
+				}
+				var flags uint32
+				firstSampleFlags, hasFirstSampleFlags := firstTrun.FirstSampleFlags()
+				switch {
+				case hasFirstSampleFlags:
+					flags = firstSampleFlags
+				case firstTrun.HasSampleFlags():
+					flags = firstTrun.Samples[0].Flags
+				case traf.Tfhd.HasDefaultSampleFlags():
+					flags = traf.Tfhd.DefaultSampleFlags
+				case trex != nil:
+					flags = trex.DefaultSampleFlags
+				default:
+					return nil, fmt.Errorf("fragment %d: no sample flags for track %d", frag.Moof.Mfhd.SequenceNumber, trackID)
+				}
+				keyframeFlags = append(keyframeFlags, !DecodeSampleFlags(flags).SampleIsNonSync)
+			}
+		}
+	}
+	return keyframeFlags, nil
+}
+
+// SanitizeHandlerNames - replace the name of every hdlr box in the file with replacement.
+// This removes tool names such as "VideoHandler" or encoder fingerprints.
+// For progressive files, the chunk offsets are updated for the new size of moov.
+func (f *File) SanitizeHandlerNames(replacement string) error {
+	oldMdatPositions := f.mdatPositions()
+	for _, box := range f.Children {
+		setHandlerNames(box, replacement)
+	}
+	return f.moveAllChunkOffsets(oldMdatPositions)
+}
+
+// setHandlerNames - set name of all hdlr boxes in the box tree starting at b
+func setHandlerNames(b Box, name string) {
+	switch box := b.(type) {
+	case *HdlrBox:
+		box.Name = name
+	case ContainerBox:
+		for _, child := range box.GetChildren() {
+			setHandlerNames(child, name)
+		}
+	}
+}
+
+// FixVisualDimensions - set width and height of the avc1/avc3 or hvc1/hev1 sample entry of trackID to the
+// cropped dimensions given by the first SPS. changed is true if the sample entry had other dimensions.
+func (f *File) FixVisualDimensions(trackID uint32) (changed bool, err error) {
+	trak := f.getTrak(trackID)
+	if trak == nil {
+		return false, fmt.Errorf("no track with trackID %d", trackID)
+	}
+	stsd := trak.Mdia.Minf.Stbl.Stsd
+	var sampleEntry *VisualSampleEntryBox
+	var width, height uint32
+	switch {
+	case stsd.AvcX != nil:
+		sampleEntry = stsd.AvcX
+		if sampleEntry.AvcC == nil || len(sampleEntry.AvcC.SPSnalus) == 0 {
+			return false, fmt.Errorf("no SPS in avcC")
+		}
+		sps, err := avc.ParseSPSNALUnit(sampleEntry.AvcC.SPSnalus[0], false)
+		if err != nil {
+			return false, err
+		}
+		width, height = uint32(sps.Width), uint32(sps.Height)
+	case stsd.HvcX != nil:
+		sampleEntry = stsd.HvcX
+		if sampleEntry.HvcC == nil {
+			return false, fmt.Errorf("no hvcC")
+		}
+		spss := sampleEntry.HvcC.GetNalusForType(hevc.NALU_SPS)
+		if len(spss) == 0 {
+			return false, fmt.Errorf("no SPS in hvcC")
+		}
+		sps, err := hevc.ParseSPSNALUnit(spss[0])
+		if err != nil {
+			return false, err
+		}
+		width, height = sps.ImageSize()
+	default:
+		return false, fmt.Errorf("track %d is not an AVC or HEVC track", trackID)
+	}
+	if uint32(sampleEntry.Width) == width && uint32(sampleEntry.Height) == height {
+		return false, nil
+	}
+	sampleEntry.Width, sampleEntry.Height = uint16(width), uint16(height)
+	return true, nil
+}
+
+// ZeroBaseTime - make the first decode time of trackID in a fragmented file zero by subtracting
+// the first tfdt value from all tfdt boxes of the track. The media time of existing non-empty edits is
+// reduced by the same offset, but not below zero. If there are other tracks, an empty edit
+// of the same duration is inserted first in the edit list to keep the sync. The subtracted offset is returned.
+func (f *File) ZeroBaseTime(trackID uint32) (offset uint64, err error) {
+	if !f.isFragmented || f.Init == nil {
+		return 0, fmt.Errorf("only available for fragmented files")
+	}
+	trak := f.getTrak(trackID)
+	if trak == nil {
+		return 0, fmt.Errorf("no track with trackID %d", trackID)
+	}
+	traf := f.firstTraf(trackID)
+	if traf == nil || traf.Tfdt == nil {
+		return 0, fmt.Errorf("no tfdt for track %d", trackID)
+	}
+	offset = traf.Tfdt.BaseMediaDecodeTime
+	if offset == 0 {
+		return 0, nil
+	}
+	var tfdts []*TfdtBox
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID != trackID {
+					continue
+				}
+				if traf.Tfdt == nil || traf.Tfdt.BaseMediaDecodeTime < offset {
+					return 0, fmt.Errorf("fragment %d: missing or too small tfdt", frag.Moof.Mfhd.SequenceNumber)
+				}
+				tfdts = append(tfdts, traf.Tfdt)
+			}
+		}
+	}
+	for _, tfdt := range tfdts {
+		tfdt.BaseMediaDecodeTime -= offset
+	}
+	if trak.Edts != nil {
+		for _, elst := range trak.Edts.Elst {
+			for i := range elst.Entries {
+				e := &elst.Entries[i]
+				if e.MediaTime < 0 {
+					continue // Empty edit
+				}
+				if uint64(e.MediaTime) > offset {
+					e.MediaTime -= int64(offset)
+				} else {
+					e.MediaTime = 0
+				}
+			}
+		}
+	}
+	moov := f.Init.Moov
+	if len(moov.Traks) > 1 {
+		emptyDur := offset * uint64(moov.Mvhd.Timescale) / uint64(trak.Mdia.Mdhd.Timescale)
+		trak.addEmptyEdit(emptyDur)
+	}
+	return offset, nil
+}
+
+// DashSegmentBaseRanges - byte ranges for DASH SegmentBase indexRange and Initialization range.
+// The index range covers the first sidx box and a directly following ssix box.
+// The init range covers everything from file start to the end of the moov box.
+// All ranges are inclusive as in DASH byte range syntax.
+func (f *File) DashSegmentBaseRanges() (indexRangeStart, indexRangeEnd, initRangeStart, initRangeEnd uint64, err error) {
+	var pos uint64
+	foundMoov, foundSidx := false, false
+	for _, box := range f.Children {
+		size := box.Size()
+		switch box.Type() {
+		case "moov":
+			initRangeEnd = pos + size - 1
+			foundMoov = true
+		case "sidx":
+			if !foundSidx {
+				indexRangeStart = pos
+				indexRangeEnd = pos + size - 1
+				foundSidx = true
+			}
+		case "ssix":
+			if foundSidx && indexRangeEnd+1 == pos {
+				indexRangeEnd = pos + size - 1
+			}
+		}
+		pos += size
+	}
+	if !foundMoov {
+		return 0, 0, 0, 0, fmt.Errorf("no moov box found")
+	}
+	if !foundSidx {
+		return 0, 0, 0, 0, fmt.Errorf("no sidx box found")
+	}
+	return indexRangeStart, indexRangeEnd, initRangeStart, initRangeEnd, nil
+}
+
+// fullSamplesForTrack - get all samples including data for trackID.
+// For progressive files, rs is used if mdat is lazily decoded.
+// Fragmented files must have their mdat data in memory.
+func (f *File) fullSamplesForTrack(trackID uint32, rs io.ReadSeeker) ([]FullSample, error) {
+	if !f.isFragmented {
+		if f.Moov == nil || f.Mdat == nil {
+			return nil, fmt.Errorf("no moov or mdat box")
+		}
+		trak := f.Moov.GetTrak(trackID)
+		if trak == nil {
+			return nil, fmt.Errorf("no track with trackID %d", trackID)
+		}
+		nrSamples := trak.GetNrSamples()
+		if nrSamples == 0 {
+			return nil, nil
+		}
+		samples, err := trak.GetSampleData(1, nrSamples)
+		if err != nil {
+			return nil, err
+		}
+		stbl := trak.Mdia.Minf.Stbl
+		allSync := stbl.Stss == nil && stbl.Sdtp == nil
+		fullSamples := make([]FullSample, 0, nrSamples)
+		var decodeTime uint64
+		for i, s := range samples {
+			nr := uint32(i + 1)
+			if allSync {
+				s.Flags = SyncSampleFlags
+			}
+			ranges, err := trak.GetRangesForSampleInterval(nr, nr)
+			if err != nil {
+				return nil, err
+			}
+			data, err := f.Mdat.ReadData(int64(ranges[0].Offset), int64(ranges[0].Size), rs)
+			if err != nil {
+				return nil, fmt.Errorf("sample %d: %w", nr, err)
+			}
+			fullSamples = append(fullSamples, FullSample{Sample: s, DecodeTime: decodeTime, Data: data})
+			decodeTime += uint64(s.Dur)
+		}
+		return fullSamples, nil
+	}
+	trex := f.getTrex(trackID)
+	if trex == nil {
+		return nil, fmt.Errorf("no trex for trackID %d", trackID)
+	}
+	var fullSamples []FullSample
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			if frag.Mdat.IsLazy() {
+				return nil, fmt.Errorf("lazy mdat not supported for fragmented files")
+			}
+			fss, err := frag.GetFullSamples(trex)
+			if err != nil {
+				return nil, err
+			}
+			fullSamples = append(fullSamples, fss...)
+		}
+	}
+	return fullSamples, nil
+}
+
+// DumpSamples - write the data of each sample of trackID to a separate file in dir.
+// File names are sample_<nr>_<sync|nonsync>.bin with one-based sample numbers.
+// If asAnnexB is set, length-prefixed NAL units in AVC/HEVC tracks are converted to Annex B byte streams.
+func (f *File) DumpSamples(rs io.ReadSeeker, trackID uint32, dir string, asAnnexB bool) error {
+	if asAnnexB {
+		trak := f.getTrak(trackID)
+		if trak == nil {
+			return fmt.Errorf("no track with trackID %d", trackID)
+		}
+		stsd := trak.Mdia.Minf.Stbl.Stsd
+		if stsd.AvcX == nil && stsd.HvcX == nil {
+			return fmt.Errorf("annex B output only supported for AVC and HEVC tracks")
+		}
+	}
+	samples, err := f.fullSamplesForTrack(trackID, rs)
+	if err != nil {
+		return err
+	}
+	for i := range samples {
+		s := samples[i]
+		syncStr := "nonsync"
+		if s.IsSync() {
+			syncStr = "sync"
+		}
+		data := s.Data
+		if asAnnexB && len(data) > 4 {
+			data = make([]byte, len(s.Data))
+			copy(data, s.Data)
+			toAnnexB(data)
+		}
+		fileName := filepath.Join(dir, fmt.Sprintf("sample_%06d_%s.bin", i+1, syncStr))
+		err = ioutil.WriteFile(fileName, data, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteAnnexB - write all samples of an AVC or HEVC track to w as an Annex B byte stream.
+// Every NAL unit is preceded by a 4-byte start code. If insertParamSets is set,
+// the parameter sets from the sample description are written before every sync sample.
+func (f *File) WriteAnnexB(w io.Writer, trackID uint32, rs io.ReadSeeker, insertParamSets bool) error {
+	trak := f.getTrak(trackID)
+	if trak == nil {
+		return fmt.Errorf("no track with trackID %d", trackID)
+	}
+	stsd := trak.Mdia.Minf.Stbl.Stsd
+	var paramSets [][]byte
+	switch {
+	case stsd.AvcX != nil && stsd.AvcX.AvcC != nil:
+		paramSets = append(paramSets, stsd.AvcX.AvcC.SPSnalus...)
+		paramSets = append(paramSets, stsd.AvcX.AvcC.PPSnalus...)
+	case stsd.HvcX != nil && stsd.HvcX.HvcC != nil:
+		hvcC := stsd.HvcX.HvcC
+		paramSets = append(paramSets, hvcC.GetNalusForType(hevc.NALU_VPS)...)
+		paramSets = append(paramSets, hvcC.GetNalusForType(hevc.NALU_SPS)...)
+		paramSets = append(paramSets, hvcC.GetNalusForType(hevc.NALU_PPS)...)
+	default:
+		return fmt.Errorf("annex B output only supported for AVC and HEVC tracks")
+	}
+	samples, err := f.fullSamplesForTrack(trackID, rs)
+	if err != nil {
+		return err
+	}
+	startCode := []byte{0, 0, 0, 1}
+	for i := range samples {
+		nalus, err := avc.GetNalusFromSample(samples[i].Data)
+		if err != nil {
+			return fmt.Errorf("sample %d: %w", i+1, err)
+		}
+		if insertParamSets && samples[i].IsSync() {
+			nalus = append(paramSets[:len(paramSets):len(paramSets)], nalus...)
+		}
+		for _, nalu := range nalus {
+			if _, err = w.Write(startCode); err != nil {
+				return err
+			}
+			if _, err = w.Write(nalu); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// FirstPresentationTime - presentation time and timescale of the earliest sample of a track.
+// For fragmented files, the earliest composition time in the first fragment of the track is used,
+// and for progressive files the time is given by the composition time offset of the first sample.
+// An initial edit list is applied by adding empty edits and subtracting the media time of the first edit.
+func (f *File) FirstPresentationTime(trackID uint32) (uint64, uint32, error) {
+	trak := f.getTrak(trackID)
+	if trak == nil {
+		return 0, 0, fmt.Errorf("no track with trackID %d", trackID)
+	}
+	timescale := trak.Mdia.Mdhd.Timescale
+	var pts int64
+	if f.isFragmented {
+		traf := f.firstTraf(trackID)
+		if traf == nil {
+			return 0, 0, fmt.Errorf("no fragment for trackID %d", trackID)
+		}
+		if traf.Tfdt == nil {
+			return 0, 0, fmt.Errorf("no tfdt for trackID %d", trackID)
+		}
+		var ok bool
+		pts, _, ok = traf.presentationTimeRange(f.getTrex(trackID))
+		if !ok {
+			return 0, 0, fmt.Errorf("no samples in first fragment of trackID %d", trackID)
+		}
+	} else {
+		ctts := trak.Mdia.Minf.Stbl.Ctts
+		if ctts != nil {
+			pts = int64(ctts.GetCompositionTimeOffset(1))
+		}
+	}
+	if trak.Edts != nil && len(trak.Edts.Elst) > 0 {
+		var movieTimescale uint32
+		if f.Moov != nil && f.Moov.Mvhd != nil {
+			movieTimescale = f.Moov.Mvhd.Timescale
+		}
+		for _, entry := range trak.Edts.Elst[0].Entries {
+			if entry.MediaTime == -1 { // Empty edit
+				if movieTimescale == 0 {
+					return 0, 0, fmt.Errorf("no movie timescale for empty edit")
+				}
+				pts += int64(entry.SegmentDuration * uint64(timescale) / uint64(movieTimescale))
+				continue
+			}
+			pts -= entry.MediaTime
+			break
+		}
+	}
+	if pts < 0 {
+		return 0, 0, fmt.Errorf("negative presentation time %d for trackID %d", pts, trackID)
+	}
+	return uint64(pts), timescale, nil
+}
+
+// firstTraf - first traf for trackID in fragments
+func (f *File) firstTraf(trackID uint32) *TrafBox {
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID == trackID {
+					return traf
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getTrak - get trak for trackID from init segment or moov
+func (f *File) getTrak(trackID uint32) *TrakBox {
+	moov := f.Moov
+	if f.isFragmented && f.Init != nil {
+		moov = f.Init.Moov
+	}
+	if moov == nil {
+		return nil
+	}
+	return moov.GetTrak(trackID)
+}
+
 // CopySampleData - copy sample data from a track in a progressive mp4 file to w. Use rs if lazy read.
 func (f *File) CopySampleData(w io.Writer, rs io.ReadSeeker, trak *TrakBox, startSampleNr, endSampleNr uint32) error {
 	if f.isFragmented {
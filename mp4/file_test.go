@@ -2,11 +2,16 @@ package mp4
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/hex"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/edgeware/mp4ff/avc"
 	"github.com/edgeware/mp4ff/bits"
+	"github.com/go-test/deep"
 )
 
 func TestDecodeFileWithLazyMdatOption(t *testing.T) {
@@ -122,3 +127,1006 @@ func TestDecodeEncodeProgressiveSliceWriter(t *testing.T) {
 		t.Errorf("output differs from input")
 	}
 }
+
+func TestComputeTfdtFromDurations(t *testing.T) {
+	const sampleDur = 1000
+	const nrSamplesPerFrag = 4
+	const startTime = 5000
+
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	var buf bytes.Buffer
+	err := init.Encode(&buf)
+	if err != nil {
+		t.Error(err)
+	}
+	for nr := uint32(1); nr <= 3; nr++ {
+		frag, err := CreateFragment(nr, 1)
+		if err != nil {
+			t.Error(err)
+		}
+		for i := 0; i < nrSamplesPerFrag; i++ {
+			frag.AddFullSample(FullSample{
+				Sample:     Sample{Flags: SyncSampleFlags, Dur: sampleDur, Size: 2},
+				DecodeTime: uint64(startTime + (int(nr-1)*nrSamplesPerFrag+i)*sampleDur),
+				Data:       []byte{byte(nr), byte(i)},
+			})
+		}
+		traf := frag.Moof.Traf
+		var children []Box
+		for _, c := range traf.Children {
+			if c.Type() != "tfdt" {
+				children = append(children, c)
+			}
+		}
+		traf.Children = children
+		traf.Tfdt = nil
+		seg := NewMediaSegmentWithoutStyp()
+		seg.AddFragment(frag)
+		err = seg.Encode(&buf)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	data := buf.Bytes()
+
+	f, err := DecodeFile(bytes.NewBuffer(data))
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = f.Segments[0].Fragments[0].GetFullSamples(f.Init.Moov.Mvex.Trex)
+	if err == nil {
+		t.Errorf("expected error for fragment without tfdt")
+	}
+
+	for _, decode := range []string{"reader", "slicereader"} {
+		var f *File
+		switch decode {
+		case "reader":
+			f, err = DecodeFile(bytes.NewBuffer(data), WithAssumeContinuousTime(startTime))
+		case "slicereader":
+			f, err = DecodeFileSR(bits.NewFixedSliceReader(data), WithAssumeContinuousTime(startTime))
+		}
+		if err != nil {
+			t.Error(err)
+		}
+		var frags []*Fragment
+		for _, seg := range f.Segments {
+			frags = append(frags, seg.Fragments...)
+		}
+		if len(frags) != 3 {
+			t.Fatalf("%s: got %d fragments instead of 3", decode, len(frags))
+		}
+		trex := f.Init.Moov.Mvex.Trex
+		for i, frag := range frags {
+			traf := frag.Moof.Traf
+			if traf.Children[1] != traf.Tfdt {
+				t.Errorf("%s: tfdt not directly after tfhd", decode)
+			}
+			wantedTime := uint64(startTime + i*nrSamplesPerFrag*sampleDur)
+			if traf.Tfdt.BaseMediaDecodeTime != wantedTime {
+				t.Errorf("%s: fragment %d: got tfdt %d instead of %d", decode, i, traf.Tfdt.BaseMediaDecodeTime, wantedTime)
+			}
+			samples, err := frag.GetFullSamples(trex)
+			if err != nil {
+				t.Error(err)
+			}
+			lastSample := samples[len(samples)-1]
+			if lastSample.DecodeTime != wantedTime+(nrSamplesPerFrag-1)*sampleDur {
+				t.Errorf("%s: fragment %d: got last decode time %d", decode, i, lastSample.DecodeTime)
+			}
+			if lastSample.Data[0] != byte(i+1) {
+				t.Errorf("%s: fragment %d: wrong sample data %v", decode, i, lastSample.Data)
+			}
+		}
+	}
+}
+
+func TestComputeTfdtFromDurationsMultiTrack(t *testing.T) {
+	const sampleDur = 1000
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.AddEmptyTrack(48000, "audio", "und")
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	frag, err := CreateMultiTrackFragment(1, []uint32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantedData := map[uint32][]byte{1: {1, 2, 3}, 2: {4, 5}}
+	for _, trackID := range []uint32{1, 2} {
+		data := wantedData[trackID]
+		err = frag.AddFullSampleToTrack(FullSample{
+			Sample: NewSample(SyncSampleFlags, sampleDur, uint32(len(data)), 0),
+			Data:   data,
+		}, trackID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, traf := range frag.Moof.Trafs {
+		var children []Box
+		for _, c := range traf.Children {
+			if c.Type() != "tfdt" {
+				children = append(children, c)
+			}
+		}
+		traf.Children = children
+		traf.Tfdt = nil
+	}
+	if err = frag.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf, WithAssumeContinuousTime(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The samples are read before and after encoding the file with the added tfdt boxes
+	for _, stage := range []string{"decoded", "re-encoded"} {
+		if stage == "re-encoded" {
+			buf.Reset()
+			if err = f.Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if f, err = DecodeFile(&buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, trex := range f.Init.Moov.Mvex.Trexs {
+			samples, err := f.Segments[0].Fragments[0].GetFullSamples(trex)
+			if err != nil {
+				t.Fatalf("%s: %s", stage, err)
+			}
+			if len(samples) != 1 || !bytes.Equal(samples[0].Data, wantedData[trex.TrackID]) {
+				t.Errorf("%s: track %d: wrong sample data", stage, trex.TrackID)
+			}
+		}
+	}
+}
+
+func TestDashSegmentBaseRanges(t *testing.T) {
+	f, err := ReadMP4File("testdata/prog_8s_enc_dashinit.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ftyp(24) + free(58) + moov(1392) followed by sidx(44)
+	indexStart, indexEnd, initStart, initEnd, err := f.DashSegmentBaseRanges()
+	if err != nil {
+		t.Error(err)
+	}
+	if initStart != 0 || initEnd != 1473 {
+		t.Errorf("got init range %d-%d instead of 0-1473", initStart, initEnd)
+	}
+	if indexStart != 1474 || indexEnd != 1517 {
+		t.Errorf("got index range %d-%d instead of 1474-1517", indexStart, indexEnd)
+	}
+
+	f, err = ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, _, err = f.DashSegmentBaseRanges()
+	if err == nil {
+		t.Errorf("expected error for file without sidx")
+	}
+}
+
+func TestDumpSamples(t *testing.T) {
+	fd, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	f, err := DecodeFile(fd, WithDecodeMode(DecModeLazyMdat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trackID := range []uint32{1, 2} {
+		dir, err := ioutil.TempDir("", "mp4ff-dump")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		asAnnexB := trackID == 2
+		err = f.DumpSamples(fd, trackID, dir, asAnnexB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trak := f.Moov.GetTrak(trackID)
+		if len(files) != int(trak.GetNrSamples()) {
+			t.Errorf("track %d: got %d files instead of %d", trackID, len(files), trak.GetNrSamples())
+		}
+		if files[0].Name() != "sample_000001_sync.bin" {
+			t.Errorf("track %d: got first file name %q", trackID, files[0].Name())
+		}
+		if int(files[0].Size()) != int(trak.Mdia.Minf.Stbl.Stsz.GetSampleSize(1)) {
+			t.Errorf("track %d: got first sample size %d", trackID, files[0].Size())
+		}
+		if asAnnexB {
+			data, err := ioutil.ReadFile(filepath.Join(dir, files[0].Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data[:4], []byte{0, 0, 0, 1}) {
+				t.Errorf("sample does not start with Annex B start code: %x", data[:4])
+			}
+		}
+	}
+	err = f.DumpSamples(fd, 1, os.TempDir(), true)
+	if err == nil {
+		t.Errorf("expected error for Annex B output of audio track")
+	}
+}
+
+func TestWriteAnnexB(t *testing.T) {
+	fd, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	f, err := DecodeFile(fd, WithDecodeMode(DecModeLazyMdat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trackID := uint32(2)
+	samples, err := f.fullSamplesForTrack(trackID, fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	avcC := f.Moov.GetTrak(trackID).Mdia.Minf.Stbl.Stsd.AvcX.AvcC
+	for _, insertParamSets := range []bool{false, true} {
+		var expected [][]byte
+		for _, s := range samples {
+			if insertParamSets && s.IsSync() {
+				expected = append(expected, avcC.SPSnalus...)
+				expected = append(expected, avcC.PPSnalus...)
+			}
+			nalus, err := avc.GetNalusFromSample(s.Data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected = append(expected, nalus...)
+		}
+		var buf bytes.Buffer
+		err = f.WriteAnnexB(&buf, trackID, fd, insertParamSets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nalus := avc.ExtractNalusFromByteStream(buf.Bytes())
+		if len(nalus) != len(expected) {
+			t.Fatalf("insertParamSets=%t: got %d NALUs instead of %d", insertParamSets, len(nalus), len(expected))
+		}
+		for i := range nalus {
+			if !bytes.Equal(nalus[i], expected[i]) {
+				t.Errorf("insertParamSets=%t: NALU %d differs", insertParamSets, i)
+				break
+			}
+		}
+	}
+	err = f.WriteAnnexB(ioutil.Discard, 1, fd, false)
+	if err == nil {
+		t.Errorf("expected error for Annex B output of audio track")
+	}
+}
+
+func TestFragmentSampleDataFromOwnMdat(t *testing.T) {
+	sampleData := [][][]byte{
+		{{0x01, 0x02, 0x03}, {0x04, 0x05}},
+		{{0x11, 0x12}, {0x13, 0x14, 0x15, 0x16}},
+	}
+	var buf bytes.Buffer
+	var decTime uint64
+	for i, fragData := range sampleData {
+		frag, err := CreateFragment(uint32(i+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, data := range fragData {
+			s := FullSample{
+				Sample:     NewSample(SyncSampleFlags, 1000, uint32(len(data)), 0),
+				DecodeTime: decTime,
+				Data:       data,
+			}
+			frag.AddFullSample(s)
+			decTime += 1000
+		}
+		err = frag.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := DecodeFile(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frags []*Fragment
+	for _, seg := range f.Segments {
+		frags = append(frags, seg.Fragments...)
+	}
+	if len(frags) != len(sampleData) {
+		t.Fatalf("got %d fragments instead of %d", len(frags), len(sampleData))
+	}
+	for i, frag := range frags {
+		if frag.Mdat == nil {
+			t.Fatalf("fragment %d: no mdat", i+1)
+		}
+		samples, err := frag.GetFullSamples(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(samples) != len(sampleData[i]) {
+			t.Fatalf("fragment %d: got %d samples instead of %d", i+1, len(samples), len(sampleData[i]))
+		}
+		for j, s := range samples {
+			if !bytes.Equal(s.Data, sampleData[i][j]) {
+				t.Errorf("fragment %d sample %d: got data %x instead of %x", i+1, j+1, s.Data, sampleData[i][j])
+			}
+		}
+	}
+}
+
+func TestOverhead(t *testing.T) {
+	for _, fileName := range []string{"testdata/prog_8s.mp4", "testdata/prog_8s_enc_dashinit.mp4"} {
+		for _, decMode := range []DecFileMode{DecModeNormal, DecModeLazyMdat} {
+			data, err := ioutil.ReadFile(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := DecodeFile(bytes.NewReader(data), WithDecodeMode(decMode))
+			if err != nil {
+				t.Fatal(err)
+			}
+			mediaBytes, containerBytes, err := f.Overhead()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mediaBytes+containerBytes != uint64(len(data)) {
+				t.Errorf("%s: media %d + container %d != file size %d", fileName, mediaBytes, containerBytes, len(data))
+			}
+			var expectedMedia uint64
+			for _, c := range f.Children {
+				if mdat, ok := c.(*MdatBox); ok {
+					expectedMedia += mdat.Size() - mdat.HeaderSize()
+				}
+			}
+			if mediaBytes != expectedMedia || mediaBytes == 0 {
+				t.Errorf("%s: got %d media bytes instead of %d", fileName, mediaBytes, expectedMedia)
+			}
+		}
+	}
+	_, _, err := (&File{}).Overhead()
+	if err == nil {
+		t.Errorf("expected error for empty file")
+	}
+}
+
+func TestDecodeHeaderlessStream(t *testing.T) {
+	for _, withStyp := range []bool{false, true} {
+		var buf bytes.Buffer
+		for nr := uint32(1); nr <= 2; nr++ {
+			seg := NewMediaSegmentWithoutStyp()
+			if withStyp {
+				seg = NewMediaSegment()
+			}
+			frag, err := CreateFragment(nr, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seg.AddFragment(frag)
+			frag.AddFullSample(FullSample{
+				Sample:     NewSample(SyncSampleFlags, 1000, 3, 0),
+				DecodeTime: uint64(nr) * 1000,
+				Data:       []byte{1, 2, 3},
+			})
+			err = seg.Encode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		input := append([]byte{}, buf.Bytes()...)
+		f, err := DecodeFile(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.HasFtyp() || f.Init != nil || !f.IsFragmented() {
+			t.Errorf("withStyp=%t: got HasFtyp %t, init %v, fragmented %t", withStyp, f.HasFtyp(), f.Init, f.IsFragmented())
+		}
+		nrFrags := 0
+		for _, seg := range f.Segments {
+			nrFrags += len(seg.Fragments)
+		}
+		if nrFrags != 2 {
+			t.Errorf("withStyp=%t: got %d fragments instead of 2", withStyp, nrFrags)
+		}
+		var out bytes.Buffer
+		err = f.Encode(&out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out.Bytes(), input) {
+			t.Errorf("withStyp=%t: re-encoded stream differs from input", withStyp)
+		}
+	}
+	fd, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	f, err := DecodeFile(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.HasFtyp() {
+		t.Errorf("no ftyp found in prog_8s.mp4")
+	}
+}
+
+func TestFragmentKeyframeFlags(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.Moov.Mvex.Trex.DefaultSampleFlags = SyncSampleFlags
+	sync, nonSync := SyncSampleFlags, NonSyncSampleFlags
+	testCases := []struct {
+		desc          string
+		flags         []uint32
+		optimize      bool
+		useTrex       bool
+		expectedFirst bool
+	}{
+		{desc: "per-sample sync", flags: []uint32{sync, nonSync}, expectedFirst: true},
+		{desc: "per-sample nonsync", flags: []uint32{nonSync, nonSync}, expectedFirst: false},
+		{desc: "first_sample_flags", flags: []uint32{sync, nonSync, nonSync}, optimize: true, expectedFirst: true},
+		{desc: "tfhd default", flags: []uint32{nonSync, nonSync}, optimize: true, expectedFirst: false},
+		{desc: "trex default", flags: []uint32{sync, sync}, optimize: true, useTrex: true, expectedFirst: true},
+	}
+	var buf bytes.Buffer
+	err := init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decTime uint64
+	for i, tc := range testCases {
+		frag, err := CreateFragment(uint32(i+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, flags := range tc.flags {
+			frag.AddFullSample(FullSample{Sample: NewSample(flags, 3000, 1, 0), DecodeTime: decTime, Data: []byte{0}})
+			decTime += 3000
+		}
+		traf := frag.Moof.Traf
+		if tc.optimize {
+			err = traf.OptimizeTfhdTrun()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if tc.useTrex {
+			traf.Tfhd.Flags &^= defaultSampleFlagsPresent
+		}
+		trun := traf.Trun
+		if _, present := trun.FirstSampleFlags(); present != (tc.desc == "first_sample_flags") {
+			t.Errorf("%s: first_sample_flags present=%t", tc.desc, present)
+		}
+		if trun.HasSampleFlags() != !tc.optimize {
+			t.Errorf("%s: sample flags present=%t", tc.desc, trun.HasSampleFlags())
+		}
+		err = frag.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyframeFlags, err := f.FragmentKeyframeFlags(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keyframeFlags) != len(testCases) {
+		t.Fatalf("got %d flags instead of %d", len(keyframeFlags), len(testCases))
+	}
+	for i, tc := range testCases {
+		if keyframeFlags[i] != tc.expectedFirst {
+			t.Errorf("%s: got keyframe %t", tc.desc, keyframeFlags[i])
+		}
+	}
+}
+
+func TestSanitizeHandlerNames(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nameLengths int
+	for _, trak := range f.Moov.Traks {
+		nameLengths += len(trak.Mdia.Hdlr.Name)
+	}
+	origSamples := make(map[uint32][]FullSample)
+	for _, trak := range f.Moov.Traks {
+		origSamples[trak.Tkhd.TrackID], err = f.fullSamplesForTrack(trak.Tkhd.TrackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	replacement := "x"
+	if err = f.SanitizeHandlerNames(replacement); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = f.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedSize := len(data) - nameLengths + len(f.Moov.Traks)*len(replacement)
+	if buf.Len() != expectedSize {
+		t.Errorf("got size %d instead of %d", buf.Len(), expectedSize)
+	}
+	f, err = DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trak := range f.Moov.Traks {
+		if trak.Mdia.Hdlr.Name != replacement {
+			t.Errorf("track %d: got hdlr name %q", trak.Tkhd.TrackID, trak.Mdia.Hdlr.Name)
+		}
+		samples, err := f.fullSamplesForTrack(trak.Tkhd.TrackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(samples, origSamples[trak.Tkhd.TrackID]); diff != nil {
+			t.Errorf("track %d: samples differ after sanitizing: %v", trak.Tkhd.TrackID, diff)
+		}
+	}
+}
+
+func TestFixVisualDimensions(t *testing.T) {
+	fd, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	f, err := DecodeFile(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	videoTrackID := uint32(2)
+	avcX := f.Moov.GetTrak(videoTrackID).Mdia.Minf.Stbl.Stsd.AvcX
+	width, height := avcX.Width, avcX.Height
+	changed, err := f.FixVisualDimensions(videoTrackID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Errorf("dimensions %dx%d changed although correct", width, height)
+	}
+	avcX.Width, avcX.Height = 1920, 1080
+	changed, err = f.FixVisualDimensions(videoTrackID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || avcX.Width != width || avcX.Height != height {
+		t.Errorf("got changed=%t and %dx%d instead of %dx%d", changed, avcX.Width, avcX.Height, width, height)
+	}
+	_, err = f.FixVisualDimensions(1)
+	if err == nil {
+		t.Errorf("expected error for audio track")
+	}
+
+	vps, _ := hex.DecodeString(vpsHex)
+	sps, _ := hex.DecodeString(spsHex)
+	pps, _ := hex.DecodeString(ppsHex)
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	err = init.Moov.Trak.SetHEVCDescriptor("hvc1", [][]byte{vps}, [][]byte{sps}, [][]byte{pps}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hvcX := init.Moov.Trak.Mdia.Minf.Stbl.Stsd.HvcX
+	width, height = hvcX.Width, hvcX.Height
+	hvcX.Width, hvcX.Height = 16, 16
+	var buf bytes.Buffer
+	err = init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hf, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err = hf.FixVisualDimensions(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hvcX = hf.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.HvcX
+	if !changed || hvcX.Width != width || hvcX.Height != height {
+		t.Errorf("hevc: got changed=%t and %dx%d instead of %dx%d", changed, hvcX.Width, hvcX.Height, width, height)
+	}
+}
+
+func TestEncodeInitWithoutFtyp(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/init1.cmfv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data[4:8]) != "ftyp" {
+		t.Fatalf("init1.cmfv does not start with ftyp")
+	}
+	ftypSize := binary.BigEndian.Uint32(data[:4])
+	input := data[ftypSize:]
+	f, err := DecodeFile(bytes.NewBuffer(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.HasFtyp() || f.Init == nil || f.Init.Ftyp != nil {
+		t.Errorf("got HasFtyp %t and init %v", f.HasFtyp(), f.Init)
+	}
+	var out bytes.Buffer
+	if err = f.Encode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), input) {
+		t.Errorf("re-encoded init segment differs from input")
+	}
+}
+
+func TestZeroBaseTime(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.AddEmptyTrack(48000, "audio", "und")
+	startTimes := []uint64{900000, 480000}
+	durs := []uint32{3000, 1024}
+	var buf bytes.Buffer
+	err := init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for fragNr := 0; fragNr < 2; fragNr++ {
+		frag, err := CreateMultiTrackFragment(uint32(fragNr+1), []uint32{1, 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for trackNr := range startTimes {
+			for i := 0; i < 2; i++ {
+				fs := FullSample{
+					Sample:     NewSample(SyncSampleFlags, durs[trackNr], 1, 0),
+					DecodeTime: startTimes[trackNr],
+					Data:       []byte{0},
+				}
+				err = frag.AddFullSampleToTrack(fs, uint32(trackNr+1))
+				if err != nil {
+					t.Fatal(err)
+				}
+				startTimes[trackNr] += uint64(durs[trackNr])
+			}
+		}
+		err = frag.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset, err := f.ZeroBaseTime(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != 900000 {
+		t.Errorf("got offset %d instead of 900000", offset)
+	}
+	buf.Reset()
+	err = f.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err = DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tfdt := f.firstTraf(1).Tfdt; tfdt.BaseMediaDecodeTime != 0 {
+		t.Errorf("got first tfdt %d instead of 0", tfdt.BaseMediaDecodeTime)
+	}
+	if tfdt := f.firstTraf(2).Tfdt; tfdt.BaseMediaDecodeTime != 480000 {
+		t.Errorf("got first audio tfdt %d instead of 480000", tfdt.BaseMediaDecodeTime)
+	}
+	trak := f.Init.Moov.Traks[0]
+	if trak.Edts == nil || len(trak.Edts.Elst) != 1 {
+		t.Fatalf("no edit list added")
+	}
+	if trak.Children[1] != trak.Edts {
+		t.Errorf("edts not directly after tkhd")
+	}
+	expectedDur := uint64(10 * f.Init.Moov.Mvhd.Timescale)
+	entries := trak.Edts.Elst[0].Entries
+	if len(entries) != 2 || entries[0].MediaTime != -1 || entries[0].SegmentDuration != expectedDur {
+		t.Errorf("got edit list entries %+v", entries)
+	}
+	if pt, _, err := f.FirstPresentationTime(1); err != nil || pt != 10*90000 {
+		t.Errorf("got first presentation time %d, err %v", pt, err)
+	}
+}
+
+func TestZeroBaseTimeEditList(t *testing.T) {
+	const startTime = 900000
+	for _, tc := range []struct {
+		mediaTime, wantedMediaTime int64
+	}{
+		{startTime + 3000, 3000},
+		{3000, 0},
+	} {
+		init := CreateEmptyInit()
+		init.AddEmptyTrack(90000, "video", "und")
+		edts := &EdtsBox{}
+		edts.AddChild(&ElstBox{Entries: []ElstEntry{
+			{SegmentDuration: 0, MediaTime: -1, MediaRateInteger: 1},
+			{SegmentDuration: 0, MediaTime: tc.mediaTime, MediaRateInteger: 1}}})
+		init.Moov.Trak.AddChild(edts)
+		frag, err := CreateFragment(1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frag.AddFullSample(FullSample{
+			Sample:     NewSample(SyncSampleFlags, 3000, 1, 0),
+			DecodeTime: startTime,
+			Data:       []byte{0},
+		})
+		var buf bytes.Buffer
+		if err = init.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if err = frag.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		f, err := DecodeFile(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = f.ZeroBaseTime(1); err != nil {
+			t.Fatal(err)
+		}
+		entries := f.Init.Moov.Trak.Edts.Elst[0].Entries
+		if len(entries) != 2 || entries[0].MediaTime != -1 || entries[1].MediaTime != tc.wantedMediaTime {
+			t.Errorf("media time %d: got edit list entries %+v", tc.mediaTime, entries)
+		}
+	}
+}
+
+func TestFirstPresentationTime(t *testing.T) {
+	for _, mediaTime := range []int64{-1, 3000} {
+		init := CreateEmptyInit()
+		init.AddEmptyTrack(90000, "video", "und")
+		if mediaTime >= 0 {
+			edts := &EdtsBox{}
+			edts.AddChild(&ElstBox{Entries: []ElstEntry{{SegmentDuration: 0, MediaTime: mediaTime, MediaRateInteger: 1}}})
+			init.Moov.Trak.AddChild(edts)
+		}
+		frag, err := CreateFragment(1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decTime := uint64(180000)
+		for _, cto := range []int32{6000, 0, 3000} {
+			frag.AddFullSample(FullSample{
+				Sample:     NewSample(SyncSampleFlags, 3000, 1, cto),
+				DecodeTime: decTime,
+				Data:       []byte{0},
+			})
+			decTime += 3000
+		}
+		var buf bytes.Buffer
+		err = init.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = frag.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := DecodeFile(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pts, timescale, err := f.FirstPresentationTime(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantedPTS := uint64(183000)
+		if mediaTime >= 0 {
+			wantedPTS -= uint64(mediaTime)
+		}
+		if pts != wantedPTS || timescale != 90000 {
+			t.Errorf("mediaTime %d: got pts %d/%d instead of %d/90000", mediaTime, pts, timescale, wantedPTS)
+		}
+	}
+}
+
+func TestParseUntilMdat(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, mdatStart, mdatSize, err := ParseUntilMdat(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Ftyp == nil || f.Moov == nil {
+		t.Errorf("ftyp and moov not parsed")
+	}
+	if mdatStart != f.Size() {
+		t.Errorf("got mdat start %d instead of %d", mdatStart, f.Size())
+	}
+	if string(data[mdatStart+4:mdatStart+8]) != "mdat" {
+		t.Errorf("no mdat box at offset %d", mdatStart)
+	}
+	fullFile, err := DecodeFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mdatSize != fullFile.Mdat.Size() {
+		t.Errorf("got mdat size %d instead of %d", mdatSize, fullFile.Mdat.Size())
+	}
+	_, _, _, err = ParseUntilMdat(bytes.NewReader(data[:mdatStart]))
+	if err == nil {
+		t.Errorf("expected error when there is no mdat")
+	}
+}
+
+func TestDecodeLenientMdatOrder(t *testing.T) {
+	const sampleDur = 1000
+	const nrSamplesPerFrag = 3
+
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var wantedSamples []FullSample
+	for nr := uint32(1); nr <= 3; nr++ {
+		frag, err := CreateFragment(nr, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < nrSamplesPerFrag; i++ {
+			fs := FullSample{
+				Sample:     Sample{Flags: SyncSampleFlags, Dur: sampleDur, Size: 3},
+				DecodeTime: uint64((int(nr-1)*nrSamplesPerFrag + i) * sampleDur),
+				Data:       []byte{byte(nr), byte(i), 0xff},
+			}
+			frag.AddFullSample(fs)
+			wantedSamples = append(wantedSamples, fs)
+		}
+		// Write mdat before moof, keeping the trun data offsets for an mdat following the moof
+		var fragBuf bytes.Buffer
+		if err = frag.Encode(&fragBuf); err != nil {
+			t.Fatal(err)
+		}
+		fragData := fragBuf.Bytes()
+		moofSize := frag.Moof.Size()
+		buf.Write(fragData[moofSize:])
+		buf.Write(fragData[:moofSize])
+	}
+	data := buf.Bytes()
+
+	if _, err := DecodeFile(bytes.NewBuffer(data)); err == nil {
+		t.Errorf("expected error for mdat before moof without lenient option")
+	}
+
+	for _, decode := range []string{"reader", "slicereader"} {
+		var f *File
+		var err error
+		switch decode {
+		case "reader":
+			f, err = DecodeFile(bytes.NewBuffer(data), WithLenientMdatOrder())
+		case "slicereader":
+			f, err = DecodeFileSR(bits.NewFixedSliceReader(data), WithLenientMdatOrder())
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", decode, err)
+		}
+		if len(f.Warnings) != 3 {
+			t.Errorf("%s: got %d warnings instead of 3: %v", decode, len(f.Warnings), f.Warnings)
+		}
+		var samples []FullSample
+		for _, seg := range f.Segments {
+			for _, frag := range seg.Fragments {
+				fss, err := frag.GetFullSamples(f.Init.Moov.Mvex.Trex)
+				if err != nil {
+					t.Fatalf("%s: %s", decode, err)
+				}
+				samples = append(samples, fss...)
+			}
+		}
+		if diff := deep.Equal(samples, wantedSamples); diff != nil {
+			t.Errorf("%s: samples differ: %v", decode, diff)
+		}
+		// Encoding writes the fragments in moof-mdat order
+		var outBuf bytes.Buffer
+		if err = f.Encode(&outBuf); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = DecodeFile(&outBuf); err != nil {
+			t.Errorf("%s: decode of re-encoded file: %s", decode, err)
+		}
+	}
+}
+
+func TestDecodeLenientMdatOrderMultiTrack(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.AddEmptyTrack(48000, "audio", "und")
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	durs := []uint32{3000, 1024}
+	wantedSamples := make([][]FullSample, 2)
+	for nr := uint32(1); nr <= 2; nr++ {
+		frag, err := CreateMultiTrackFragment(nr, []uint32{1, 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for trackNr := range durs {
+			for i := 0; i < 2; i++ {
+				fs := FullSample{
+					Sample:     Sample{Flags: SyncSampleFlags, Dur: durs[trackNr], Size: 2},
+					DecodeTime: uint64((int(nr-1)*2 + i) * int(durs[trackNr])),
+					Data:       []byte{byte(nr), byte(trackNr*2 + i)},
+				}
+				if err = frag.AddFullSampleToTrack(fs, uint32(trackNr+1)); err != nil {
+					t.Fatal(err)
+				}
+				wantedSamples[trackNr] = append(wantedSamples[trackNr], fs)
+			}
+		}
+		// Write mdat before moof, keeping the trun data offsets for an mdat following the moof
+		var fragBuf bytes.Buffer
+		if err = frag.Encode(&fragBuf); err != nil {
+			t.Fatal(err)
+		}
+		fragData := fragBuf.Bytes()
+		moofSize := frag.Moof.Size()
+		buf.Write(fragData[moofSize:])
+		buf.Write(fragData[:moofSize])
+	}
+	f, err := DecodeFile(bytes.NewBuffer(buf.Bytes()), WithLenientMdatOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outBuf bytes.Buffer
+	if err = f.Encode(&outBuf); err != nil {
+		t.Fatal(err)
+	}
+	f, err = DecodeFile(&outBuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for trackNr, trex := range f.Init.Moov.Mvex.Trexs {
+		var samples []FullSample
+		for _, seg := range f.Segments {
+			for _, frag := range seg.Fragments {
+				fss, err := frag.GetFullSamples(trex)
+				if err != nil {
+					t.Fatal(err)
+				}
+				samples = append(samples, fss...)
+			}
+		}
+		if diff := deep.Equal(samples, wantedSamples[trackNr]); diff != nil {
+			t.Errorf("track %d: samples differ after re-encoding: %v", trex.TrackID, diff)
+		}
+	}
+}
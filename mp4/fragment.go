@@ -4,19 +4,54 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"sort"
 
 	"github.com/edgeware/mp4ff/bits"
 )
 
-// Fragment - MP4 Fragment ([prft] + moof + mdat)
+// Fragment - MP4 Fragment ([emsg] + [prft] + moof + mdat)
 type Fragment struct {
+	Emsgs       []*EmsgBox
 	Prft        *PrftBox
 	Moof        *MoofBox
 	Mdat        *MdatBox
 	Children    []Box       // All top-level boxes in order
 	nextTrunNr  uint32      // To handle multi-trun cases
 	EncOptimize EncOptimize // Bit field with optimizations being done at encoding
+	// DataOffsetMode - how tfhd and trun signal the sample data position at encoding
+	DataOffsetMode DataOffsetMode
+}
+
+// DataOffsetMode - how the position of sample data is signaled in tfhd and trun when encoding a fragment
+type DataOffsetMode uint32
+
+const (
+	// DataOffsetUnchanged - keep tfhd flags and set trun data_offset relative to moof start
+	DataOffsetUnchanged DataOffsetMode = iota
+	// DataOffsetDefaultBaseIsMoof - set default-base-is-moof in tfhd and trun data_offset relative to moof start
+	DataOffsetDefaultBaseIsMoof
+	// DataOffsetExplicitBase - set tfhd base_data_offset to the moof start position and
+	// trun data_offset relative to moof start
+	DataOffsetExplicitBase
+	// DataOffsetMdatRelative - set tfhd base_data_offset to the mdat payload start position and
+	// trun data_offset relative to mdat payload start
+	DataOffsetMdatRelative
+)
+
+func (m DataOffsetMode) String() string {
+	switch m {
+	case DataOffsetUnchanged:
+		return "DataOffsetUnchanged"
+	case DataOffsetDefaultBaseIsMoof:
+		return "DataOffsetDefaultBaseIsMoof"
+	case DataOffsetExplicitBase:
+		return "DataOffsetExplicitBase"
+	case DataOffsetMdatRelative:
+		return "DataOffsetMdatRelative"
+	default:
+		return fmt.Sprintf("DataOffsetMode(%d)", uint32(m))
+	}
 }
 
 // NewFragment - New empty one-track MP4 Fragment
@@ -71,16 +106,57 @@ func CreateMultiTrackFragment(seqNumber uint32, trackIDs []uint32) (*Fragment, e
 // AddChild - Add a top-level box to Fragment
 func (f *Fragment) AddChild(b Box) {
 	switch b.Type() {
+	case "emsg":
+		f.Emsgs = append(f.Emsgs, b.(*EmsgBox))
 	case "prft":
 		f.Prft = b.(*PrftBox)
 	case "moof":
 		f.Moof = b.(*MoofBox)
 	case "mdat":
-		f.Mdat = b.(*MdatBox)
+		if f.Mdat == nil { // The sample data belongs to the mdat directly following the moof
+			f.Mdat = b.(*MdatBox)
+		}
 	}
 	f.Children = append(f.Children, b)
 }
 
+// setPrft - replace existing prft or insert it as first child
+func (f *Fragment) setPrft(prft *PrftBox) {
+	for i, c := range f.Children {
+		if c.Type() == "prft" {
+			f.Children[i] = prft
+			f.Prft = prft
+			return
+		}
+	}
+	f.Children = append([]Box{prft}, f.Children...)
+	f.Prft = prft
+}
+
+// moveTrunDataOffsetsToEarlyMdat - make trun data offsets point into an mdat before the moof.
+// Offsets written as if the mdat directly followed the moof are moved by the distance between that
+// position and the actual mdat position, while offsets already pointing before the moof are kept.
+// A DataOffsetUnchanged mode is changed to DataOffsetDefaultBaseIsMoof, so that all trun data offsets
+// are recomputed for the mdat following the moof when encoding.
+func (f *Fragment) moveTrunDataOffsetsToEarlyMdat() {
+	moof, mdat := f.Moof, f.Mdat
+	if f.DataOffsetMode == DataOffsetUnchanged {
+		f.DataOffsetMode = DataOffsetDefaultBaseIsMoof
+	}
+	moofEnd := moof.StartPos + moof.Size()
+	delta := int64(mdat.StartPos) - int64(moofEnd)
+	for _, traf := range moof.Trafs {
+		if traf.Tfhd.HasBaseDataOffset() {
+			continue
+		}
+		for _, trun := range traf.Truns {
+			if trun.HasDataOffset() && int64(moof.StartPos)+int64(trun.DataOffset) >= int64(moofEnd) {
+				trun.DataOffset += int32(delta)
+			}
+		}
+	}
+}
+
 // Size - return size of fragment including all boxes.
 // Be aware that TrafBox.OptimizeTfhdTrun() can change size
 func (f *Fragment) Size() uint64 {
@@ -95,49 +171,105 @@ func (f *Fragment) Size() uint64 {
 func (f *Fragment) GetFullSamples(trex *TrexBox) ([]FullSample, error) {
 	moof := f.Moof
 	mdat := f.Mdat
-	//seqNr := moof.Mfhd.SequenceNumber
-	var traf *TrafBox
-	foundTrak := false
-	if trex != nil {
-		for _, traf = range moof.Trafs {
-			if traf.Tfhd.TrackID == trex.TrackID {
-				foundTrak = true
-				break
-			}
-		}
-		if !foundTrak {
-			return nil, nil // This trackID may not exist for this fragment
-		}
-	} else {
-		traf = moof.Traf // The first one
+	traf := f.trafForTrex(trex)
+	if traf == nil {
+		return nil, nil // This trackID may not exist for this fragment
 	}
 	tfhd := traf.Tfhd
+	if traf.Tfdt == nil {
+		return nil, fmt.Errorf("no tfdt for track %d, use ComputeTfdtFromDurations", tfhd.TrackID)
+	}
+	if mdat == nil {
+		return nil, fmt.Errorf("no mdat following moof with sequence number %d", moof.Mfhd.SequenceNumber)
+	}
 	baseTime := traf.Tfdt.BaseMediaDecodeTime
 	moofStartPos := moof.StartPos
+	mdatPayloadStart := mdat.PayloadAbsoluteOffset()
+	mdatDataLength := uint64(len(mdat.Data)) // len should be fine for 64-bit
 	var samples []FullSample
-	for _, trun := range traf.Truns {
+	var prevTrunEnd uint64
+	for i, trun := range traf.Truns {
 		totalDur := trun.AddSampleDefaultValues(tfhd, trex)
-		var baseOffset uint64
-		if tfhd.HasBaseDataOffset() {
-			baseOffset = tfhd.BaseDataOffset
-		} else if tfhd.DefaultBaseIfMoof() {
-			baseOffset = moofStartPos
+		baseOffset := trunDataStart(tfhd, trun, moofStartPos, prevTrunEnd, i == 0)
+		if baseOffset < mdatPayloadStart {
+			return nil, errors.New("Offset before mdat following moof")
 		}
-		if trun.HasDataOffset() {
-			baseOffset = uint64(int64(trun.DataOffset) + int64(baseOffset))
-		}
-		mdatDataLength := uint64(len(mdat.Data)) // len should be fine for 64-bit
-		offsetInMdat := baseOffset - mdat.PayloadAbsoluteOffset()
-		if offsetInMdat > mdatDataLength {
+		offsetInMdat := baseOffset - mdatPayloadStart
+		trunDataSize := trun.SizeOfData()
+		if offsetInMdat+trunDataSize > mdatDataLength {
 			return nil, errors.New("Offset in mdata beyond size")
 		}
 		samples = append(samples, trun.GetFullSamples(uint32(offsetInMdat), baseTime, mdat)...)
 		baseTime += totalDur // Next trun start after this
+		prevTrunEnd = baseOffset + trunDataSize
 	}
 
 	return samples, nil
 }
 
+// SampleRange - byte range in file of one-based sample number SampleNr in a fragment
+type SampleRange struct {
+	SampleNr uint32
+	Offset   uint64
+	Size     uint32
+}
+
+// SampleByteRanges - absolute byte ranges for the samples of the trex track (first track if trex is nil).
+// moofStart is the absolute position of the moof box, which is the base offset unless tfhd has base_data_offset.
+// Truns without data offset directly follow the data of the previous trun.
+func (f *Fragment) SampleByteRanges(moofStart uint64, trex *TrexBox) ([]SampleRange, error) {
+	if f.Moof == nil {
+		return nil, fmt.Errorf("no moof in fragment")
+	}
+	traf := f.trafForTrex(trex)
+	if traf == nil {
+		return nil, nil // This trackID may not exist for this fragment
+	}
+	tfhd := traf.Tfhd
+	var ranges []SampleRange
+	var prevTrunEnd uint64
+	sampleNr := uint32(1)
+	for i, trun := range traf.Truns {
+		trun.AddSampleDefaultValues(tfhd, trex)
+		offset := trunDataStart(tfhd, trun, moofStart, prevTrunEnd, i == 0)
+		for _, s := range trun.Samples {
+			ranges = append(ranges, SampleRange{SampleNr: sampleNr, Offset: offset, Size: s.Size})
+			offset += uint64(s.Size)
+			sampleNr++
+		}
+		prevTrunEnd = offset
+	}
+	return ranges, nil
+}
+
+// trafForTrex - traf with the track ID of trex, or the first traf if trex is nil
+func (f *Fragment) trafForTrex(trex *TrexBox) *TrafBox {
+	if trex == nil {
+		return f.Moof.Traf
+	}
+	for _, traf := range f.Moof.Trafs {
+		if traf.Tfhd.TrackID == trex.TrackID {
+			return traf
+		}
+	}
+	return nil
+}
+
+// trunDataStart - absolute position of the data of trun given the moof position and the end of the previous trun data
+func trunDataStart(tfhd *TfhdBox, trun *TrunBox, moofStartPos, prevTrunEnd uint64, firstTrun bool) uint64 {
+	baseOffset := moofStartPos
+	if tfhd.HasBaseDataOffset() {
+		baseOffset = tfhd.BaseDataOffset
+	}
+	if trun.HasDataOffset() {
+		return uint64(int64(trun.DataOffset) + int64(baseOffset))
+	}
+	if !firstTrun {
+		return prevTrunEnd // Data directly follows previous trun
+	}
+	return baseOffset
+}
+
 // AddFullSample - add a full sample to the first (and only) trun of a track
 // AddFullSampleToTrack is the more general function
 func (f *Fragment) AddFullSample(s FullSample) {
@@ -254,24 +386,47 @@ func (f *Fragment) DumpSampleData(w io.Writer, trex *TrexBox) error {
 	return nil
 }
 
-// Encode - write fragment via writer
+// Encode - write fragment via writer.
+// Explicit base data offsets are set assuming that the moof is written at Moof.StartPos.
 func (f *Fragment) Encode(w io.Writer) error {
 	if f.Moof == nil {
 		return fmt.Errorf("moof not set in fragment")
 	}
-	traf := f.Moof.Traf
-	if f.EncOptimize&OptimizeTrun != 0 {
-		err := traf.OptimizeTfhdTrun()
+	return f.encodeAt(w, f.Moof.StartPos)
+}
+
+// encodeAt - write fragment via writer with the moof at position moofPos in the output
+func (f *Fragment) encodeAt(w io.Writer, moofPos uint64) error {
+	err := f.prepareEncode(moofPos)
+	if err != nil {
+		return err
+	}
+	for _, b := range f.Children {
+		err := b.Encode(w)
 		if err != nil {
 			return err
 		}
 	}
-	if f.Mdat == nil {
-		return fmt.Errorf("mdat not set in fragment")
+	return nil
+}
+
+// EncodeSW - write fragment via SliceWriter.
+// Explicit base data offsets are set assuming that the moof is written at Moof.StartPos.
+func (f *Fragment) EncodeSW(sw bits.SliceWriter) error {
+	if f.Moof == nil {
+		return fmt.Errorf("moof not set in fragment")
 	}
-	f.SetTrunDataOffsets()
-	for _, b := range f.Children {
-		err := b.Encode(w)
+	return f.encodeSWAt(sw, f.Moof.StartPos)
+}
+
+// encodeSWAt - write fragment via SliceWriter with the moof at position moofPos in the output
+func (f *Fragment) encodeSWAt(sw bits.SliceWriter, moofPos uint64) error {
+	err := f.prepareEncode(moofPos)
+	if err != nil {
+		return err
+	}
+	for _, c := range f.Children {
+		err := c.EncodeSW(sw)
 		if err != nil {
 			return err
 		}
@@ -279,8 +434,8 @@ func (f *Fragment) Encode(w io.Writer) error {
 	return nil
 }
 
-// EncodeSW - write fragment via SliceWriter
-func (f *Fragment) EncodeSW(sw bits.SliceWriter) error {
+// prepareEncode - optimize trun if requested and set data offsets for the moof written at moofPos
+func (f *Fragment) prepareEncode(moofPos uint64) error {
 	if f.Moof == nil {
 		return fmt.Errorf("moof not set in fragment")
 	}
@@ -294,14 +449,20 @@ func (f *Fragment) EncodeSW(sw bits.SliceWriter) error {
 	if f.Mdat == nil {
 		return fmt.Errorf("mdat not set in fragment")
 	}
-	f.SetTrunDataOffsets()
+	f.setTrunDataOffsets(moofPos)
+	return nil
+}
+
+// moofOffset - offset of the moof from the start of the fragment
+func (f *Fragment) moofOffset() uint64 {
+	var offset uint64
 	for _, c := range f.Children {
-		err := c.EncodeSW(sw)
-		if err != nil {
-			return err
+		if c == f.Moof {
+			break
 		}
+		offset += c.Size()
 	}
-	return nil
+	return offset
 }
 
 // Info - write box-specific information
@@ -320,33 +481,116 @@ func (f *Fragment) GetChildren() []Box {
 	return f.Children
 }
 
-// SetTrunDataOffsets - if writeOrder available, sort and set dataOffset in truns
+// SetTrunDataOffsets - if writeOrder available, sort and set dataOffset in truns.
+// Decoded fragments with multiple truns keep their data layout in the mdat, and their offsets are only
+// changed if DataOffsetMode is not DataOffsetUnchanged.
+// The tfhd flags and base_data_offset are set according to DataOffsetMode. The explicit base modes
+// use Moof.StartPos, which must be the position of the moof in the output.
 func (f *Fragment) SetTrunDataOffsets() {
-	nrTruns := 0
+	f.setTrunDataOffsets(f.Moof.StartPos)
+}
+
+// setTrunDataOffsets - set tfhd and trun data offsets for the moof written at position moofPos
+func (f *Fragment) setTrunDataOffsets(moofPos uint64) {
+	var truns []*TrunBox
 	writeOrderSet := false
 	for _, traf := range f.Moof.Trafs {
 		for _, trun := range traf.Truns {
-			nrTruns++
+			truns = append(truns, trun)
 			if trun.writeOrderNr != 0 {
 				writeOrderSet = true
 			}
 		}
 	}
-	if !writeOrderSet && nrTruns > 1 {
-		return
+	var offsetsInMdat map[*TrunBox]uint64
+	if !writeOrderSet && len(truns) > 1 {
+		if f.DataOffsetMode == DataOffsetUnchanged {
+			return
+		}
+		offsetsInMdat = f.trunOffsetsInMdat()
+		if offsetsInMdat == nil {
+			return
+		}
+		sort.SliceStable(truns, func(i, j int) bool {
+			return offsetsInMdat[truns[i]] < offsetsInMdat[truns[j]]
+		})
+	} else {
+		sort.Slice(truns, func(i, j int) bool {
+			return truns[i].writeOrderNr < truns[j].writeOrderNr
+		})
+		offsetsInMdat = make(map[*TrunBox]uint64, len(truns))
+		var offset uint64
+		for _, trun := range truns {
+			offsetsInMdat[trun] = offset
+			offset += trun.SizeOfData()
+		}
 	}
-
-	truns := make([]*TrunBox, 0, nrTruns)
-	for _, traf := range f.Moof.Trafs {
-		truns = append(truns, traf.Truns...)
+	f.setDataOffsetFlags(truns)
+	mdatPayloadOffset := f.Moof.Size() + f.Mdat.HeaderSize()
+	baseOffset := int64(mdatPayloadOffset) // trun data_offset base relative to moof start
+	switch f.DataOffsetMode {
+	case DataOffsetExplicitBase:
+		for _, traf := range f.Moof.Trafs {
+			traf.Tfhd.BaseDataOffset = moofPos
+		}
+	case DataOffsetMdatRelative:
+		firstOffset := offsetsInMdat[truns[0]]
+		for _, traf := range f.Moof.Trafs {
+			traf.Tfhd.BaseDataOffset = moofPos + mdatPayloadOffset + firstOffset
+		}
+		baseOffset = -int64(firstOffset)
 	}
-	sort.Slice(truns, func(i, j int) bool {
-		return truns[i].writeOrderNr < truns[j].writeOrderNr
-	})
-	dataOffset := f.Moof.Size() + f.Mdat.HeaderSize()
 	for _, trun := range truns {
-		trun.DataOffset = int32(dataOffset)
-		dataOffset += trun.SizeOfData()
+		if trun.HasDataOffset() {
+			trun.DataOffset = int32(baseOffset + int64(offsetsInMdat[trun]))
+		}
+	}
+}
+
+// trunOffsetsInMdat - offsets of the trun data in the mdat payload given the decoded positions,
+// or nil if some trun data is not inside the mdat of the fragment
+func (f *Fragment) trunOffsetsInMdat() map[*TrunBox]uint64 {
+	moof, mdat := f.Moof, f.Mdat
+	mdatPayloadStart := mdat.PayloadAbsoluteOffset()
+	offsets := make(map[*TrunBox]uint64)
+	for _, traf := range moof.Trafs {
+		var prevTrunEnd uint64
+		for i, trun := range traf.Truns {
+			start := trunDataStart(traf.Tfhd, trun, moof.StartPos, prevTrunEnd, i == 0)
+			if start < mdatPayloadStart {
+				return nil
+			}
+			offsets[trun] = start - mdatPayloadStart
+			prevTrunEnd = start + trun.SizeOfData()
+		}
+	}
+	return offsets
+}
+
+// setDataOffsetFlags - set tfhd and trun flags according to DataOffsetMode given truns in write order.
+// Must be done before offsets are calculated, since the flags change the moof size.
+// In DataOffsetMdatRelative mode, the first trun written has no data_offset if it is the first trun of its traf,
+// since its data starts at the base data offset.
+func (f *Fragment) setDataOffsetFlags(truns []*TrunBox) {
+	if f.DataOffsetMode == DataOffsetUnchanged {
+		return
+	}
+	for _, traf := range f.Moof.Trafs {
+		tfhd := traf.Tfhd
+		switch f.DataOffsetMode {
+		case DataOffsetDefaultBaseIsMoof:
+			tfhd.Flags = (tfhd.Flags | defaultBaseIsMoof) &^ baseDataOffsetPresent
+			tfhd.BaseDataOffset = 0
+		case DataOffsetExplicitBase, DataOffsetMdatRelative:
+			tfhd.Flags = (tfhd.Flags | baseDataOffsetPresent) &^ defaultBaseIsMoof
+		}
+		for i, trun := range traf.Truns {
+			if f.DataOffsetMode == DataOffsetMdatRelative && i == 0 && trun == truns[0] {
+				trun.Flags &^= TrunDataOffsetPresentFlag
+				continue
+			}
+			trun.Flags |= TrunDataOffsetPresentFlag
+		}
 	}
 }
 
@@ -409,3 +653,160 @@ func (f *Fragment) AddSampleInterval(sItvl SampleInterval) error {
 	f.Mdat.AddSampleDataPart(sItvl.Data)
 	return nil
 }
+
+// BaseMediaDecodeTimes - tfdt baseMediaDecodeTime for all tracks in the fragment keyed by trackID
+func (f *Fragment) BaseMediaDecodeTimes() map[uint32]uint64 {
+	times := make(map[uint32]uint64)
+	for _, traf := range f.Moof.Trafs {
+		if traf.Tfdt != nil {
+			times[traf.Tfhd.TrackID] = traf.Tfdt.BaseMediaDecodeTime
+		}
+	}
+	return times
+}
+
+// EarliestPresentationTime - earliest presentation time of the samples of the trex track (first track if trex is nil)
+// in the fragment. Together with PresentationEndTime, it gives the full presentation time range.
+// Returns 0 if the track has no tfdt or no samples in the fragment.
+func (f *Fragment) EarliestPresentationTime(trex *TrexBox) uint64 {
+	start, _ := f.presentationTimeRange(trex)
+	return start
+}
+
+// PresentationEndTime - end of the presentation of the samples of the trex track (first track if trex is nil)
+// in the fragment, given by tfdt plus the sum of sample durations adjusted for composition time offsets.
+// This is the maximum of presentation time plus duration over all samples, so that reordered samples are handled.
+// Returns 0 if the track has no tfdt or no samples in the fragment.
+func (f *Fragment) PresentationEndTime(trex *TrexBox) uint64 {
+	_, end := f.presentationTimeRange(trex)
+	return end
+}
+
+// SAPDeltaTime - SAP_delta_time for a sidx reference to the fragment, which is the presentation time of the first
+// sync sample in decode order (T_SAP) minus the earliest presentation time of the samples of the trex track
+// (first track if trex is nil). This is zero for closed GOPs and positive for open GOPs with leading pictures
+// that are presented before the sync sample. Returns 0 if the track has no tfdt or no sync sample in the fragment.
+func (f *Fragment) SAPDeltaTime(trex *TrexBox) uint32 {
+	if f.Moof == nil {
+		return 0
+	}
+	traf := f.trafForTrex(trex)
+	if traf == nil || traf.Tfdt == nil {
+		return 0
+	}
+	decTime := int64(traf.Tfdt.BaseMediaDecodeTime)
+	foundSAP := false
+	var sapTime int64
+	for _, trun := range traf.Truns {
+		trun.AddSampleDefaultValues(traf.Tfhd, trex)
+		for _, s := range trun.Samples {
+			if s.IsSync() {
+				sapTime = decTime + int64(s.CompositionTimeOffset)
+				foundSAP = true
+				break
+			}
+			decTime += int64(s.Dur)
+		}
+		if foundSAP {
+			break
+		}
+	}
+	if !foundSAP {
+		return 0
+	}
+	start, _ := f.presentationTimeRange(trex)
+	if sapTime <= int64(start) {
+		return 0
+	}
+	return uint32(sapTime - int64(start))
+}
+
+// presentationTimeRange - earliest presentation time and presentation end time of a track in the fragment
+func (f *Fragment) presentationTimeRange(trex *TrexBox) (start, end uint64) {
+	if f.Moof == nil {
+		return 0, 0
+	}
+	traf := f.trafForTrex(trex)
+	if traf == nil {
+		return 0, 0
+	}
+	minPT, maxEnd, ok := traf.presentationTimeRange(trex)
+	if !ok {
+		return 0, 0
+	}
+	if minPT < 0 {
+		minPT = 0
+	}
+	if maxEnd < 0 {
+		maxEnd = 0
+	}
+	return uint64(minPT), uint64(maxEnd)
+}
+
+// SetAllBaseMediaDecodeTimes - set tfdt baseMediaDecodeTime for the tracks given by times keyed by trackID.
+// Tracks without traf or tfdt in the fragment are left unchanged. A tfdt is changed to version 1 if needed,
+// but never to version 0. If the moof size changes, the tfhd, trun, and saio data offsets are adjusted accordingly.
+func (f *Fragment) SetAllBaseMediaDecodeTimes(times map[uint32]uint64) {
+	oldSize := f.Moof.Size()
+	for _, traf := range f.Moof.Trafs {
+		bTime, ok := times[traf.Tfhd.TrackID]
+		if !ok || traf.Tfdt == nil {
+			continue
+		}
+		if bTime > math.MaxUint32 {
+			traf.Tfdt.Version = 1
+		}
+		traf.Tfdt.BaseMediaDecodeTime = bTime
+	}
+	f.moveDataOffsets(int32(f.Moof.Size() - oldSize))
+}
+
+// growMoof - move the data offsets of a decoded fragment whose moof grew by sizeDiff, and its decoded positions
+// so that the sample data can still be read. An mdat after the moof moves with its end, while an mdat before
+// the moof stays in place and the moof is instead considered to start sizeDiff bytes earlier.
+func (f *Fragment) growMoof(sizeDiff int32) {
+	f.moveDataOffsets(sizeDiff)
+	if f.Mdat == nil {
+		return
+	}
+	if f.Mdat.StartPos > f.Moof.StartPos {
+		f.Mdat.StartPos = uint64(int64(f.Mdat.StartPos) + int64(sizeDiff))
+	} else {
+		f.Moof.StartPos = uint64(int64(f.Moof.StartPos) - int64(sizeDiff))
+	}
+}
+
+// moveDataOffsets - adjust data offsets after the moof size changed by sizeDiff, so that they point to the same data.
+// An explicit tfhd base_data_offset is moved, and otherwise the trun data offsets relative to the moof start.
+// saio offsets to senc data in the moof are recomputed, and other saio offsets are moved like the sample data.
+func (f *Fragment) moveDataOffsets(sizeDiff int32) {
+	if sizeDiff == 0 {
+		return
+	}
+	moof := f.Moof
+	for _, traf := range moof.Trafs {
+		tfhd := traf.Tfhd
+		if tfhd.HasBaseDataOffset() {
+			tfhd.BaseDataOffset = uint64(int64(tfhd.BaseDataOffset) + int64(sizeDiff))
+		} else {
+			for _, trun := range traf.Truns {
+				if trun.HasDataOffset() {
+					trun.DataOffset += sizeDiff
+				}
+			}
+		}
+		if traf.Saio == nil || len(traf.Saio.Offset) != 1 {
+			continue
+		}
+		switch {
+		case traf.Senc != nil:
+			offset := int64(sencAuxDataOffsetInMoof(moof, traf))
+			if tfhd.HasBaseDataOffset() {
+				offset += int64(moof.StartPos) - int64(tfhd.BaseDataOffset)
+			}
+			traf.Saio.Offset[0] = offset
+		case !tfhd.HasBaseDataOffset():
+			traf.Saio.Offset[0] += int64(sizeDiff)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package mp4
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestSampleByteRanges(t *testing.T) {
+	sampleData := [][]byte{{0x01, 0x02, 0x03}, {0x04}, {0x05, 0x06}}
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, data := range sampleData {
+		frag.AddFullSample(FullSample{
+			Sample:     NewSample(SyncSampleFlags, 1000, uint32(len(data)), 0),
+			DecodeTime: uint64(i * 1000),
+			Data:       data,
+		})
+	}
+	prefix := make([]byte, 100) // Position moof inside file
+	var buf bytes.Buffer
+	buf.Write(prefix)
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileData := buf.Bytes()
+	moofStart := uint64(len(prefix))
+	box, err := DecodeBox(moofStart, bytes.NewBuffer(fileData[moofStart:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	moof := box.(*MoofBox)
+	if !moof.Traf.Tfhd.DefaultBaseIfMoof() {
+		t.Errorf("expected default-base-is-moof")
+	}
+	decFrag := NewFragment()
+	decFrag.AddChild(moof)
+	ranges, err := decFrag.SampleByteRanges(moofStart, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != len(sampleData) {
+		t.Fatalf("got %d ranges instead of %d", len(ranges), len(sampleData))
+	}
+	wantedFirstOffset := moofStart + moof.Size() + frag.Mdat.HeaderSize()
+	if ranges[0].Offset != wantedFirstOffset {
+		t.Errorf("got first offset %d instead of %d", ranges[0].Offset, wantedFirstOffset)
+	}
+	for i, r := range ranges {
+		if r.SampleNr != uint32(i+1) {
+			t.Errorf("got sampleNr %d instead of %d", r.SampleNr, i+1)
+		}
+		data := fileData[r.Offset : r.Offset+uint64(r.Size)]
+		if !bytes.Equal(data, sampleData[i]) {
+			t.Errorf("sample %d: got data %x instead of %x", i+1, data, sampleData[i])
+		}
+	}
+}
+
+func TestSetAllBaseMediaDecodeTimes(t *testing.T) {
+	frag, err := CreateMultiTrackFragment(1, []uint32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sampleData := map[uint32][]byte{1: {0x01, 0x02, 0x03}, 2: {0x04, 0x05}}
+	for _, trackID := range []uint32{1, 2} {
+		data := sampleData[trackID]
+		err = frag.AddFullSampleToTrack(FullSample{
+			Sample:     NewSample(SyncSampleFlags, 1000, uint32(len(data)), 0),
+			DecodeTime: 1000,
+			Data:       data,
+		}, trackID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err = frag.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFrag := f.Segments[0].Fragments[0]
+	times := map[uint32]uint64{1: 90000, 2: 1 << 33}
+	decFrag.SetAllBaseMediaDecodeTimes(times)
+	gotTimes := decFrag.BaseMediaDecodeTimes()
+	for trackID, bTime := range times {
+		if gotTimes[trackID] != bTime {
+			t.Errorf("track %d: got baseMediaDecodeTime %d instead of %d", trackID, gotTimes[trackID], bTime)
+		}
+	}
+
+	buf.Reset()
+	if err = decFrag.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err = DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFrag = f.Segments[0].Fragments[0]
+	for trackID, data := range sampleData {
+		samples, err := decFrag.GetFullSamples(&TrexBox{TrackID: trackID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(samples) != 1 || samples[0].DecodeTime != times[trackID] || !bytes.Equal(samples[0].Data, data) {
+			t.Errorf("track %d: sample time or data not correct after setting tfdt", trackID)
+		}
+	}
+}
+
+func TestSetAllBaseMediaDecodeTimesMovesOffsets(t *testing.T) {
+	// Explicit base data offsets
+	frag, err := CreateMultiTrackFragment(1, []uint32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sampleData := map[uint32][]byte{1: {0x01, 0x02, 0x03}, 2: {0x04, 0x05}}
+	for _, trackID := range []uint32{1, 2} {
+		data := sampleData[trackID]
+		err = frag.AddFullSampleToTrack(FullSample{
+			Sample:     NewSample(SyncSampleFlags, 1000, uint32(len(data)), 0),
+			DecodeTime: 1000,
+			Data:       data,
+		}, trackID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	frag.DataOffsetMode = DataOffsetExplicitBase
+	var buf bytes.Buffer
+	if err = frag.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFrag := f.Segments[0].Fragments[0]
+	decFrag.SetAllBaseMediaDecodeTimes(map[uint32]uint64{1: 1 << 33, 2: 1 << 33})
+	buf.Reset()
+	if err = decFrag.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = DecodeFile(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decFrag = f.Segments[0].Fragments[0]
+	for trackID, data := range sampleData {
+		samples, err := decFrag.GetFullSamples(&TrexBox{TrackID: trackID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(samples) != 1 || !bytes.Equal(samples[0].Data, data) {
+			t.Errorf("track %d: sample data not correct with explicit base data offset", trackID)
+		}
+	}
+
+	// saio offsets to senc in encrypted fragments
+	fd, err := os.Open("testdata/prog_8s_enc_dashinit.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	if f, err = DecodeFile(fd); err != nil {
+		t.Fatal(err)
+	}
+	decFrag = f.Segments[0].Fragments[0]
+	times := decFrag.BaseMediaDecodeTimes()
+	for trackID := range times {
+		times[trackID] += 1 << 33
+	}
+	decFrag.SetAllBaseMediaDecodeTimes(times)
+	buf.Reset()
+	if err = f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = DecodeFile(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if issues := ValidateEncryption(f); len(issues) != 0 {
+		t.Errorf("got encryption issues %v", issues)
+	}
+}
+
+func TestDataOffsetMode(t *testing.T) {
+	sampleData := map[uint32][][]byte{1: {{0x01, 0x02, 0x03}, {0x04}}, 2: {{0x05, 0x06}}}
+	free := &FreeBox{Name: "free", notDecoded: make([]byte, 92)}
+	modes := []DataOffsetMode{DataOffsetUnchanged, DataOffsetDefaultBaseIsMoof, DataOffsetExplicitBase,
+		DataOffsetMdatRelative}
+	for _, mode := range modes {
+		frag, err := CreateMultiTrackFragment(1, []uint32{1, 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, trackID := range []uint32{1, 2} {
+			for i, data := range sampleData[trackID] {
+				err = frag.AddFullSampleToTrack(FullSample{
+					Sample:     NewSample(SyncSampleFlags, 1000, uint32(len(data)), 0),
+					DecodeTime: uint64(i * 1000),
+					Data:       data,
+				}, trackID)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		frag.DataOffsetMode = mode
+		frag.Moof.StartPos = free.Size()
+		var buf bytes.Buffer
+		if err = free.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if err = frag.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		f, err := DecodeFile(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decFrag := f.Segments[0].Fragments[0]
+		for _, traf := range decFrag.Moof.Trafs {
+			tfhd := traf.Tfhd
+			switch mode {
+			case DataOffsetDefaultBaseIsMoof:
+				if !tfhd.DefaultBaseIfMoof() || tfhd.HasBaseDataOffset() {
+					t.Errorf("%s: wrong tfhd flags %06x", mode, tfhd.Flags)
+				}
+			case DataOffsetExplicitBase, DataOffsetMdatRelative:
+				if tfhd.DefaultBaseIfMoof() || !tfhd.HasBaseDataOffset() {
+					t.Errorf("%s: wrong tfhd flags %06x", mode, tfhd.Flags)
+				}
+			}
+		}
+		for trackID, datas := range sampleData {
+			samples, err := decFrag.GetFullSamples(&TrexBox{TrackID: trackID})
+			if err != nil {
+				t.Fatalf("%s: %s", mode, err)
+			}
+			if len(samples) != len(datas) {
+				t.Fatalf("%s: track %d: got %d samples instead of %d", mode, trackID, len(samples), len(datas))
+			}
+			for i, s := range samples {
+				if !bytes.Equal(s.Data, datas[i]) || s.DecodeTime != uint64(i*1000) {
+					t.Errorf("%s: track %d: sample %d not correct", mode, trackID, i+1)
+				}
+			}
+		}
+	}
+}
+
+func TestDataOffsetModeDecodedFragment(t *testing.T) {
+	sampleData := map[uint32][][]byte{1: {{0x01, 0x02, 0x03}, {0x04}}, 2: {{0x05, 0x06}}}
+	frag, err := CreateMultiTrackFragment(1, []uint32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trackID := range []uint32{1, 2} {
+		for i, data := range sampleData[trackID] {
+			err = frag.AddFullSampleToTrack(FullSample{
+				Sample:     NewSample(SyncSampleFlags, 1000, uint32(len(data)), 0),
+				DecodeTime: uint64(i * 1000),
+				Data:       data,
+			}, trackID)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	seg := NewMediaSegment()
+	seg.AddFragment(frag)
+	var segBuf bytes.Buffer
+	if err = seg.Encode(&segBuf); err != nil {
+		t.Fatal(err)
+	}
+	free := &FreeBox{Name: "free", notDecoded: make([]byte, 92)}
+	for _, mode := range []DataOffsetMode{DataOffsetDefaultBaseIsMoof, DataOffsetExplicitBase, DataOffsetMdatRelative} {
+		f, err := DecodeFile(bytes.NewReader(segBuf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Encode the decoded segment on its own after a free box
+		decSeg := f.Segments[0]
+		decSeg.Fragments[0].DataOffsetMode = mode
+		decSeg.StartPos = free.Size()
+		var buf bytes.Buffer
+		if err = free.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if err = decSeg.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		f, err = DecodeFile(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decFrag := f.Segments[0].Fragments[0]
+		for _, traf := range decFrag.Moof.Trafs {
+			if mode == DataOffsetDefaultBaseIsMoof != traf.Tfhd.DefaultBaseIfMoof() ||
+				mode == DataOffsetDefaultBaseIsMoof == traf.Tfhd.HasBaseDataOffset() {
+				t.Errorf("%s: wrong tfhd flags %06x", mode, traf.Tfhd.Flags)
+			}
+		}
+		for trackID, datas := range sampleData {
+			samples, err := decFrag.GetFullSamples(&TrexBox{TrackID: trackID})
+			if err != nil {
+				t.Fatalf("%s: %s", mode, err)
+			}
+			if len(samples) != len(datas) {
+				t.Fatalf("%s: track %d: got %d samples instead of %d", mode, trackID, len(samples), len(datas))
+			}
+			for i, s := range samples {
+				if !bytes.Equal(s.Data, datas[i]) {
+					t.Errorf("%s: track %d: sample %d not correct", mode, trackID, i+1)
+				}
+			}
+		}
+	}
+}
+
+func TestPresentationEndTime(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// I P B B with composition time offsets for reordering
+	durs := []uint32{3000, 3000, 3000, 3000}
+	ctos := []int32{3000, 9000, 0, 0}
+	decTime := uint64(90000)
+	for i, dur := range durs {
+		frag.AddFullSample(FullSample{
+			Sample:     NewSample(SyncSampleFlags, dur, 1, ctos[i]),
+			DecodeTime: decTime + uint64(i)*3000,
+			Data:       []byte{0},
+		})
+	}
+	trex := &TrexBox{TrackID: 1}
+	if start := frag.EarliestPresentationTime(trex); start != 93000 {
+		t.Errorf("got earliest presentation time %d instead of 93000", start)
+	}
+	if end := frag.PresentationEndTime(trex); end != 105000 {
+		t.Errorf("got presentation end time %d instead of 105000", end)
+	}
+}
+
+func TestSAPDeltaTime(t *testing.T) {
+	cases := []struct {
+		desc      string
+		flags     []uint32
+		ctos      []int32
+		wantDelta uint32
+	}{
+		{
+			// Open GOP: I with two leading B pictures presented before it, then P B B
+			desc:      "open GOP",
+			flags:     []uint32{SyncSampleFlags, NonSyncSampleFlags, NonSyncSampleFlags, NonSyncSampleFlags},
+			ctos:      []int32{6000, -3000, -3000, 6000},
+			wantDelta: 6000,
+		},
+		{
+			// Closed GOP: I P B B
+			desc:      "closed GOP",
+			flags:     []uint32{SyncSampleFlags, NonSyncSampleFlags, NonSyncSampleFlags, NonSyncSampleFlags},
+			ctos:      []int32{3000, 9000, 0, 0},
+			wantDelta: 0,
+		},
+		{
+			desc:      "no sync sample",
+			flags:     []uint32{NonSyncSampleFlags, NonSyncSampleFlags},
+			ctos:      []int32{3000, 0},
+			wantDelta: 0,
+		},
+	}
+	for _, c := range cases {
+		frag, err := CreateFragment(1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, flags := range c.flags {
+			frag.AddFullSample(FullSample{
+				Sample:     NewSample(flags, 3000, 1, c.ctos[i]),
+				DecodeTime: 90000 + uint64(i)*3000,
+				Data:       []byte{0},
+			})
+		}
+		if delta := frag.SAPDeltaTime(&TrexBox{TrackID: 1}); delta != c.wantDelta {
+			t.Errorf("%s: got SAP delta time %d instead of %d", c.desc, delta, c.wantDelta)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package mp4
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// ITunesGaplessInfo - encoder delay (priming), padding, and total number of audio frames (PCM samples per channel)
+// from the iTunSMPB freeform item (----) in the moov/udta/meta/ilst box, as written by Apple AAC encoders.
+// The item value is a string of space-separated hex numbers, where the second, third, and fourth
+// numbers are the encoder delay, the padding, and the original number of samples.
+// Players can trim the decoded audio with these values without an edit list.
+// ok is false if there is no valid iTunSMPB item.
+func (f *File) ITunesGaplessInfo() (encoderDelay, padding, totalFrames uint64, ok bool) {
+	if f.Moov == nil {
+		return 0, 0, 0, false
+	}
+	for _, ilst := range moovIlsts(f.Moov) {
+		for _, item := range ilst.Children {
+			unknown, isUnknown := item.(*UnknownBox)
+			if !isUnknown || item.Type() != "----" {
+				continue
+			}
+			name, value, found := parseFreeformItem(unknown.notDecoded)
+			if !found || name != "iTunSMPB" {
+				continue
+			}
+			return parseITunSMPB(string(value))
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// moovIlsts - ilst boxes in meta boxes in udta boxes directly in moov
+func moovIlsts(moov *MoovBox) []*IlstBox {
+	var ilsts []*IlstBox
+	for _, c := range moov.Children {
+		udta, ok := c.(*UdtaBox)
+		if !ok {
+			continue
+		}
+		for _, uc := range udta.Children {
+			meta, ok := uc.(*MetaBox)
+			if !ok {
+				continue
+			}
+			for _, mc := range meta.Children {
+				if ilst, ok := mc.(*IlstBox); ok {
+					ilsts = append(ilsts, ilst)
+				}
+			}
+		}
+	}
+	return ilsts
+}
+
+// parseFreeformItem - name and value of a freeform item with mean, name, and data child boxes.
+// The name and data boxes start with version and flags, and the data box also with a 4-byte locale.
+func parseFreeformItem(payload []byte) (name string, value []byte, ok bool) {
+	sr := bits.NewFixedSliceReader(payload)
+	foundName, foundData := false, false
+	for sr.NrRemainingBytes() >= boxHeaderSize {
+		size := int(sr.ReadUint32())
+		boxType := sr.ReadFixedLengthString(4)
+		if size < boxHeaderSize || size-boxHeaderSize > sr.NrRemainingBytes() {
+			return "", nil, false
+		}
+		body := sr.ReadBytes(size - boxHeaderSize)
+		switch boxType {
+		case "name":
+			if len(body) >= 4 {
+				name, foundName = string(body[4:]), true
+			}
+		case "data":
+			if len(body) >= 8 {
+				value, foundData = body[8:], true
+			}
+		}
+	}
+	return name, value, foundName && foundData && sr.AccError() == nil
+}
+
+// parseITunSMPB - encoder delay, padding, and total number of frames from an iTunSMPB string
+func parseITunSMPB(smpb string) (encoderDelay, padding, totalFrames uint64, ok bool) {
+	fields := strings.Fields(smpb)
+	if len(fields) < 4 {
+		return 0, 0, 0, false
+	}
+	var values [3]uint64
+	for i := range values {
+		v, err := strconv.ParseUint(fields[i+1], 16, 64)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		values[i] = v
+	}
+	return values[0], values[1], values[2], true
+}
@@ -0,0 +1,81 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// freeformItemBox - ---- box with mean, name, and data boxes with a UTF-8 value
+func freeformItemBox(t *testing.T, name, value string) Box {
+	t.Helper()
+	mean := "com.apple.iTunes"
+	size := 8 + (12 + len(mean)) + (12 + len(name)) + (16 + len(value))
+	sw := bits.NewFixedSliceWriter(size)
+	sw.WriteUint32(uint32(size))
+	sw.WriteString("----", false)
+	sw.WriteUint32(uint32(12 + len(mean)))
+	sw.WriteString("mean", false)
+	sw.WriteUint32(0)
+	sw.WriteString(mean, false)
+	sw.WriteUint32(uint32(12 + len(name)))
+	sw.WriteString("name", false)
+	sw.WriteUint32(0)
+	sw.WriteString(name, false)
+	sw.WriteUint32(uint32(16 + len(value)))
+	sw.WriteString("data", false)
+	sw.WriteUint32(1) // UTF-8
+	sw.WriteUint32(0) // locale
+	sw.WriteString(value, false)
+	box, err := DecodeBox(0, bytes.NewBuffer(sw.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return box
+}
+
+func TestITunesGaplessInfo(t *testing.T) {
+	smpb := " 00000000 00000840 000001CA 00000000003F31F6 00000000 00000000 00000000 00000000" +
+		" 00000000 00000000 00000000 00000000"
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(44100, "audio", "und")
+	hdlr, err := CreateHdlr("mdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := CreateMetaBox(0, hdlr)
+	ilst := &IlstBox{}
+	ilst.AddChild(freeformItemBox(t, "iTunNORM", " 00000000 00000000"))
+	ilst.AddChild(freeformItemBox(t, "iTunSMPB", smpb))
+	meta.AddChild(ilst)
+	udta := &UdtaBox{}
+	udta.AddChild(meta)
+	init.Moov.AddChild(udta)
+	var buf bytes.Buffer
+	if err = init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delay, padding, totalFrames, ok := f.ITunesGaplessInfo()
+	if !ok {
+		t.Fatalf("no gapless info found")
+	}
+	if delay != 2112 || padding != 458 || totalFrames != 4141558 {
+		t.Errorf("got delay %d, padding %d, totalFrames %d instead of 2112, 458, 4141558", delay, padding, totalFrames)
+	}
+
+	f, err = ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, ok = f.ITunesGaplessInfo(); ok {
+		t.Errorf("expected no gapless info without iTunSMPB item")
+	}
+	if _, _, _, ok = parseITunSMPB(" 00000000 00000840 zz 00000000003F31F6"); ok {
+		t.Errorf("expected bad iTunSMPB string to fail")
+	}
+}
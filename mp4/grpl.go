@@ -0,0 +1,192 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// GrplBox - Groups List Box (grpl) ISO/IEC 14496-12 Ed. 6 2020 Section 8.18.3
+//
+// Contained in : Meta Box (meta)
+//
+// All children are EntityToGroupBoxes with the grouping type as box type,
+// for example altr (alternatives) or ster (stereo pair in HEIF).
+type GrplBox struct {
+	Groups   []*EntityToGroupBox
+	Children []Box
+}
+
+// EntityGroup - grouping type, group ID, and entity IDs of an entity group
+type EntityGroup struct {
+	GroupingType string
+	GroupID      uint32
+	EntityIDs    []uint32
+}
+
+// DecodeGrpl - box-specific decode
+func DecodeGrpl(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeGrplSR(hdr, startPos, sr)
+}
+
+// DecodeGrplSR - box-specific decode
+func DecodeGrplSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := &GrplBox{}
+	pos := startPos + uint64(hdr.Hdrlen)
+	endPos := startPos + hdr.Size
+	for pos < endPos {
+		childHdr, err := DecodeHeaderSR(sr)
+		if err != nil {
+			return nil, fmt.Errorf("decode grpl: %w", err)
+		}
+		if childHdr.Size < uint64(childHdr.Hdrlen) || pos+childHdr.Size > endPos {
+			return nil, fmt.Errorf("decode grpl: bad size %d of %s box", childHdr.Size, childHdr.Name)
+		}
+		child, err := decodeEntityToGroupSR(childHdr, sr)
+		if err != nil {
+			return nil, fmt.Errorf("decode grpl: %w", err)
+		}
+		b.AddChild(child)
+		pos += childHdr.Size
+	}
+	return b, sr.AccError()
+}
+
+// AddChild - add an EntityToGroupBox
+func (b *GrplBox) AddChild(child Box) {
+	if group, ok := child.(*EntityToGroupBox); ok {
+		b.Groups = append(b.Groups, group)
+	}
+	b.Children = append(b.Children, child)
+}
+
+// Type - box type
+func (b *GrplBox) Type() string {
+	return "grpl"
+}
+
+// Size - calculated size of box
+func (b *GrplBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *GrplBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write grpl container to w
+func (b *GrplBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write grpl container to sw
+func (b *GrplBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *GrplBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
+
+// EntityToGroupBox - Entity To Group Box ISO/IEC 14496-12 Ed. 6 2020 Section 8.18.3
+//
+// Contained in : Groups List Box (grpl)
+//
+// The box type is the grouping type. Extra holds any grouping-type specific data after the entity IDs.
+type EntityToGroupBox struct {
+	GroupingType string
+	Version      byte
+	Flags        uint32
+	GroupID      uint32
+	EntityIDs    []uint32
+	Extra        []byte
+}
+
+// CreateEntityToGroupBox - create an entity group of groupingType such as altr or ster
+func CreateEntityToGroupBox(groupingType string, groupID uint32, entityIDs []uint32) *EntityToGroupBox {
+	return &EntityToGroupBox{GroupingType: groupingType, GroupID: groupID, EntityIDs: entityIDs}
+}
+
+// decodeEntityToGroupSR - decode the payload of an EntityToGroupBox with header hdr
+func decodeEntityToGroupSR(hdr BoxHeader, sr bits.SliceReader) (*EntityToGroupBox, error) {
+	payloadLen := hdr.payloadLen()
+	if payloadLen < 12 {
+		return nil, fmt.Errorf("%s box payload %d bytes too short", hdr.Name, payloadLen)
+	}
+	versionAndFlags := sr.ReadUint32()
+	b := &EntityToGroupBox{
+		GroupingType: hdr.Name,
+		Version:      byte(versionAndFlags >> 24),
+		Flags:        versionAndFlags & flagsMask,
+		GroupID:      sr.ReadUint32(),
+	}
+	nrEntities := int(sr.ReadUint32())
+	if 12+4*nrEntities > payloadLen {
+		return nil, fmt.Errorf("%s box with %d entities does not fit in %d bytes", hdr.Name, nrEntities, payloadLen)
+	}
+	b.EntityIDs = make([]uint32, nrEntities)
+	for i := range b.EntityIDs {
+		b.EntityIDs[i] = sr.ReadUint32()
+	}
+	if extraLen := payloadLen - 12 - 4*nrEntities; extraLen > 0 {
+		b.Extra = sr.ReadBytes(extraLen)
+	}
+	return b, sr.AccError()
+}
+
+// Type - box type, which is the grouping type
+func (b *EntityToGroupBox) Type() string {
+	return b.GroupingType
+}
+
+// Size - calculated size of box
+func (b *EntityToGroupBox) Size() uint64 {
+	return uint64(boxHeaderSize + 12 + 4*len(b.EntityIDs) + len(b.Extra))
+}
+
+// Encode - write box to w
+func (b *EntityToGroupBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *EntityToGroupBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint32(b.GroupID)
+	sw.WriteUint32(uint32(len(b.EntityIDs)))
+	for _, id := range b.EntityIDs {
+		sw.WriteUint32(id)
+	}
+	sw.WriteBytes(b.Extra)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *EntityToGroupBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - groupID: %d", b.GroupID)
+	bd.write(" - entityIDs: %v", b.EntityIDs)
+	if len(b.Extra) > 0 {
+		bd.write(" - extra: %d bytes", len(b.Extra))
+	}
+	return bd.err
+}
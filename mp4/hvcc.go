@@ -46,6 +46,52 @@ func DecodeHvcCSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	return &HvcCBox{hevcDecConfRec}, err
 }
 
+// SetVPS - replace all VPS NAL units. An empty list removes the VPS array
+func (b *HvcCBox) SetVPS(vpsList [][]byte) {
+	b.setNalus(hevc.NALU_VPS, vpsList)
+}
+
+// SetSPS - replace all SPS NAL units. An empty list removes the SPS array
+func (b *HvcCBox) SetSPS(spsList [][]byte) {
+	b.setNalus(hevc.NALU_SPS, spsList)
+}
+
+// SetPPS - replace all PPS NAL units. An empty list removes the PPS array
+func (b *HvcCBox) SetPPS(ppsList [][]byte) {
+	b.setNalus(hevc.NALU_PPS, ppsList)
+}
+
+// setNalus - replace nalus in array of naluType keeping its complete flag.
+// A new array is marked complete and inserted in VPS, SPS, PPS order.
+func (b *HvcCBox) setNalus(naluType hevc.NaluType, nalus [][]byte) {
+	arrays := b.NaluArrays
+	for i := range arrays {
+		if arrays[i].NaluType() != naluType {
+			continue
+		}
+		if len(nalus) == 0 {
+			b.NaluArrays = append(arrays[:i], arrays[i+1:]...)
+			return
+		}
+		arrays[i].Nalus = nalus
+		return
+	}
+	if len(nalus) == 0 {
+		return
+	}
+	pos := len(arrays)
+	for i := range arrays {
+		if arrays[i].NaluType() > naluType {
+			pos = i
+			break
+		}
+	}
+	newArray := hevc.NewNaluArray(true, naluType, nalus)
+	b.NaluArrays = append(arrays, hevc.NaluArray{})
+	copy(b.NaluArrays[pos+1:], b.NaluArrays[pos:])
+	b.NaluArrays[pos] = *newArray
+}
+
 // Type - return box type
 func (b *HvcCBox) Type() string {
 	return "hvcC"
@@ -1,8 +1,11 @@
 package mp4
 
 import (
+	"bytes"
 	"encoding/hex"
 	"testing"
+
+	"github.com/edgeware/mp4ff/hevc"
 )
 
 const (
@@ -31,3 +34,37 @@ func TestHvcC(t *testing.T) {
 	}
 	boxDiffAfterEncodeAndDecode(t, hvcC)
 }
+
+func TestHvcCSetParameterSets(t *testing.T) {
+	vpsNalu, _ := hex.DecodeString(vpsHex)
+	spsNalu, _ := hex.DecodeString(spsHex)
+	ppsNalu, _ := hex.DecodeString(ppsHex)
+	hvcC, err := CreateHvcC([][]byte{vpsNalu}, [][]byte{spsNalu}, [][]byte{ppsNalu}, true, false, true, true)
+	if err != nil {
+		t.Error(err)
+	}
+	origSize := hvcC.Size()
+	newSPS := append(append([]byte{}, spsNalu...), 0x80)
+	hvcC.SetSPS([][]byte{newSPS})
+	if hvcC.Size() != origSize+1 {
+		t.Errorf("got size %d instead of %d", hvcC.Size(), origSize+1)
+	}
+	decHvcC := boxAfterEncodeAndDecode(t, hvcC).(*HvcCBox)
+	spss := decHvcC.GetNalusForType(hevc.NALU_SPS)
+	if len(spss) != 1 || !bytes.Equal(spss[0], newSPS) {
+		t.Errorf("SPS not replaced")
+	}
+	if decHvcC.NaluArrays[1].Complete() != 0 {
+		t.Errorf("SPS complete flag not kept")
+	}
+
+	hvcC.SetVPS(nil)
+	if len(hvcC.NaluArrays) != 2 || hvcC.NaluArrays[0].NaluType() != hevc.NALU_SPS {
+		t.Errorf("VPS array not removed")
+	}
+	hvcC.SetVPS([][]byte{vpsNalu})
+	if len(hvcC.NaluArrays) != 3 || hvcC.NaluArrays[0].NaluType() != hevc.NALU_VPS {
+		t.Errorf("VPS array not inserted first")
+	}
+	boxDiffAfterEncodeAndDecode(t, hvcC)
+}
@@ -0,0 +1,128 @@
+package mp4
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// IacbBox - IAConfigurationBox (iacb) for Immersive Audio (IAMF)
+// Defined in AOM Immersive Audio Model and Formats (IAMF) v1.0.0 Section 6.2.4
+//
+// Contained in : IA Sample Entry (iamf)
+//
+// The descriptor OBUs (IA Sequence Header, Codec Config, Audio Element, and Mix Presentation)
+// are kept as raw bytes.
+type IacbBox struct {
+	ConfigurationVersion byte
+	ConfigOBUs           []byte
+}
+
+// CreateIacb - create iacb box with configurationVersion 1 and given descriptor OBUs
+func CreateIacb(configOBUs []byte) *IacbBox {
+	return &IacbBox{ConfigurationVersion: 1, ConfigOBUs: configOBUs}
+}
+
+// DecodeIacb - box-specific decode
+func DecodeIacb(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeIacbSR(hdr, startPos, sr)
+}
+
+// DecodeIacbSR - box-specific decode
+func DecodeIacbSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := IacbBox{}
+	b.ConfigurationVersion = sr.ReadUint8()
+	size, nrBytes := readLeb128(sr)
+	if sr.AccError() != nil {
+		return nil, fmt.Errorf("decode iacb: %w", sr.AccError())
+	}
+	if size != uint64(hdr.payloadLen()-1-nrBytes) {
+		return nil, fmt.Errorf("decode iacb: configOBUs size %d does not match box size", size)
+	}
+	b.ConfigOBUs = sr.ReadBytes(int(size))
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *IacbBox) Type() string {
+	return "iacb"
+}
+
+// Size - return calculated size
+func (b *IacbBox) Size() uint64 {
+	return uint64(boxHeaderSize + 1 + leb128Size(uint64(len(b.ConfigOBUs))) + len(b.ConfigOBUs))
+}
+
+// Encode - write box to w
+func (b *IacbBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *IacbBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint8(b.ConfigurationVersion)
+	writeLeb128(sw, uint64(len(b.ConfigOBUs)))
+	sw.WriteBytes(b.ConfigOBUs)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *IacbBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - configurationVersion: %d", b.ConfigurationVersion)
+	bd.write(" - configOBUs: %d bytes", len(b.ConfigOBUs))
+	level := getInfoLevel(b, specificBoxLevels)
+	if level > 0 {
+		bd.write(" - configOBUs: %s", hex.EncodeToString(b.ConfigOBUs))
+	}
+	return bd.err
+}
+
+// readLeb128 - read unsigned LEB128 value (at most 8 bytes) and return value and number of bytes read
+func readLeb128(sr bits.SliceReader) (value uint64, nrBytes int) {
+	for i := 0; i < 8; i++ {
+		b := sr.ReadUint8()
+		value |= uint64(b&0x7f) << (7 * uint(i))
+		nrBytes++
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	return value, nrBytes
+}
+
+// leb128Size - number of bytes for minimal unsigned LEB128 encoding of value
+func leb128Size(value uint64) int {
+	size := 1
+	for value >= 0x80 {
+		value >>= 7
+		size++
+	}
+	return size
+}
+
+// writeLeb128 - write value as minimal unsigned LEB128
+func writeLeb128(sw bits.SliceWriter, value uint64) {
+	for value >= 0x80 {
+		sw.WriteUint8(byte(value&0x7f) | 0x80)
+		value >>= 7
+	}
+	sw.WriteUint8(byte(value))
+}
@@ -0,0 +1,53 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// iaSequenceHeaderOBUHex - IA Sequence Header OBU with ia_code "iamf" and simple profile
+const iaSequenceHeaderOBUHex = "f80669616d660000"
+
+func TestIacb(t *testing.T) {
+	configOBUs, _ := hex.DecodeString(iaSequenceHeaderOBUHex)
+	iacb := CreateIacb(configOBUs)
+	boxDiffAfterEncodeAndDecode(t, iacb)
+
+	// configOBUs_size needing more than one leb128 byte
+	boxDiffAfterEncodeAndDecode(t, CreateIacb(make([]byte, 300)))
+}
+
+func TestIamfInitSegment(t *testing.T) {
+	configOBUs, _ := hex.DecodeString(iaSequenceHeaderOBUHex)
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(48000, "audio", "und")
+	iamf := CreateAudioSampleEntryBox("iamf", 0, 16, 0, CreateIacb(configOBUs))
+	init.Moov.Trak.Mdia.Minf.Stbl.Stsd.AddChild(iamf)
+
+	var buf bytes.Buffer
+	err := init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encBytes := buf.Bytes()
+	f, err := DecodeFile(bytes.NewBuffer(encBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decEntry, ok := f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.Children[0].(*AudioSampleEntryBox)
+	if !ok || decEntry.Type() != "iamf" {
+		t.Fatalf("iamf sample entry not decoded")
+	}
+	if decEntry.Iacb == nil || !bytes.Equal(decEntry.Iacb.ConfigOBUs, configOBUs) {
+		t.Errorf("iacb configOBUs not decoded correctly")
+	}
+	buf.Reset()
+	err = f.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), encBytes) {
+		t.Errorf("IAMF init segment not identical after round-trip")
+	}
+}
@@ -68,6 +68,34 @@ func (s *InitSegment) EncodeSW(sw bits.SliceWriter) error {
 	return nil
 }
 
+// PrependTo - combine the init segment and seg into a self-initializing fragmented file
+// with the boxes ftyp, moov, styp, (sidx), moof, and mdat, usable as a combined first request.
+// The boxes are shared with s and seg and are not copied.
+func (s *InitSegment) PrependTo(seg *MediaSegment) *File {
+	f := NewFile()
+	f.isFragmented = true
+	f.Init = s
+	f.Ftyp = s.Ftyp
+	f.Moov = s.Moov
+	f.Children = append(f.Children, s.Children...)
+	for _, b := range s.Children {
+		if ainf, ok := b.(*AinfBox); ok {
+			f.Ainf = ainf
+		}
+	}
+	if seg.Styp != nil {
+		f.Children = append(f.Children, seg.Styp)
+	}
+	if seg.Sidx != nil {
+		f.Children = append(f.Children, seg.Sidx)
+	}
+	for _, frag := range seg.Fragments {
+		f.Children = append(f.Children, frag.Children...)
+	}
+	f.AddMediaSegment(seg)
+	return f
+}
+
 // Info - write box tree with indent for each level
 func (s *InitSegment) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	for _, box := range s.Children {
@@ -332,3 +360,13 @@ func (t *TrakBox) SetStppDescriptor(namespace, schemaLocation, auxiliaryMimeType
 	t.Mdia.Minf.Stbl.Stsd.AddChild(stpp)
 	return nil
 }
+
+// SetSbttDescriptor - add sbtt box for text subtitles with mimeFormat such as text/plain
+func (t *TrakBox) SetSbttDescriptor(contentEncoding, mimeFormat string) error {
+	if mimeFormat == "" {
+		return fmt.Errorf("sbtt mime format must not be empty")
+	}
+	sbtt := NewSbttBox(contentEncoding, mimeFormat)
+	t.Mdia.Minf.Stbl.Stsd.AddChild(sbtt)
+	return nil
+}
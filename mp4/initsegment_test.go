@@ -211,3 +211,55 @@ func TestGenerateInitSegment(t *testing.T) {
 		t.Errorf("Generated init segment different from %s", goldenAssetPath)
 	}
 }
+
+func TestPrependTo(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(48000, "audio", "und")
+	trex := init.Moov.Mvex.Trex
+	trex.DefaultSampleDuration = 1024
+	seg := CreateSingleSampleMediaSegment(trex.TrackID, 1, 0, trex, []byte{0x21, 0x10, 0x04}, 0)
+	var initBuf, segBuf bytes.Buffer
+	if err := init.Encode(&initBuf); err != nil {
+		t.Fatal(err)
+	}
+	if err := seg.Encode(&segBuf); err != nil {
+		t.Fatal(err)
+	}
+	expected := append(initBuf.Bytes(), segBuf.Bytes()...)
+
+	combined := init.PrependTo(seg)
+	for _, mode := range []EncFragFileMode{EncModeSegment, EncModeBoxTree} {
+		combined.FragEncMode = mode
+		var buf bytes.Buffer
+		if err := combined.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), expected) {
+			t.Errorf("mode %d: combined file differs from init and segment", mode)
+		}
+	}
+	wantedTypes := []string{"ftyp", "moov", "styp", "moof", "mdat"}
+	for i, box := range combined.Children {
+		if i >= len(wantedTypes) || box.Type() != wantedTypes[i] {
+			t.Fatalf("box %d: got %s, wanted order %v", i, box.Type(), wantedTypes)
+		}
+	}
+
+	f, err := DecodeFile(bytes.NewBuffer(expected))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.IsFragmented() || f.Init == nil || len(f.Segments) != 1 || len(f.Segments[0].Fragments) != 1 {
+		t.Fatalf("combined file does not decode to one init and one segment")
+	}
+	if diff := deep.Equal(f.Init.Moov.Trak.Mdia.Mdhd, init.Moov.Trak.Mdia.Mdhd); diff != nil {
+		t.Errorf("mdhd differs: %v", diff)
+	}
+	samples, err := f.Segments[0].Fragments[0].GetFullSamples(f.Init.Moov.Mvex.Trex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 1 || !bytes.Equal(samples[0].Data, []byte{0x21, 0x10, 0x04}) {
+		t.Errorf("wrong sample data in decoded segment")
+	}
+}
@@ -0,0 +1,72 @@
+package mp4
+
+import "fmt"
+
+// KeyframeRef - sync sample with presentation time and position of its data in the file
+type KeyframeRef struct {
+	SampleNr         uint32  // One-based sample number in the track
+	PresentationTime float64 // Seconds relative to the media time of the first non-empty edit
+	Offset           uint64  // Byte offset of the sample data in the file
+	Size             uint64  // Size of the sample data in bytes
+}
+
+// KeyframeIndex - presentation time and byte range in the file of each sync sample of trackID in a progressive
+// file, so that a client can fetch and decode just the keyframes, for example for scrubbing thumbnails.
+// Sync samples are given by stss, and all samples are sync samples if there is no stss box.
+func (f *File) KeyframeIndex(trackID uint32) ([]KeyframeRef, error) {
+	if f.isFragmented || f.Moov == nil {
+		return nil, fmt.Errorf("only available for progressive files")
+	}
+	trak := f.Moov.GetTrak(trackID)
+	if trak == nil {
+		return nil, fmt.Errorf("no track with trackID %d", trackID)
+	}
+	stbl := trak.Mdia.Minf.Stbl
+	if stbl.Stsz == nil || stbl.Stts == nil {
+		return nil, fmt.Errorf("no stsz or stts box")
+	}
+	if trak.Mdia.Mdhd.Timescale == 0 {
+		return nil, fmt.Errorf("zero timescale for track %d", trackID)
+	}
+	nrSamples := stbl.Stsz.GetNrSamples()
+	var nrSttsSamples uint32
+	for _, count := range stbl.Stts.SampleCount {
+		nrSttsSamples += count
+	}
+	var syncNrs []uint32
+	if stbl.Stss != nil {
+		syncNrs = stbl.Stss.SampleNumber
+	} else {
+		syncNrs = make([]uint32, nrSamples)
+		for i := range syncNrs {
+			syncNrs[i] = uint32(i + 1)
+		}
+	}
+	timescale := float64(trak.Mdia.Mdhd.Timescale)
+	mediaTime := trakMediaTime(trak)
+	refs := make([]KeyframeRef, 0, len(syncNrs))
+	for _, nr := range syncNrs {
+		if nr == 0 || nr > nrSamples {
+			return nil, fmt.Errorf("sync sample %d outside range 1-%d", nr, nrSamples)
+		}
+		if nr > nrSttsSamples {
+			return nil, fmt.Errorf("sync sample %d beyond the %d samples in stts", nr, nrSttsSamples)
+		}
+		decTime, _ := stbl.Stts.GetDecodeTime(nr)
+		presTime := int64(decTime) - mediaTime
+		if stbl.Ctts != nil {
+			presTime += int64(stbl.Ctts.GetCompositionTimeOffset(nr))
+		}
+		ranges, err := trak.GetRangesForSampleInterval(nr, nr)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, KeyframeRef{
+			SampleNr:         nr,
+			PresentationTime: float64(presTime) / timescale,
+			Offset:           ranges[0].Offset,
+			Size:             ranges[0].Size,
+		})
+	}
+	return refs, nil
+}
@@ -0,0 +1,71 @@
+package mp4
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"testing"
+)
+
+func TestKeyframeIndex(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trak := f.Moov.Traks[1]
+	stbl := trak.Mdia.Minf.Stbl
+	if stbl.Stss == nil {
+		t.Fatalf("video track has no stss")
+	}
+	refs, err := f.KeyframeIndex(trak.Tkhd.TrackID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != len(stbl.Stss.SampleNumber) {
+		t.Fatalf("got %d keyframes instead of %d", len(refs), len(stbl.Stss.SampleNumber))
+	}
+	timescale := float64(trak.Mdia.Mdhd.Timescale)
+	for i, ref := range refs {
+		nr := stbl.Stss.SampleNumber[i]
+		if ref.SampleNr != nr {
+			t.Errorf("keyframe %d: got sample %d instead of %d", i, ref.SampleNr, nr)
+		}
+		decTime, _ := stbl.Stts.GetDecodeTime(nr)
+		presTime := int64(decTime) - trakMediaTime(trak)
+		if stbl.Ctts != nil {
+			presTime += int64(stbl.Ctts.GetCompositionTimeOffset(nr))
+		}
+		if math.Abs(ref.PresentationTime-float64(presTime)/timescale) > 1e-9 {
+			t.Errorf("keyframe %d: got time %.3f", i, ref.PresentationTime)
+		}
+		if ref.Size != uint64(stbl.Stsz.GetSampleSize(int(nr))) {
+			t.Errorf("keyframe %d: got size %d instead of %d", i, ref.Size, stbl.Stsz.GetSampleSize(int(nr)))
+		}
+		sample := data[ref.Offset : ref.Offset+ref.Size]
+		expected, err := f.Mdat.ReadData(int64(ref.Offset), int64(ref.Size), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sample, expected) {
+			t.Errorf("keyframe %d: byte range does not match sample data", i)
+		}
+	}
+	if _, err = f.KeyframeIndex(17); err == nil {
+		t.Errorf("expected error for unknown track")
+	}
+	// Broken sample tables give errors
+	stts := stbl.Stts
+	stbl.Stts = &SttsBox{SampleCount: []uint32{1}, SampleTimeDelta: []uint32{1000}}
+	if _, err = f.KeyframeIndex(trak.Tkhd.TrackID); err == nil {
+		t.Errorf("expected error for sync samples beyond stts")
+	}
+	stbl.Stts = stts
+	trak.Mdia.Mdhd.Timescale = 0
+	if _, err = f.KeyframeIndex(trak.Tkhd.TrackID); err == nil {
+		t.Errorf("expected error for zero timescale")
+	}
+}
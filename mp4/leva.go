@@ -0,0 +1,175 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// Level assignment types in leva box
+const (
+	LevaAssignmentSampleGroup          = 0 // Level given by sample group of grouping type
+	LevaAssignmentSampleGroupParameter = 1 // Level given by sample group of grouping type and parameter
+	LevaAssignmentTrack                = 2 // Level given by track
+	LevaAssignmentTrackNoParams        = 3 // Level given by track, without parameters
+	LevaAssignmentSubTrack             = 4 // Level given by sub-track
+)
+
+// LevaLevel - level assignment for one level in leva box
+type LevaLevel struct {
+	TrackID               uint32
+	PaddingFlag           bool
+	AssignmentType        byte   // 7 bits
+	GroupingType          string // assignment types 0 and 1
+	GroupingTypeParameter uint32 // assignment type 1
+	SubTrackID            uint32 // assignment type 4
+}
+
+// size - size in bytes of level entry
+func (l LevaLevel) size() int {
+	switch l.AssignmentType {
+	case LevaAssignmentSampleGroup, LevaAssignmentSubTrack:
+		return 9
+	case LevaAssignmentSampleGroupParameter:
+		return 13
+	default:
+		return 5
+	}
+}
+
+// LevaBox - Level Assignment Box (leva), ISO/IEC 14496-12 Section 8.8.13
+//
+// Contained in : Movie Extends Box (mvex)
+//
+// Levels are used by DASH sub-representations and are referred to by ssix boxes.
+type LevaBox struct {
+	Version byte
+	Flags   uint32
+	Entries []LevaLevel
+}
+
+// DecodeLeva - box-specific decode
+func DecodeLeva(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeLevaSR(hdr, startPos, sr)
+}
+
+// DecodeLevaSR - box-specific decode
+func DecodeLevaSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := LevaBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	levelCount := int(sr.ReadUint8())
+	for i := 0; i < levelCount; i++ {
+		l := LevaLevel{TrackID: sr.ReadUint32()}
+		typeByte := sr.ReadUint8()
+		l.PaddingFlag = typeByte&0x80 != 0
+		l.AssignmentType = typeByte & 0x7f
+		switch l.AssignmentType {
+		case LevaAssignmentSampleGroup:
+			l.GroupingType = sr.ReadFixedLengthString(4)
+		case LevaAssignmentSampleGroupParameter:
+			l.GroupingType = sr.ReadFixedLengthString(4)
+			l.GroupingTypeParameter = sr.ReadUint32()
+		case LevaAssignmentTrack, LevaAssignmentTrackNoParams:
+		case LevaAssignmentSubTrack:
+			l.SubTrackID = sr.ReadUint32()
+		default:
+			return nil, fmt.Errorf("decode leva: reserved assignment type %d", l.AssignmentType)
+		}
+		b.Entries = append(b.Entries, l)
+	}
+	if sr.AccError() != nil {
+		return nil, fmt.Errorf("decode leva: %w", sr.AccError())
+	}
+	return &b, nil
+}
+
+// Levels - level assignments in order. Level numbers start at 1 for the first entry.
+func (b *LevaBox) Levels() []LevaLevel {
+	return b.Entries
+}
+
+// Type - return box type
+func (b *LevaBox) Type() string {
+	return "leva"
+}
+
+// Size - return calculated size
+func (b *LevaBox) Size() uint64 {
+	size := boxHeaderSize + 5
+	for _, l := range b.Entries {
+		size += l.size()
+	}
+	return uint64(size)
+}
+
+// Encode - write box to w
+func (b *LevaBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *LevaBox) EncodeSW(sw bits.SliceWriter) error {
+	if len(b.Entries) > 255 {
+		return fmt.Errorf("leva: %d levels is more than 255", len(b.Entries))
+	}
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint8(byte(len(b.Entries)))
+	for _, l := range b.Entries {
+		sw.WriteUint32(l.TrackID)
+		typeByte := l.AssignmentType & 0x7f
+		if l.PaddingFlag {
+			typeByte |= 0x80
+		}
+		sw.WriteUint8(typeByte)
+		switch l.AssignmentType {
+		case LevaAssignmentSampleGroup:
+			sw.WriteString(l.GroupingType, false)
+		case LevaAssignmentSampleGroupParameter:
+			sw.WriteString(l.GroupingType, false)
+			sw.WriteUint32(l.GroupingTypeParameter)
+		case LevaAssignmentSubTrack:
+			sw.WriteUint32(l.SubTrackID)
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *LevaBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - levelCount: %d", len(b.Entries))
+	for i, l := range b.Entries {
+		msg := fmt.Sprintf(" - level[%d]: trackID=%d paddingFlag=%t assignmentType=%d",
+			i+1, l.TrackID, l.PaddingFlag, l.AssignmentType)
+		switch l.AssignmentType {
+		case LevaAssignmentSampleGroup:
+			msg += fmt.Sprintf(" groupingType=%s", l.GroupingType)
+		case LevaAssignmentSampleGroupParameter:
+			msg += fmt.Sprintf(" groupingType=%s groupingTypeParameter=%d", l.GroupingType, l.GroupingTypeParameter)
+		case LevaAssignmentSubTrack:
+			msg += fmt.Sprintf(" subTrackID=%d", l.SubTrackID)
+		}
+		bd.write("%s", msg)
+	}
+	return bd.err
+}
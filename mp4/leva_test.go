@@ -0,0 +1,54 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestLeva(t *testing.T) {
+	leva := &LevaBox{
+		Entries: []LevaLevel{
+			{TrackID: 1, AssignmentType: LevaAssignmentSampleGroup, GroupingType: "tele"},
+			{TrackID: 1, PaddingFlag: true, AssignmentType: LevaAssignmentSampleGroupParameter,
+				GroupingType: "sync", GroupingTypeParameter: 7},
+			{TrackID: 2, AssignmentType: LevaAssignmentTrack},
+			{TrackID: 3, AssignmentType: LevaAssignmentSubTrack, SubTrackID: 5},
+		},
+	}
+	boxDiffAfterEncodeAndDecode(t, leva)
+}
+
+func TestLevaInMvex(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	levels := []LevaLevel{
+		{TrackID: 1, AssignmentType: LevaAssignmentSampleGroup, GroupingType: "tele"},
+		{TrackID: 1, AssignmentType: LevaAssignmentSubTrack, SubTrackID: 2},
+	}
+	init.Moov.Mvex.AddChild(&LevaBox{Entries: levels})
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	f, err := DecodeFile(bytes.NewBuffer(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leva := f.Init.Moov.Mvex.Leva
+	if leva == nil {
+		t.Fatalf("no leva box in mvex after decode")
+	}
+	if diff := deep.Equal(leva.Levels(), levels); diff != nil {
+		t.Errorf("levels differ: %v", diff)
+	}
+	var outBuf bytes.Buffer
+	if err = f.Encode(&outBuf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(outBuf.Bytes(), encoded) {
+		t.Errorf("re-encoded file differs from original")
+	}
+}
@@ -100,6 +100,36 @@ func TestReadData_NormalMode(t *testing.T) {
 
 }
 
+func TestReadAndCopyDataAtEnd(t *testing.T) {
+	mdat := &MdatBox{
+		StartPos: 0,
+		Data:     []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06},
+	}
+	// The range may end at the last byte of the mdat data
+	data, err := mdat.ReadData(12, 3, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(data, mdat.Data[4:]) {
+		t.Errorf("expected %v, got %v", mdat.Data[4:], data)
+	}
+	var outBuffer bytes.Buffer
+	n, err := mdat.CopyData(12, 3, nil, &outBuffer)
+	if err != nil || n != 3 {
+		t.Errorf("got %d bytes and error %v instead of 3 bytes", n, err)
+	}
+	if !bytes.Equal(outBuffer.Bytes(), mdat.Data[4:]) {
+		t.Errorf("expected %v, got %v", mdat.Data[4:], outBuffer.Bytes())
+	}
+	// but not beyond it
+	if _, err = mdat.ReadData(12, 4, nil); err == nil {
+		t.Errorf("expected error for ReadData range beyond mdat data")
+	}
+	if _, err = mdat.CopyData(12, 4, nil, &outBuffer); err == nil {
+		t.Errorf("expected error for CopyData range beyond mdat data")
+	}
+}
+
 func TestReadData_LazyMdatMode(t *testing.T) {
 
 	// prepare encoded mdat box before testing read
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 
 	"github.com/edgeware/mp4ff/bits"
 )
@@ -17,7 +18,7 @@ const charOffset = 0x60 // According to Section 8.4.2.3 of 14496-12
 // Timescale defines the timescale used for this track.
 // Language is a ISO-639-2/T language code stored as 1bit padding + [3]int5
 type MdhdBox struct {
-	Version          byte // Only version 0
+	Version          byte // 0 for 32-bit times and duration, 1 for 64-bit
 	Flags            uint32
 	CreationTime     uint64 // Typically not set
 	ModificationTime uint64 // Typically not set
@@ -79,6 +80,16 @@ func (m *MdhdBox) SetLanguage(lang string) {
 	m.Language = l
 }
 
+// SetDuration - set duration and select version 1 if duration or times do not fit in 32 bits, else version 0
+func (m *MdhdBox) SetDuration(duration uint64) {
+	m.Duration = duration
+	if m.Duration > math.MaxUint32 || m.CreationTime > math.MaxUint32 || m.ModificationTime > math.MaxUint32 {
+		m.Version = 1
+	} else {
+		m.Version = 0
+	}
+}
+
 // Type - box type
 func (m *MdhdBox) Type() string {
 	return "mdhd"
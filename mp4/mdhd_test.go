@@ -39,3 +39,26 @@ func TestMdhd(t *testing.T) {
 		}
 	}
 }
+
+func TestMdhdSetDuration(t *testing.T) {
+	mdhd := &MdhdBox{Timescale: 90000}
+	mdhd.SetLanguage("eng")
+	mdhd.SetDuration(1 << 33)
+	if mdhd.Version != 1 {
+		t.Errorf("got version %d instead of 1 for 64-bit duration", mdhd.Version)
+	}
+	if mdhd.Size() != 44 {
+		t.Errorf("got size %d instead of 44", mdhd.Size())
+	}
+	boxDiffAfterEncodeAndDecode(t, mdhd)
+	outBox := boxAfterEncodeAndDecode(t, mdhd)
+	mdhdOut := outBox.(*MdhdBox)
+	if mdhdOut.Version != 1 || mdhdOut.Duration != 1<<33 {
+		t.Errorf("got version %d and duration %d after decode", mdhdOut.Version, mdhdOut.Duration)
+	}
+	mdhd.SetDuration(1000)
+	if mdhd.Version != 0 {
+		t.Errorf("got version %d instead of 0 for 32-bit duration", mdhd.Version)
+	}
+	boxDiffAfterEncodeAndDecode(t, mdhd)
+}
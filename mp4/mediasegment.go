@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/edgeware/mp4ff/bits"
@@ -12,6 +13,8 @@ type MediaSegment struct {
 	Sidx        *SidxBox // Sidx for a segment
 	Fragments   []*Fragment
 	EncOptimize EncOptimize
+	// StartPos - position of the segment in the file. Used for explicit base data offsets at encoding
+	StartPos uint64
 }
 
 // NewMediaSegment - create empty MediaSegment with CMAF styp box
@@ -37,6 +40,39 @@ func (s *MediaSegment) AddFragment(f *Fragment) {
 	s.Fragments = append(s.Fragments, f)
 }
 
+// CreateEmptyMediaSegment - create a media segment (styp + moof + mdat) for trackID with an empty trun,
+// e.g. for probing players. The tfdt is set to baseTime. trex is not needed for an empty segment and may be nil.
+func CreateEmptyMediaSegment(trackID, seqNr uint32, baseTime uint64, trex *TrexBox) *MediaSegment {
+	return createMediaSegment(trackID, seqNr, baseTime, trex, nil, 0)
+}
+
+// CreateSingleSampleMediaSegment - create a media segment (styp + moof + mdat) for trackID with one sync sample.
+// The codec-specific sample data, e.g. a black video frame or a silent audio frame, is given by data.
+// If dur is 0, the default sample duration of trex is used.
+func CreateSingleSampleMediaSegment(trackID, seqNr uint32, baseTime uint64, trex *TrexBox,
+	data []byte, dur uint32) *MediaSegment {
+	return createMediaSegment(trackID, seqNr, baseTime, trex, data, dur)
+}
+
+// createMediaSegment - create a one-fragment media segment with one sample if data is not nil
+func createMediaSegment(trackID, seqNr uint32, baseTime uint64, trex *TrexBox, data []byte, dur uint32) *MediaSegment {
+	seg := NewMediaSegment()
+	frag, _ := CreateFragment(seqNr, trackID) // Never returns error
+	frag.Moof.Traf.Tfdt.SetBaseMediaDecodeTime(baseTime)
+	if data != nil {
+		if dur == 0 && trex != nil {
+			dur = trex.DefaultSampleDuration
+		}
+		frag.AddFullSample(FullSample{
+			Sample:     NewSample(SyncSampleFlags, dur, uint32(len(data)), 0),
+			DecodeTime: baseTime,
+			Data:       data,
+		})
+	}
+	seg.AddFragment(frag)
+	return seg
+}
+
 // LastFragment - Currently last fragment
 func (s *MediaSegment) LastFragment() *Fragment {
 	return s.Fragments[len(s.Fragments)-1]
@@ -57,50 +93,58 @@ func (s *MediaSegment) Size() uint64 {
 	return size
 }
 
-// Encode - Write MediaSegment via writer
+// Encode - Write MediaSegment via writer. Fragment positions are computed from StartPos
 func (s *MediaSegment) Encode(w io.Writer) error {
+	pos := s.StartPos
 	if s.Styp != nil {
 		err := s.Styp.Encode(w)
 		if err != nil {
 			return err
 		}
+		pos += s.Styp.Size()
 	}
 	if s.Sidx != nil {
 		err := s.Sidx.Encode(w)
 		if err != nil {
 			return err
 		}
+		pos += s.Sidx.Size()
 	}
 	for _, f := range s.Fragments {
 		f.EncOptimize = s.EncOptimize
-		err := f.Encode(w)
+		err := f.encodeAt(w, pos+f.moofOffset())
 		if err != nil {
 			return err
 		}
+		pos += f.Size()
 	}
 	return nil
 }
 
-// EncodeSW - Write MediaSegment via SliceWriter
+// EncodeSW - Write MediaSegment via SliceWriter. Fragment positions are computed from StartPos
 func (s *MediaSegment) EncodeSW(sw bits.SliceWriter) error {
+	pos := s.StartPos
 	if s.Styp != nil {
 		err := s.Styp.EncodeSW(sw)
 		if err != nil {
 			return err
 		}
+		pos += s.Styp.Size()
 	}
 	if s.Sidx != nil {
 		err := s.Sidx.EncodeSW(sw)
 		if err != nil {
 			return err
 		}
+		pos += s.Sidx.Size()
 	}
 	for _, f := range s.Fragments {
 		f.EncOptimize = s.EncOptimize
-		err := f.EncodeSW(sw)
+		err := f.encodeSWAt(sw, pos+f.moofOffset())
 		if err != nil {
 			return err
 		}
+		pos += f.Size()
 	}
 	return nil
 }
@@ -166,3 +210,41 @@ func (s *MediaSegment) Fragmentify(timescale uint64, trex *TrexBox, duration uin
 	}
 	return outFragments, nil
 }
+
+// AggregateStats - total number of samples and duration in timescale for trackID over all fragments in segs.
+// Missing sample durations are taken from tfhd or the trex defaults in init.
+func AggregateStats(init *InitSegment, segs []*MediaSegment, trackID uint32) (totalSamples uint32,
+	totalDuration uint64, timescale uint32, err error) {
+	if init == nil || init.Moov == nil {
+		return 0, 0, 0, fmt.Errorf("no moov in init segment")
+	}
+	trak := init.Moov.GetTrak(trackID)
+	if trak == nil {
+		return 0, 0, 0, fmt.Errorf("no track with trackID %d", trackID)
+	}
+	timescale = trak.Mdia.Mdhd.Timescale
+	var trex *TrexBox
+	if init.Moov.Mvex != nil {
+		trex, _ = init.Moov.Mvex.GetTrex(trackID)
+	}
+	if trex == nil {
+		return 0, 0, 0, fmt.Errorf("no trex for trackID %d", trackID)
+	}
+	for _, seg := range segs {
+		for _, frag := range seg.Fragments {
+			if frag.Moof == nil {
+				continue
+			}
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID != trackID {
+					continue
+				}
+				for _, trun := range traf.Truns {
+					totalDuration += trun.AddSampleDefaultValues(traf.Tfhd, trex)
+					totalSamples += trun.SampleCount()
+				}
+			}
+		}
+	}
+	return totalSamples, totalDuration, timescale, nil
+}
@@ -218,3 +218,93 @@ func TestDecodeEncodeCencFragmentedFile(t *testing.T) {
 		t.Errorf("generated bytes differ from input")
 	}
 }
+
+func TestAggregateStats(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(48000, "audio", "und")
+	init.Moov.Mvex.Trex.DefaultSampleDuration = 1024
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	nrSegs, nrSamplesPerFrag := 3, 10
+	for i := 0; i < nrSegs; i++ {
+		seg := NewMediaSegment()
+		frag, err := CreateFragment(uint32(i+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < nrSamplesPerFrag; j++ {
+			frag.AddFullSample(FullSample{
+				Sample:     NewSample(SyncSampleFlags, 1024, 1, 0),
+				DecodeTime: uint64((i*nrSamplesPerFrag + j) * 1024),
+				Data:       []byte{0},
+			})
+		}
+		frag.Moof.Traf.Trun.Flags &^= TrunSampleDurationPresentFlag // Use trex default duration
+		seg.AddFragment(frag)
+		if err = seg.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Segments) != nrSegs {
+		t.Fatalf("got %d segments instead of %d", len(f.Segments), nrSegs)
+	}
+	nrSamples, dur, timescale, err := AggregateStats(f.Init, f.Segments, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantedSamples := uint32(nrSegs * nrSamplesPerFrag)
+	if nrSamples != wantedSamples || dur != uint64(wantedSamples)*1024 || timescale != 48000 {
+		t.Errorf("got %d samples, duration %d, timescale %d instead of %d, %d, 48000",
+			nrSamples, dur, timescale, wantedSamples, uint64(wantedSamples)*1024)
+	}
+	if _, _, _, err = AggregateStats(f.Init, f.Segments, 2); err == nil {
+		t.Errorf("expected error for non-existing track")
+	}
+}
+
+func TestCreateEmptyMediaSegment(t *testing.T) {
+	trex := CreateTrex(1)
+	trex.DefaultSampleDuration = 1024
+	testCases := []struct {
+		seg        *MediaSegment
+		nrSamples  uint32
+		sampleData []byte
+	}{
+		{CreateEmptyMediaSegment(1, 5, 48000, trex), 0, nil},
+		{CreateSingleSampleMediaSegment(1, 5, 48000, trex, []byte{0x21, 0x10, 0x04}, 0), 1, []byte{0x21, 0x10, 0x04}},
+	}
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		if err := tc.seg.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		f, err := DecodeFile(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(f.Segments) != 1 || f.Segments[0].Styp == nil || len(f.Segments[0].Fragments) != 1 {
+			t.Fatalf("not one segment with styp and one fragment")
+		}
+		frag := f.Segments[0].Fragments[0]
+		traf := frag.Moof.Traf
+		if frag.Moof.Mfhd.SequenceNumber != 5 || traf.Tfdt.BaseMediaDecodeTime != 48000 {
+			t.Errorf("wrong sequence number or base media decode time")
+		}
+		if traf.Trun.SampleCount() != tc.nrSamples {
+			t.Errorf("got sample count %d instead of %d", traf.Trun.SampleCount(), tc.nrSamples)
+		}
+		samples, err := frag.GetFullSamples(trex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.nrSamples == 1 && (samples[0].Dur != 1024 || !bytes.Equal(samples[0].Data, tc.sampleData)) {
+			t.Errorf("wrong sample duration or data")
+		}
+	}
+}
@@ -8,11 +8,17 @@ import (
 )
 
 // MetaBox - MetaBox meta ISO/IEC 14496-12 Ed. 6 2020 Section 8.11
+//
+// QuickTime meta boxes lack version and flags, and are decoded with IsQuickTime set.
 type MetaBox struct {
-	Version  byte
-	Flags    uint32
-	Hdlr     *HdlrBox
-	Children []Box
+	Version     byte
+	Flags       uint32
+	IsQuickTime bool // No version and flags as in QuickTime files
+	Hdlr        *HdlrBox
+	Xml         *XmlBox
+	Bxml        *BxmlBox
+	Grpl        *GrplBox
+	Children    []Box
 }
 
 // CreateMetaBox - Create a new MetaBox
@@ -30,50 +36,80 @@ func (b *MetaBox) AddChild(child Box) {
 	switch box := child.(type) {
 	case *HdlrBox:
 		b.Hdlr = box
+	case *XmlBox:
+		b.Xml = box
+	case *BxmlBox:
+		b.Bxml = box
+	case *GrplBox:
+		b.Grpl = box
 	}
 	b.Children = append(b.Children, child)
 }
 
+// XML - return text of xml box if present
+func (b *MetaBox) XML() (string, bool) {
+	if b.Xml == nil {
+		return "", false
+	}
+	return b.Xml.XML, true
+}
+
+// EntityGroups - entity groups in grpl box, or nil if there is none
+func (b *MetaBox) EntityGroups() []EntityGroup {
+	if b.Grpl == nil {
+		return nil
+	}
+	groups := make([]EntityGroup, 0, len(b.Grpl.Groups))
+	for _, g := range b.Grpl.Groups {
+		groups = append(groups, EntityGroup{GroupingType: g.GroupingType, GroupID: g.GroupID, EntityIDs: g.EntityIDs})
+	}
+	return groups
+}
+
 // DecodeMeta - box-specific decode
 func DecodeMeta(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
-	var versionAndFlags uint32
-	err := binary.Read(r, binary.BigEndian, &versionAndFlags)
+	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
 	}
-	//Note higher startPos below since not simple container
-	children, err := DecodeContainerChildren(hdr, startPos+12, startPos+hdr.Size, r)
-	if err != nil {
-		return nil, err
-	}
-	b := &MetaBox{
-		Version: byte(versionAndFlags >> 24),
-		Flags:   versionAndFlags & flagsMask,
-	}
-	for _, child := range children {
-		b.AddChild(child)
-	}
-	return b, nil
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeMetaSR(hdr, startPos, sr)
 }
 
 // DecodeMetaSR - box-specific decode
 func DecodeMetaSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	versionAndFlags := sr.ReadUint32()
-	//Note higher startPos below since not simple container
-	children, err := DecodeContainerChildrenSR(hdr, startPos+12, startPos+hdr.Size, sr)
+	b := &MetaBox{}
+	childStartPos := startPos + 12 // Note higher startPos since not simple container
+	if isQuickTimeMeta(hdr, sr) {
+		b.IsQuickTime = true
+		childStartPos = startPos + 8
+	} else {
+		versionAndFlags := sr.ReadUint32()
+		b.Version = byte(versionAndFlags >> 24)
+		b.Flags = versionAndFlags & flagsMask
+	}
+	children, err := DecodeContainerChildrenSR(hdr, childStartPos, startPos+hdr.Size, sr)
 	if err != nil {
 		return nil, err
 	}
-	b := &MetaBox{
-		Version: byte(versionAndFlags >> 24),
-		Flags:   versionAndFlags & flagsMask,
-	}
 	for _, child := range children {
 		b.AddChild(child)
 	}
 	return b, nil
 }
 
+// isQuickTimeMeta - true if the meta payload starts with a hdlr box instead of version and flags
+func isQuickTimeMeta(hdr BoxHeader, sr bits.SliceReader) bool {
+	if hdr.payloadLen() < 8 {
+		return false
+	}
+	pos := sr.GetPos()
+	sr.SkipBytes(4)
+	childType := sr.ReadFixedLengthString(4)
+	sr.SetPos(pos)
+	return childType == "hdlr"
+}
+
 // Type - box type
 func (b *MetaBox) Type() string {
 	return "meta"
@@ -81,6 +117,9 @@ func (b *MetaBox) Type() string {
 
 // Size - calculated size of box
 func (b *MetaBox) Size() uint64 {
+	if b.IsQuickTime {
+		return containerSize(b.Children)
+	}
 	return 4 + containerSize(b.Children)
 }
 
@@ -95,10 +134,12 @@ func (b *MetaBox) Encode(w io.Writer) error {
 	if err != nil {
 		return err
 	}
-	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
-	err = binary.Write(w, binary.BigEndian, versionAndFlags)
-	if err != nil {
-		return err
+	if !b.IsQuickTime {
+		versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+		err = binary.Write(w, binary.BigEndian, versionAndFlags)
+		if err != nil {
+			return err
+		}
 	}
 	for _, b := range b.Children {
 		err = b.Encode(w)
@@ -115,8 +156,10 @@ func (b *MetaBox) EncodeSW(sw bits.SliceWriter) error {
 	if err != nil {
 		return err
 	}
-	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
-	sw.WriteUint32(versionAndFlags)
+	if !b.IsQuickTime {
+		versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+		sw.WriteUint32(versionAndFlags)
+	}
 	for _, c := range b.Children {
 		err = c.EncodeSW(sw)
 		if err != nil {
@@ -128,7 +171,11 @@ func (b *MetaBox) EncodeSW(sw bits.SliceWriter) error {
 
 // Info - box-specific info
 func (b *MetaBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
-	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	version := int(b.Version)
+	if b.IsQuickTime {
+		version = -1
+	}
+	bd := newInfoDumper(w, indent, b, version, b.Flags)
 	if bd.err != nil {
 		return bd.err
 	}
@@ -1,6 +1,11 @@
 package mp4
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+)
 
 func TestMeta(t *testing.T) {
 	hdlr, err := CreateHdlr("zzzz")
@@ -11,3 +16,73 @@ func TestMeta(t *testing.T) {
 	boxDiffAfterEncodeAndDecode(t, meta)
 
 }
+
+func TestMetaXml(t *testing.T) {
+	hdlr, err := CreateHdlr("meta")
+	if err != nil {
+		t.Error(err)
+	}
+	doc := `<?xml version="1.0" encoding="UTF-8"?><Mpeg7><Title>Smörgåsbord – 日本</Title></Mpeg7>`
+	meta := CreateMetaBox(0, hdlr)
+	meta.AddChild(&XmlBox{XML: doc})
+	meta.AddChild(&BxmlBox{Data: []byte{0x03, 0x01, 0x6a, 0x00}})
+	udta := &UdtaBox{}
+	udta.AddChild(meta)
+	boxDiffAfterEncodeAndDecode(t, udta)
+
+	decUdta := boxAfterEncodeAndDecode(t, udta).(*UdtaBox)
+	decMeta := decUdta.Children[0].(*MetaBox)
+	xml, ok := decMeta.XML()
+	if !ok || xml != doc {
+		t.Errorf("got xml %q, %t instead of %q", xml, ok, doc)
+	}
+	if decMeta.Bxml == nil || len(decMeta.Bxml.Data) != 4 {
+		t.Errorf("bxml not decoded")
+	}
+	boxDiffAfterEncodeAndDecode(t, &XmlBox{XML: "<a/>", LacksZeroTermination: true})
+
+	if _, ok = CreateMetaBox(0, hdlr).XML(); ok {
+		t.Errorf("expected no xml in meta box without xml box")
+	}
+}
+
+func TestMetaEntityGroups(t *testing.T) {
+	hdlr, err := CreateHdlr("pict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := CreateMetaBox(0, hdlr)
+	grpl := &GrplBox{}
+	grpl.AddChild(CreateEntityToGroupBox("altr", 100, []uint32{1, 2, 3}))
+	ster := CreateEntityToGroupBox("ster", 101, []uint32{4, 5})
+	ster.Extra = []byte{0x01}
+	grpl.AddChild(ster)
+	meta.AddChild(grpl)
+
+	f := NewFile()
+	f.AddChild(NewFtyp("heic", 0, []string{"mif1", "heic"}), 0)
+	f.AddChild(meta, f.Ftyp.Size())
+	var buf bytes.Buffer
+	if err = f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decFile, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decMeta, ok := decFile.Children[1].(*MetaBox)
+	if !ok {
+		t.Fatalf("second box is %s and not meta", decFile.Children[1].Type())
+	}
+	if diff := deep.Equal(decMeta, meta); diff != nil {
+		t.Errorf("meta differs after encode and decode: %v", diff)
+	}
+	wanted := []EntityGroup{
+		{GroupingType: "altr", GroupID: 100, EntityIDs: []uint32{1, 2, 3}},
+		{GroupingType: "ster", GroupID: 101, EntityIDs: []uint32{4, 5}},
+	}
+	if diff := deep.Equal(decMeta.EntityGroups(), wanted); diff != nil {
+		t.Errorf("entity groups differ: %v", diff)
+	}
+	boxDiffAfterEncodeAndDecode(t, meta)
+}
@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/edgeware/mp4ff/bits"
@@ -94,3 +95,190 @@ func (m *MfraBox) GetChildren() []Box {
 func (m *MfraBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	return ContainerInfo(m, w, specificBoxLevels, indent, indentStep)
 }
+
+// BuildMfra - build mfra box with one tfra per track from fragments in segs.
+// moofOffsets must contain the file offset of each fragment's moof box in order.
+// Each traf with a sync sample gets an entry with the presentation time and position of its first sync sample.
+// mvex provides the trex defaults for sample durations and flags not given in trun or tfhd.
+// It may be nil if all fragments signal these values in trun or tfhd.
+// An mfro box with the total size of the mfra box is appended.
+func BuildMfra(segs []*MediaSegment, moofOffsets []uint64, mvex *MvexBox) *MfraBox {
+	var tfras []*TfraBox
+	tfraForTrack := make(map[uint32]*TfraBox)
+	fragNr := 0
+	for _, seg := range segs {
+		for _, frag := range seg.Fragments {
+			if fragNr >= len(moofOffsets) {
+				break
+			}
+			moofOffset := moofOffsets[fragNr]
+			fragNr++
+			for trafIdx, traf := range frag.Moof.Trafs {
+				var trex *TrexBox
+				if mvex != nil {
+					trex, _ = mvex.GetTrex(traf.Tfhd.TrackID)
+				}
+				entry, ok := firstSyncSampleEntry(traf, trex)
+				if !ok {
+					continue
+				}
+				entry.MoofOffset = int64(moofOffset)
+				entry.TrafNumber = uint32(trafIdx + 1)
+				trackID := traf.Tfhd.TrackID
+				tfra, ok := tfraForTrack[trackID]
+				if !ok {
+					tfra = &TfraBox{TrackID: trackID}
+					tfraForTrack[trackID] = tfra
+					tfras = append(tfras, tfra)
+				}
+				tfra.Entries = append(tfra.Entries, entry)
+			}
+		}
+	}
+	mfra := &MfraBox{}
+	for _, tfra := range tfras {
+		var maxTraf, maxTrun, maxSample uint32
+		for _, e := range tfra.Entries {
+			if e.Time >= 1<<31 || e.MoofOffset >= 1<<31 {
+				tfra.Version = 1
+			}
+			if e.TrafNumber > maxTraf {
+				maxTraf = e.TrafNumber
+			}
+			if e.TrunNumber > maxTrun {
+				maxTrun = e.TrunNumber
+			}
+			if e.SampleDelta > maxSample {
+				maxSample = e.SampleDelta
+			}
+		}
+		tfra.LengthSizeOfTrafNum = lengthSizeMinusOne(maxTraf)
+		tfra.LengthSizeOfTrunNum = lengthSizeMinusOne(maxTrun)
+		tfra.LengthSizeOfSampleNum = lengthSizeMinusOne(maxSample)
+		_ = mfra.AddChild(tfra)
+	}
+	mfro := &MfroBox{}
+	_ = mfra.AddChild(mfro)
+	mfro.ParentSize = uint32(mfra.Size())
+	return mfra
+}
+
+// RegenerateMfra - recompute the tfra entries and the mfro size of the mfra box of a fragmented file
+// from the current fragment layout, so that it is valid after fragments have been edited.
+// The moof offsets are calculated for encoding with the current FragEncMode, and the mfra box
+// is updated in place with the same content as made by BuildMfra, using the trex defaults of the init segment.
+func (f *File) RegenerateMfra() error {
+	if !f.isFragmented {
+		return fmt.Errorf("only available for fragmented files")
+	}
+	if f.Mfra == nil {
+		return fmt.Errorf("no mfra box")
+	}
+	moofOffsets, err := f.moofOffsets()
+	if err != nil {
+		return err
+	}
+	var mvex *MvexBox
+	if f.Init != nil {
+		mvex = f.Init.Moov.Mvex
+	}
+	mfra := BuildMfra(f.Segments, moofOffsets, mvex)
+	f.Mfra.Tfra = mfra.Tfra
+	f.Mfra.Tfras = mfra.Tfras
+	f.Mfra.Mfro = mfra.Mfro
+	f.Mfra.Children = mfra.Children
+	return nil
+}
+
+// moofOffsets - file offset of the moof box of each fragment when encoded with current FragEncMode
+func (f *File) moofOffsets() ([]uint64, error) {
+	var offsets []uint64
+	var pos uint64
+	switch f.FragEncMode {
+	case EncModeSegment:
+		if f.Init != nil {
+			pos += f.Init.Size()
+		}
+		if f.Sidx != nil {
+			pos += f.Sidx.Size()
+		}
+		for _, seg := range f.Segments {
+			if seg.Styp != nil {
+				pos += seg.Styp.Size()
+			}
+			if seg.Sidx != nil {
+				pos += seg.Sidx.Size()
+			}
+			for _, frag := range seg.Fragments {
+				for _, c := range frag.Children {
+					if c == frag.Moof {
+						offsets = append(offsets, pos)
+					}
+					pos += c.Size()
+				}
+			}
+		}
+	case EncModeBoxTree:
+		moofPos := make(map[*MoofBox]uint64)
+		for _, c := range f.Children {
+			if moof, ok := c.(*MoofBox); ok {
+				moofPos[moof] = pos
+			}
+			pos += c.Size()
+		}
+		for _, seg := range f.Segments {
+			for _, frag := range seg.Fragments {
+				offset, ok := moofPos[frag.Moof]
+				if !ok {
+					return nil, fmt.Errorf("moof with sequence number %d not in file children",
+						frag.Moof.Mfhd.SequenceNumber)
+				}
+				offsets = append(offsets, offset)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("Unknown FragEncMode=%d", f.FragEncMode)
+	}
+	return offsets, nil
+}
+
+// firstSyncSampleEntry - tfra entry with presentation time, trun number and sample number of first sync sample
+// in traf. Sample values not in trun are taken from tfhd if present there, and otherwise from trex if not nil.
+func firstSyncSampleEntry(traf *TrafBox, trex *TrexBox) (TfraEntry, bool) {
+	var decTime uint64
+	if traf.Tfdt != nil {
+		decTime = traf.Tfdt.BaseMediaDecodeTime
+	}
+	for trunIdx, trun := range traf.Truns {
+		trun.AddSampleDefaultValues(traf.Tfhd, trex)
+		for sampleIdx, s := range trun.Samples {
+			if s.IsSync() {
+				cto := int64(s.CompositionTimeOffset)
+				if trun.Version == 0 {
+					cto = int64(uint32(s.CompositionTimeOffset)) // Unsigned in version 0
+				}
+				return TfraEntry{
+					Time:        int64(decTime) + cto,
+					TrunNumber:  uint32(trunIdx + 1),
+					SampleDelta: uint32(sampleIdx + 1),
+				}, true
+			}
+			decTime += uint64(s.Dur)
+		}
+	}
+	return TfraEntry{}, false
+}
+
+// lengthSizeMinusOne - number of bytes minus one needed to represent nr
+func lengthSizeMinusOne(nr uint32) byte {
+	switch {
+	case nr <= 0xff:
+		return 0
+	case nr <= 0xffff:
+		return 1
+	case nr <= 0xffffff:
+		return 2
+	default:
+		return 3
+	}
+}
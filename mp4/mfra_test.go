@@ -1,6 +1,9 @@
 package mp4
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+)
 
 func TestMfra(t *testing.T) {
 	mfra := &MfraBox{}
@@ -31,3 +34,291 @@ func TestMfra(t *testing.T) {
 	}
 	boxDiffAfterEncodeAndDecode(t, mfra)
 }
+
+func TestBuildMfra(t *testing.T) {
+	const sampleDur = 1000
+	var segs []*MediaSegment
+	var moofOffsets []uint64
+	offset := uint64(1000)
+	for nr := uint32(1); nr <= 3; nr++ {
+		seg := NewMediaSegment()
+		frag, err := CreateFragment(nr, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seg.AddFragment(frag)
+		baseTime := uint64(nr-1) * 4 * sampleDur
+		for i := 0; i < 4; i++ {
+			flags := NonSyncSampleFlags
+			if i == int(nr)-1 { // Sync sample at different position in each fragment
+				flags = SyncSampleFlags
+			}
+			frag.AddFullSample(FullSample{
+				Sample:     Sample{Flags: flags, Dur: sampleDur, Size: 1},
+				DecodeTime: baseTime + uint64(i*sampleDur),
+				Data:       []byte{byte(i)},
+			})
+		}
+		segs = append(segs, seg)
+		moofOffsets = append(moofOffsets, offset+seg.Styp.Size())
+		offset += seg.Size()
+	}
+	mfra := BuildMfra(segs, moofOffsets, nil)
+	if len(mfra.Tfras) != 1 || mfra.Mfro == nil {
+		t.Fatalf("expected one tfra and an mfro")
+	}
+	if mfra.Mfro.ParentSize != uint32(mfra.Size()) {
+		t.Errorf("mfro parent size %d instead of %d", mfra.Mfro.ParentSize, mfra.Size())
+	}
+	tfra := mfra.Tfra
+	if tfra.TrackID != 1 || len(tfra.Entries) != 3 {
+		t.Fatalf("got trackID %d with %d entries", tfra.TrackID, len(tfra.Entries))
+	}
+	for i, e := range tfra.Entries {
+		wanted := TfraEntry{
+			Time:        int64(i*4*sampleDur + i*sampleDur),
+			MoofOffset:  int64(moofOffsets[i]),
+			TrafNumber:  1,
+			TrunNumber:  1,
+			SampleDelta: uint32(i + 1),
+		}
+		if e != wanted {
+			t.Errorf("entry %d: got %+v instead of %+v", i, e, wanted)
+		}
+	}
+	boxDiffAfterEncodeAndDecode(t, mfra)
+}
+
+func TestBuildMfraTrexDefaults(t *testing.T) {
+	const sampleDur = 1000
+	frag, err := CreateMultiTrackFragment(1, []uint32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trackID := range []uint32{1, 2} {
+		for i := 0; i < 3; i++ {
+			err = frag.AddFullSampleToTrack(FullSample{
+				Sample:     Sample{Flags: SyncSampleFlags, Dur: sampleDur, Size: 1},
+				DecodeTime: uint64(i * sampleDur),
+				Data:       []byte{byte(i)},
+			}, trackID)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// Durations and flags are only given by trex, except a non-sync first sample in track 1
+	for _, traf := range frag.Moof.Trafs {
+		trun := traf.Trun
+		trun.Flags &^= TrunSampleDurationPresentFlag | TrunSampleFlagsPresentFlag
+		for i := range trun.Samples {
+			trun.Samples[i].Dur = 0
+			trun.Samples[i].Flags = 0
+		}
+		if traf.Tfhd.TrackID == 1 {
+			trun.SetFirstSampleFlags(NonSyncSampleFlags)
+		}
+	}
+	mvex := &MvexBox{}
+	mvex.AddChild(&TrexBox{TrackID: 1, DefaultSampleDuration: sampleDur, DefaultSampleFlags: SyncSampleFlags})
+	mvex.AddChild(&TrexBox{TrackID: 2, DefaultSampleDuration: sampleDur, DefaultSampleFlags: NonSyncSampleFlags})
+	seg := NewMediaSegment()
+	seg.AddFragment(frag)
+	mfra := BuildMfra([]*MediaSegment{seg}, []uint64{1000}, mvex)
+	if len(mfra.Tfras) != 1 {
+		t.Fatalf("got %d tfras instead of 1", len(mfra.Tfras))
+	}
+	wanted := TfraEntry{Time: sampleDur, MoofOffset: 1000, TrafNumber: 1, TrunNumber: 1, SampleDelta: 2}
+	if tfra := mfra.Tfra; tfra.TrackID != 1 || len(tfra.Entries) != 1 || tfra.Entries[0] != wanted {
+		t.Errorf("got tfra for track %d with entries %+v instead of %+v", tfra.TrackID, tfra.Entries, wanted)
+	}
+}
+
+func TestBuildMfraCompositionTimeOffset(t *testing.T) {
+	const sampleDur = 1000
+	for _, tc := range []struct {
+		trunVersion byte
+		cto         int32
+		wantedTime  int64
+	}{
+		{0, 2 * sampleDur, 3 * sampleDur},
+		{0, -1 << 31, sampleDur + 1<<31}, // Unsigned offset in version 0
+		{1, -sampleDur / 2, sampleDur / 2},
+	} {
+		frag, err := CreateFragment(1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frag.AddFullSample(FullSample{
+			Sample: Sample{Flags: NonSyncSampleFlags, Dur: sampleDur, Size: 1, CompositionTimeOffset: sampleDur},
+			Data:   []byte{0},
+		})
+		frag.AddFullSample(FullSample{
+			Sample:     Sample{Flags: SyncSampleFlags, Dur: sampleDur, Size: 1, CompositionTimeOffset: tc.cto},
+			DecodeTime: sampleDur,
+			Data:       []byte{1},
+		})
+		frag.Moof.Traf.Trun.Version = tc.trunVersion
+		seg := NewMediaSegment()
+		seg.AddFragment(frag)
+		mfra := BuildMfra([]*MediaSegment{seg}, []uint64{1000}, nil)
+		wanted := TfraEntry{Time: tc.wantedTime, MoofOffset: 1000, TrafNumber: 1, TrunNumber: 1, SampleDelta: 2}
+		if tfra := mfra.Tfra; len(tfra.Entries) != 1 || tfra.Entries[0] != wanted {
+			t.Errorf("trun version %d: got entries %+v instead of %+v", tc.trunVersion, tfra.Entries, wanted)
+		}
+	}
+}
+
+func TestRegenerateMfra(t *testing.T) {
+	const sampleDur = 1000
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(10000, "video", "und")
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var segs []*MediaSegment
+	var moofOffsets []uint64
+	for nr := uint32(1); nr <= 3; nr++ {
+		seg := NewMediaSegment()
+		frag, err := CreateFragment(nr, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seg.AddFragment(frag)
+		for i := 0; i < 4; i++ {
+			flags := NonSyncSampleFlags
+			if i == 1 {
+				flags = SyncSampleFlags
+			}
+			frag.AddFullSample(FullSample{
+				Sample:     Sample{Flags: flags, Dur: sampleDur, Size: 1},
+				DecodeTime: uint64(nr-1)*4*sampleDur + uint64(i*sampleDur),
+				Data:       []byte{byte(i)},
+			})
+		}
+		moofOffsets = append(moofOffsets, uint64(buf.Len())+seg.Styp.Size())
+		if err = seg.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		segs = append(segs, seg)
+	}
+	if err := BuildMfra(segs, moofOffsets, nil).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Mfra == nil {
+		t.Fatalf("no mfra after decode")
+	}
+
+	// Prepend a sync sample to the first fragment, which moves the following moof boxes
+	frag := f.Segments[0].Fragments[0]
+	trun := frag.Moof.Traf.Trun
+	trun.Samples = append([]Sample{{Flags: SyncSampleFlags, Dur: sampleDur, Size: 2}}, trun.Samples...)
+	frag.Mdat.Data = append([]byte{0xff, 0xff}, frag.Mdat.Data...)
+	frag.Moof.Traf.Tfdt.BaseMediaDecodeTime = 0
+	for _, seg := range f.Segments[1:] {
+		tfdt := seg.Fragments[0].Moof.Traf.Tfdt
+		tfdt.SetBaseMediaDecodeTime(tfdt.BaseMediaDecodeTime + sampleDur)
+	}
+	staleOffset := f.Mfra.Tfra.Entries[1].MoofOffset
+	if err = f.RegenerateMfra(); err != nil {
+		t.Fatal(err)
+	}
+	if f.Mfra.Mfro.ParentSize != uint32(f.Mfra.Size()) {
+		t.Errorf("mfro parent size %d instead of %d", f.Mfra.Mfro.ParentSize, f.Mfra.Size())
+	}
+
+	var outBuf bytes.Buffer
+	if err = f.Encode(&outBuf); err != nil {
+		t.Fatal(err)
+	}
+	data := outBuf.Bytes()
+	f2, err := DecodeFile(bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f2.Mfra == nil {
+		t.Fatalf("no mfra after re-encoding")
+	}
+	entries := f2.Mfra.Tfra.Entries
+	if len(entries) != 3 {
+		t.Fatalf("got %d tfra entries instead of 3", len(entries))
+	}
+	if entries[1].MoofOffset == staleOffset {
+		t.Errorf("moof offset not updated")
+	}
+	for i, e := range entries {
+		moof := f2.Segments[i].Fragments[0].Moof
+		if uint64(e.MoofOffset) != moof.StartPos || string(data[e.MoofOffset+4:e.MoofOffset+8]) != "moof" {
+			t.Errorf("entry %d: moof offset %d does not point to moof at %d", i, e.MoofOffset, moof.StartPos)
+		}
+		wantedTime, wantedSampleNr := int64(i*4*sampleDur+2*sampleDur), uint32(2)
+		if i == 0 {
+			wantedTime, wantedSampleNr = 0, 1
+		}
+		if e.Time != wantedTime || e.SampleDelta != wantedSampleNr {
+			t.Errorf("entry %d: got time %d sample %d instead of %d and %d",
+				i, e.Time, e.SampleDelta, wantedTime, wantedSampleNr)
+		}
+	}
+}
+
+func TestRegenerateMfraTrexDefaults(t *testing.T) {
+	const sampleDur = 1000
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(10000, "video", "und")
+	trex := init.Moov.Mvex.Trex
+	trex.DefaultSampleDuration = sampleDur
+	trex.DefaultSampleFlags = SyncSampleFlags
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for nr := uint32(1); nr <= 3; nr++ {
+		seg := NewMediaSegment()
+		frag, err := CreateFragment(nr, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seg.AddFragment(frag)
+		for i := 0; i < 4; i++ {
+			frag.AddFullSample(FullSample{
+				Sample:     Sample{Dur: sampleDur, Size: 1},
+				DecodeTime: uint64(nr-1)*4*sampleDur + uint64(i*sampleDur),
+				Data:       []byte{byte(i)},
+			})
+		}
+		// Only the first sample has flags in trun, so the second sample is a sync sample from trex
+		trun := frag.Moof.Traf.Trun
+		trun.Flags &^= TrunSampleDurationPresentFlag | TrunSampleFlagsPresentFlag
+		trun.SetFirstSampleFlags(NonSyncSampleFlags)
+		if err = seg.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Mfra = &MfraBox{}
+	if err = f.RegenerateMfra(); err != nil {
+		t.Fatal(err)
+	}
+	if f.Mfra.Tfra == nil {
+		t.Fatalf("no tfra after regenerating mfra")
+	}
+	entries := f.Mfra.Tfra.Entries
+	if len(entries) != 3 {
+		t.Fatalf("got %d tfra entries instead of 3", len(entries))
+	}
+	for i, e := range entries {
+		wantedTime := int64(i*4*sampleDur + sampleDur)
+		if e.Time != wantedTime || e.SampleDelta != 2 {
+			t.Errorf("entry %d: got time %d sample %d instead of %d and 2", i, e.Time, e.SampleDelta, wantedTime)
+		}
+	}
+}
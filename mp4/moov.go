@@ -16,7 +16,7 @@ type MoovBox struct {
 	Mvex     *MvexBox
 	Pssh     *PsshBox
 	Psshs    []*PsshBox
-	Children []Box
+	Children []Box // All children in order, including free and skip boxes
 	StartPos uint64
 }
 
@@ -25,16 +25,10 @@ func NewMoovBox() *MoovBox {
 	return &MoovBox{}
 }
 
-// AddChild - Add a child box
+// AddChild - Add a child box.
+// A trak box is put directly after the last previous trak box to keep traks together.
 func (m *MoovBox) AddChild(child Box) {
-	switch box := child.(type) {
-	case *MvhdBox:
-		m.Mvhd = box
-	case *TrakBox:
-		if m.Trak == nil {
-			m.Trak = box
-		}
-		m.Traks = append(m.Traks, box)
+	if box, ok := child.(*TrakBox); ok {
 		// Possibley re-order to keep traks together on same
 		// side of mvex or similar. Put this trak box after last previous trak
 		lastTrakIdx := 0
@@ -44,10 +38,31 @@ func (m *MoovBox) AddChild(child Box) {
 			}
 		}
 		if lastTrakIdx != 0 && lastTrakIdx != len(m.Children)-1 { // last one in middle
+			m.setChildField(box)
 			m.Children = append(m.Children[:lastTrakIdx+2], m.Children[lastTrakIdx+1:]...)
 			m.Children[lastTrakIdx+1] = box
 			return
 		}
+	}
+	m.appendChild(child)
+}
+
+// appendChild - append child box without re-ordering, so that decoded boxes such as free keep their position
+func (m *MoovBox) appendChild(child Box) {
+	m.setChildField(child)
+	m.Children = append(m.Children, child)
+}
+
+// setChildField - set the box-specific field for child
+func (m *MoovBox) setChildField(child Box) {
+	switch box := child.(type) {
+	case *MvhdBox:
+		m.Mvhd = box
+	case *TrakBox:
+		if m.Trak == nil {
+			m.Trak = box
+		}
+		m.Traks = append(m.Traks, box)
 	case *MvexBox:
 		m.Mvex = box
 	case *PsshBox:
@@ -56,7 +71,6 @@ func (m *MoovBox) AddChild(child Box) {
 		}
 		m.Psshs = append(m.Psshs, box)
 	}
-	m.Children = append(m.Children, child)
 }
 
 // DecodeMoov - box-specific decode
@@ -74,7 +88,7 @@ func DecodeMoov(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	m := MoovBox{Children: make([]Box, 0, len(children))}
 	m.StartPos = startPos
 	for _, c := range children {
-		m.AddChild(c)
+		m.appendChild(c)
 	}
 	return &m, err
 }
@@ -88,7 +102,7 @@ func DecodeMoovSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	m := MoovBox{Children: make([]Box, 0, len(children))}
 	m.StartPos = startPos
 	for _, c := range children {
-		m.AddChild(c)
+		m.appendChild(c)
 	}
 	return &m, err
 }
@@ -142,6 +156,17 @@ func (m *MoovBox) RemovePsshs() []*PsshBox {
 	return psshs
 }
 
+// GetTrak - get trak for trackID, or nil if not present
+func (m *MoovBox) GetTrak(trackID uint32) *TrakBox {
+	for _, trak := range m.Traks {
+		if trak.Tkhd.TrackID == trackID {
+			return trak
+		}
+	}
+	return nil
+}
+
+// GetSinf - get sinf box for trackID, or nil if not present
 func (m *MoovBox) GetSinf(trackID uint32) *SinfBox {
 	for _, trak := range m.Traks {
 		if trak.Tkhd.TrackID == trackID {
@@ -0,0 +1,46 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMoovFreeBetweenTraks(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.AddEmptyTrack(48000, "audio", "und")
+	moov := init.Moov
+	free := &FreeBox{Name: "free", notDecoded: make([]byte, 16)}
+	// Build moov with free between the traks, which AddChild would not produce
+	orig := &MoovBox{Children: []Box{moov.Mvhd, moov.Traks[0], free, moov.Traks[1], moov.Mvex}}
+	var buf bytes.Buffer
+	if err := orig.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	origBytes := buf.Bytes()
+
+	box, err := DecodeBox(0, bytes.NewBuffer(origBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decMoov := box.(*MoovBox)
+	wantedTypes := []string{"mvhd", "trak", "free", "trak", "mvex"}
+	if len(decMoov.Children) != len(wantedTypes) {
+		t.Fatalf("got %d children instead of %d", len(decMoov.Children), len(wantedTypes))
+	}
+	for i, c := range decMoov.Children {
+		if c.Type() != wantedTypes[i] {
+			t.Errorf("child %d: got %s instead of %s", i, c.Type(), wantedTypes[i])
+		}
+	}
+	if len(decMoov.Traks) != 2 || decMoov.Mvex == nil {
+		t.Errorf("traks or mvex not set")
+	}
+	var outBuf bytes.Buffer
+	if err = decMoov.Encode(&outBuf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(outBuf.Bytes(), origBytes) {
+		t.Errorf("re-encoded moov differs from original")
+	}
+}
@@ -0,0 +1,137 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// Mp4sBox - MPEG-4 Systems sample entry (mp4s) for streams such as BIFS or OD
+// Defined in ISO/IEC 14496-14 Section 6.7.2
+//
+// Contained in : Sample Description Box (stsd)
+type Mp4sBox struct {
+	DataReferenceIndex uint16
+	Esds               *EsdsBox
+	Children           []Box
+}
+
+// NewMp4sBox - Create new mp4s sample entry with esds child
+func NewMp4sBox(esds *EsdsBox) *Mp4sBox {
+	b := &Mp4sBox{DataReferenceIndex: 1}
+	if esds != nil {
+		b.AddChild(esds)
+	}
+	return b
+}
+
+// AddChild - add a child box (esds normally)
+func (b *Mp4sBox) AddChild(child Box) {
+	if esds, ok := child.(*EsdsBox); ok {
+		b.Esds = esds
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecoderSpecificInfo - DecoderSpecificInfo bytes from esds or nil if no esds
+func (b *Mp4sBox) DecoderSpecificInfo() []byte {
+	if b.Esds == nil {
+		return nil
+	}
+	return b.Esds.DecConfigDescriptor.DecSpecificInfo.DecConfig
+}
+
+// DecodeMp4s - box-specific decode
+func DecodeMp4s(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeMp4sSR(hdr, startPos, sr)
+}
+
+// DecodeMp4sSR - box-specific decode
+func DecodeMp4sSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	payloadLen := hdr.payloadLen()
+	initPos := sr.GetPos()
+	b := Mp4sBox{}
+	// 14496-12 8.5.2.2 Sample entry (8 bytes)
+	sr.SkipBytes(6) // Skip 6 reserved bytes
+	b.DataReferenceIndex = sr.ReadUint16()
+	if err := sr.AccError(); err != nil {
+		return nil, fmt.Errorf("DecodeMp4s: %w", err)
+	}
+	pos := startPos + uint64(hdr.Hdrlen+8)
+	for payloadLen-(sr.GetPos()-initPos) > 0 {
+		box, err := DecodeBoxSR(pos, sr)
+		if err != nil {
+			return nil, err
+		}
+		if box == nil {
+			return nil, fmt.Errorf("no mp4s child")
+		}
+		b.AddChild(box)
+		pos += box.Size()
+	}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *Mp4sBox) Type() string {
+	return "mp4s"
+}
+
+// Size - return calculated size
+func (b *Mp4sBox) Size() uint64 {
+	totalSize := uint64(boxHeaderSize + 8)
+	for _, child := range b.Children {
+		totalSize += child.Size()
+	}
+	return totalSize
+}
+
+// Encode - write box to w
+func (b *Mp4sBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *Mp4sBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteZeroBytes(6)
+	sw.WriteUint16(b.DataReferenceIndex)
+	for _, child := range b.Children {
+		err = child.EncodeSW(sw)
+		if err != nil {
+			return err
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write specific box info to w
+func (b *Mp4sBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - dataReferenceIndex: %d", b.DataReferenceIndex)
+	if bd.err != nil {
+		return bd.err
+	}
+	for _, child := range b.Children {
+		err := child.Info(w, specificBoxLevels, indent+indentStep, indent)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMp4s(t *testing.T) {
+	decConfig := []byte{0x00, 0x08, 0x10, 0x3f}
+	esds := CreateEsdsBox(decConfig)
+	esds.DecConfigDescriptor.ObjectType = 0x01 // Systems ISO/IEC 14496-1
+	esds.DecConfigDescriptor.StreamType = 0x0d // 0x03 << 2 + 0x01 (SceneDescriptionStream + reserved)
+	mp4s := NewMp4sBox(esds)
+	boxDiffAfterEncodeAndDecode(t, mp4s)
+
+	decMp4s := boxAfterEncodeAndDecode(t, mp4s).(*Mp4sBox)
+	if decMp4s.Esds == nil {
+		t.Fatalf("no esds in decoded mp4s")
+	}
+	if !bytes.Equal(decMp4s.DecoderSpecificInfo(), decConfig) {
+		t.Errorf("got DecoderSpecificInfo %x instead of %x", decMp4s.DecoderSpecificInfo(), decConfig)
+	}
+	if decMp4s.Esds.DecConfigDescriptor.StreamType != 0x0d {
+		t.Errorf("got streamType %02x instead of 0d", decMp4s.Esds.DecConfigDescriptor.StreamType)
+	}
+	if (&Mp4sBox{}).DecoderSpecificInfo() != nil {
+		t.Errorf("expected nil DecoderSpecificInfo without esds")
+	}
+}
+
+func TestMp4sInitSegment(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(1000, "text", "und")
+	trak := init.Moov.Trak
+	trak.Mdia.Hdlr.HandlerType = "sdsm" // Scene Description Stream
+	stsd := trak.Mdia.Minf.Stbl.Stsd
+	stsd.AddChild(NewMp4sBox(CreateEsdsBox([]byte{0x01, 0x02})))
+	if stsd.Mp4s == nil {
+		t.Fatalf("mp4s not set in stsd")
+	}
+
+	buf := bytes.Buffer{}
+	err := init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encBytes := buf.Bytes()
+	f, err := DecodeFile(bytes.NewBuffer(encBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decStsd := f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd
+	if decStsd.Mp4s == nil {
+		t.Fatalf("mp4s not decoded in stsd")
+	}
+	if !bytes.Equal(decStsd.Mp4s.DecoderSpecificInfo(), []byte{0x01, 0x02}) {
+		t.Errorf("got DecoderSpecificInfo %x", decStsd.Mp4s.DecoderSpecificInfo())
+	}
+	buf.Reset()
+	err = f.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), encBytes) {
+		t.Errorf("init segment with mp4s not identical after round-trip")
+	}
+}
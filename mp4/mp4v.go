@@ -0,0 +1,92 @@
+package mp4
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// MPEG-4 Visual (ISO/IEC 14496-2) video_object_layer_shape values
+const (
+	volShapeRectangular = 0
+	volShapeBinaryOnly  = 2
+	volShapeGrayscale   = 3
+	volExtendedPAR      = 0xf
+)
+
+// DecoderSpecificInfo - return DecoderSpecificInfo from esds (VOL header for mp4v), or nil if not present
+func (b *VisualSampleEntryBox) DecoderSpecificInfo() []byte {
+	if b.Esds == nil {
+		return nil
+	}
+	return b.Esds.DecConfigDescriptor.DecSpecificInfo.DecConfig
+}
+
+// DecodeVOLDimensions - extract width and height from MPEG-4 Visual DecoderSpecificInfo.
+// The data is searched for a video_object_layer start code (0x00000120-0x0000012f)
+// and the VOL header is parsed according to ISO/IEC 14496-2 Section 6.2.3.
+func DecodeVOLDimensions(decConfig []byte) (width, height uint16, err error) {
+	volStart := -1
+	for i := 0; i+3 < len(decConfig); i++ {
+		if decConfig[i] == 0 && decConfig[i+1] == 0 && decConfig[i+2] == 1 && decConfig[i+3]&0xf0 == 0x20 {
+			volStart = i + 4
+			break
+		}
+	}
+	if volStart < 0 {
+		return 0, 0, fmt.Errorf("no video_object_layer start code found")
+	}
+	r := bits.NewAccErrReader(bytes.NewBuffer(decConfig[volStart:]))
+	_ = r.Read(1) // random_accessible_vol
+	_ = r.Read(8) // video_object_type_indication
+	verID := uint(1)
+	if r.ReadFlag() { // is_object_layer_identifier
+		verID = r.Read(4)
+		_ = r.Read(3) // video_object_layer_priority
+	}
+	if r.Read(4) == volExtendedPAR { // aspect_ratio_info
+		_ = r.Read(8) // par_width
+		_ = r.Read(8) // par_height
+	}
+	if r.ReadFlag() { // vol_control_parameters
+		_ = r.Read(2) // chroma_format
+		_ = r.Read(1) // low_delay
+		if r.ReadFlag() { // vbv_parameters
+			_ = r.Read(16) // first_half_bit_rate + marker
+			_ = r.Read(16) // latter_half_bit_rate + marker
+			_ = r.Read(16) // first_half_vbv_buffer_size + marker
+			_ = r.Read(3)  // latter_half_vbv_buffer_size
+			_ = r.Read(12) // first_half_vbv_occupancy + marker
+			_ = r.Read(16) // latter_half_vbv_occupancy + marker
+		}
+	}
+	shape := r.Read(2)
+	if shape == volShapeGrayscale && verID != 1 {
+		_ = r.Read(4) // video_object_layer_shape_extension
+	}
+	_ = r.Read(1) // marker_bit
+	timeIncrementResolution := r.Read(16)
+	_ = r.Read(1) // marker_bit
+	if r.ReadFlag() { // fixed_vop_rate
+		nrBits := 1
+		for (1 << nrBits) < int(timeIncrementResolution) {
+			nrBits++
+		}
+		_ = r.Read(nrBits) // fixed_vop_time_increment
+	}
+	if shape == volShapeBinaryOnly {
+		return 0, 0, fmt.Errorf("no dimensions for binary only shape")
+	}
+	if shape != volShapeRectangular {
+		return 0, 0, fmt.Errorf("dimensions only available for rectangular shape, not %d", shape)
+	}
+	_ = r.Read(1) // marker_bit
+	width = uint16(r.Read(13))
+	_ = r.Read(1) // marker_bit
+	height = uint16(r.Read(13))
+	if r.AccError() != nil {
+		return 0, 0, fmt.Errorf("decode VOL: %w", r.AccError())
+	}
+	return width, height, nil
+}
@@ -0,0 +1,70 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// createTestVOL - create a simple rectangular VOL header with given dimensions
+func createTestVOL(width, height uint16) []byte {
+	sw := bits.NewFixedSliceWriter(20)
+	sw.WriteUint32(0x00000120) // video_object_layer_start_code
+	sw.WriteBits(0, 1)         // random_accessible_vol
+	sw.WriteBits(1, 8)         // video_object_type_indication (Simple Object)
+	sw.WriteBits(1, 1)         // is_object_layer_identifier
+	sw.WriteBits(1, 4)         // video_object_layer_verid
+	sw.WriteBits(1, 3)         // video_object_layer_priority
+	sw.WriteBits(1, 4)         // aspect_ratio_info (square)
+	sw.WriteBits(0, 1)         // vol_control_parameters
+	sw.WriteBits(0, 2)         // video_object_layer_shape (rectangular)
+	sw.WriteBits(1, 1)         // marker_bit
+	sw.WriteBits(25, 16)       // vop_time_increment_resolution
+	sw.WriteBits(1, 1)         // marker_bit
+	sw.WriteBits(1, 1)         // fixed_vop_rate
+	sw.WriteBits(1, 5)         // fixed_vop_time_increment (5 bits for resolution 25)
+	sw.WriteBits(1, 1)         // marker_bit
+	sw.WriteBits(uint(width), 13)
+	sw.WriteBits(1, 1) // marker_bit
+	sw.WriteBits(uint(height), 13)
+	sw.WriteBits(1, 1) // marker_bit
+	sw.FlushBits()
+	return sw.Bytes()
+}
+
+func TestMp4vSampleEntry(t *testing.T) {
+	vol := createTestVOL(352, 288)
+	width, height, err := DecodeVOLDimensions(vol)
+	if err != nil {
+		t.Error(err)
+	}
+	if width != 352 || height != 288 {
+		t.Errorf("got %dx%d instead of 352x288", width, height)
+	}
+
+	esds := CreateEsdsBox(vol)
+	esds.DecConfigDescriptor.ObjectType = 0x20 // Visual ISO/IEC 14496-2
+	esds.DecConfigDescriptor.StreamType = 0x11 // 0x4 << 2 + 0x01 (visualType + upstreamFlag + reserved)
+	mp4v := CreateVisualSampleEntryBox("mp4v", width, height, esds)
+	stsd := NewStsdBox()
+	stsd.AddChild(mp4v)
+	if stsd.Mp4v != mp4v {
+		t.Errorf("mp4v not set in stsd")
+	}
+
+	decStsd := boxAfterEncodeAndDecode(t, stsd).(*StsdBox)
+	decMp4v := decStsd.Mp4v
+	if decMp4v == nil || decMp4v.Esds == nil {
+		t.Fatalf("mp4v with esds not decoded")
+	}
+	if !bytes.Equal(decMp4v.DecoderSpecificInfo(), vol) {
+		t.Errorf("got VOL %x instead of %x", decMp4v.DecoderSpecificInfo(), vol)
+	}
+	boxDiffAfterEncodeAndDecode(t, mp4v)
+
+	_, _, err = DecodeVOLDimensions([]byte{0, 0, 1, 0xb0, 0x01})
+	if err == nil {
+		t.Errorf("expected error for missing VOL start code")
+	}
+}
@@ -15,6 +15,7 @@ type MvexBox struct {
 	Mehd     *MehdBox
 	Trex     *TrexBox
 	Trexs    []*TrexBox
+	Leva     *LevaBox
 	Children []Box
 }
 
@@ -33,6 +34,8 @@ func (m *MvexBox) AddChild(child Box) {
 			m.Trex = box
 		}
 		m.Trexs = append(m.Trexs, box)
+	case *LevaBox:
+		m.Leva = box
 	}
 	m.Children = append(m.Children, child)
 }
@@ -0,0 +1,130 @@
+package mp4
+
+import (
+	"fmt"
+	"math"
+)
+
+// quickTimeTopLevelBoxes - top-level QuickTime atoms without meaning in ISO BMFF
+var quickTimeTopLevelBoxes = map[string]bool{"wide": true, "pnot": true}
+
+// NormalizeToMP4 - turn a progressive QuickTime file into a clean ISO BMFF file.
+// The top-level wide and pnot atoms are removed, and so are gmhd boxes in tracks that are not text tracks.
+// QuickTime meta boxes get version and flags, and ftyp is set (or added) with major brand isom and
+// compatible brands isom, iso2, and mp41. Sample data is not changed, but chunk offsets are updated
+// to the new positions of the mdat boxes.
+func (f *File) NormalizeToMP4() error {
+	if f.isFragmented || f.Moov == nil {
+		return fmt.Errorf("only available for progressive files")
+	}
+	oldMdatStarts := f.mdatPositions()
+
+	var children []Box
+	for _, c := range f.Children {
+		if !quickTimeTopLevelBoxes[c.Type()] {
+			children = append(children, c)
+		}
+	}
+	ftyp := NewFtyp("isom", 0x200, []string{"isom", "iso2", "mp41"})
+	if f.Ftyp != nil {
+		for i, c := range children {
+			if c == f.Ftyp {
+				children[i] = ftyp
+			}
+		}
+	} else {
+		children = append([]Box{ftyp}, children...)
+	}
+	f.Ftyp = ftyp
+	f.Children = children
+
+	for _, trak := range f.Moov.Traks {
+		if trak.Mdia.Hdlr != nil && trak.Mdia.Hdlr.HandlerType != "text" {
+			removeChildBox(&trak.Mdia.Minf.Children, "gmhd")
+		}
+	}
+	for _, c := range f.Children {
+		setFullBoxMeta(c)
+	}
+
+	return f.moveAllChunkOffsets(oldMdatStarts)
+}
+
+// mdatPosition - start position and size of an mdat box
+type mdatPosition struct {
+	mdat  *MdatBox
+	start uint64
+	size  uint64
+}
+
+// mdatPositions - positions of all top-level mdat boxes given by the sizes of the preceding boxes.
+// The StartPos of each mdat is updated to the calculated position.
+func (f *File) mdatPositions() []mdatPosition {
+	var positions []mdatPosition
+	var pos uint64
+	for _, c := range f.Children {
+		size := c.Size()
+		if mdat, ok := c.(*MdatBox); ok {
+			mdat.StartPos = pos
+			positions = append(positions, mdatPosition{mdat: mdat, start: pos, size: size})
+		}
+		pos += size
+	}
+	return positions
+}
+
+// moveAllChunkOffsets - move the chunk offsets of all tracks in a progressive file from the mdat positions
+// oldPositions, given by mdatPositions before boxes were changed, to the current mdat positions.
+// This is needed after any change of the size of moov or other boxes in front of an mdat box.
+func (f *File) moveAllChunkOffsets(oldPositions []mdatPosition) error {
+	if f.isFragmented || f.Moov == nil {
+		return nil
+	}
+	newPositions := f.mdatPositions()
+	for _, trak := range f.Moov.Traks {
+		if err := moveChunkOffsets(trak.Mdia.Minf.Stbl, oldPositions, newPositions); err != nil {
+			return fmt.Errorf("track %d: %w", trak.Tkhd.TrackID, err)
+		}
+	}
+	return nil
+}
+
+// setFullBoxMeta - make all QuickTime meta boxes in the box tree of b into FullBox meta boxes
+func setFullBoxMeta(b Box) {
+	if meta, ok := b.(*MetaBox); ok {
+		meta.IsQuickTime = false
+	}
+	if c, ok := b.(ContainerBox); ok {
+		for _, child := range c.GetChildren() {
+			setFullBoxMeta(child)
+		}
+	}
+}
+
+// moveChunkOffsets - move chunk offsets in stbl from old to new mdat positions.
+// Offsets outside all mdat boxes are left unchanged.
+func moveChunkOffsets(stbl *StblBox, oldPositions, newPositions []mdatPosition) error {
+	move := func(offset uint64) uint64 {
+		for i, old := range oldPositions {
+			if offset >= old.start && offset < old.start+old.size {
+				return offset - old.start + newPositions[i].start
+			}
+		}
+		return offset
+	}
+	if stbl.Co64 != nil {
+		for i, offset := range stbl.Co64.ChunkOffset {
+			stbl.Co64.ChunkOffset[i] = move(offset)
+		}
+	}
+	if stbl.Stco != nil {
+		for i, offset := range stbl.Stco.ChunkOffset {
+			newOffset := move(uint64(offset))
+			if newOffset > math.MaxUint32 {
+				return fmt.Errorf("chunk offset %d too big for stco", newOffset)
+			}
+			stbl.Stco.ChunkOffset[i] = uint32(newOffset)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// rawBox - decode box from header and payload, giving an UnknownBox for unregistered types
+func rawBox(t *testing.T, boxType string, payload []byte) Box {
+	t.Helper()
+	size := 8 + len(payload)
+	data := append([]byte{byte(size >> 24), byte(size >> 16), byte(size >> 8), byte(size)}, boxType...)
+	box, err := DecodeBox(0, bytes.NewBuffer(append(data, payload...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return box
+}
+
+// makeQuickTimeFile - prog_8s.mp4 with QuickTime brand, wide and pnot atoms, gmhd, and a QuickTime meta box
+func makeQuickTimeFile(t *testing.T) []byte {
+	t.Helper()
+	f, err := ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldMdatPayload := f.Mdat.PayloadAbsoluteOffset()
+	hdlr, err := CreateHdlr("mdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := CreateMetaBox(0, hdlr)
+	meta.IsQuickTime = true
+	meta.AddChild(&IlstBox{})
+	udta := &UdtaBox{}
+	udta.AddChild(meta)
+	f.Moov.AddChild(udta)
+	minf := f.Moov.Traks[1].Mdia.Minf
+	minf.AddChild(rawBox(t, "gmhd", nil))
+
+	qt := NewFile()
+	var pos uint64
+	for _, c := range f.Children {
+		switch c.Type() {
+		case "ftyp":
+			c = NewFtyp("qt  ", 0x20050300, []string{"qt  "})
+			qt.AddChild(c, pos)
+			pos += c.Size()
+			c = rawBox(t, "pnot", make([]byte, 12))
+		case "mdat":
+			wide := rawBox(t, "wide", nil)
+			qt.AddChild(wide, pos)
+			pos += wide.Size()
+			c.(*MdatBox).StartPos = pos
+		}
+		qt.AddChild(c, pos)
+		pos += c.Size()
+	}
+	delta := uint32(qt.Mdat.PayloadAbsoluteOffset() - oldMdatPayload)
+	for _, trak := range qt.Moov.Traks {
+		stco := trak.Mdia.Minf.Stbl.Stco
+		for i := range stco.ChunkOffset {
+			stco.ChunkOffset[i] += delta
+		}
+	}
+	var buf bytes.Buffer
+	if err = qt.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestNormalizeToMP4(t *testing.T) {
+	orig, err := ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(bytes.NewBuffer(makeQuickTimeFile(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trak := range orig.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		origSamples, err := orig.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		qtSamples, err := f.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(qtSamples, origSamples); diff != nil {
+			t.Fatalf("track %d: bad QuickTime test file: %v", trackID, diff)
+		}
+	}
+	qtUdta := f.Moov.Children[len(f.Moov.Children)-1].(*UdtaBox)
+	if meta := qtUdta.Children[0].(*MetaBox); !meta.IsQuickTime || meta.Hdlr == nil {
+		t.Errorf("QuickTime meta box not decoded")
+	}
+	if err = f.NormalizeToMP4(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Ftyp == nil || out.Ftyp.MajorBrand() != "isom" {
+		t.Errorf("major brand is not isom")
+	}
+	if diff := deep.Equal(out.Ftyp.CompatibleBrands(), []string{"isom", "iso2", "mp41"}); diff != nil {
+		t.Errorf("compatible brands: %v", diff)
+	}
+	for _, c := range out.Children {
+		if c.Type() == "wide" || c.Type() == "pnot" {
+			t.Errorf("%s box not removed", c.Type())
+		}
+	}
+	for _, trak := range out.Moov.Traks {
+		for _, c := range trak.Mdia.Minf.Children {
+			if c.Type() == "gmhd" {
+				t.Errorf("track %d: gmhd not removed", trak.Tkhd.TrackID)
+			}
+		}
+	}
+	udta := out.Moov.Children[len(out.Moov.Children)-1].(*UdtaBox)
+	if meta := udta.Children[0].(*MetaBox); meta.IsQuickTime || meta.Hdlr == nil {
+		t.Errorf("meta box is not a FullBox with hdlr")
+	}
+	for _, trak := range orig.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		origSamples, err := orig.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outSamples, err := out.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(outSamples, origSamples); diff != nil {
+			t.Errorf("track %d: samples changed: %v", trackID, diff)
+		}
+	}
+}
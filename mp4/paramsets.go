@@ -0,0 +1,51 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/avc"
+	"github.com/edgeware/mp4ff/hevc"
+)
+
+// UniqueParameterSets - inband parameter sets in the samples of an AVC or HEVC track, deduplicated by content.
+// The parameter sets are returned in order of first appearance. vps is only set for HEVC tracks.
+// Parameter sets in the sample description (avcC/hvcC) are not included. rs is needed if the mdat is lazily decoded.
+func (f *File) UniqueParameterSets(trackID uint32, rs io.ReadSeeker) (sps, pps, vps [][]byte, err error) {
+	trak := f.getTrak(trackID)
+	if trak == nil {
+		return nil, nil, nil, fmt.Errorf("no track with trackID %d", trackID)
+	}
+	stsd := trak.Mdia.Minf.Stbl.Stsd
+	if stsd.AvcX == nil && stsd.HvcX == nil {
+		return nil, nil, nil, fmt.Errorf("track %d is not AVC or HEVC", trackID)
+	}
+	samples, err := f.fullSamplesForTrack(trackID, rs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	seen := make(map[string]bool)
+	addUnique := func(list [][]byte, nalus [][]byte) [][]byte {
+		for _, nalu := range nalus {
+			if seen[string(nalu)] {
+				continue
+			}
+			seen[string(nalu)] = true
+			list = append(list, nalu)
+		}
+		return list
+	}
+	for _, s := range samples {
+		if stsd.AvcX != nil {
+			spss, ppss := avc.GetParameterSets(s.Data)
+			sps = addUnique(sps, spss)
+			pps = addUnique(pps, ppss)
+			continue
+		}
+		vpss, spss, ppss := hevc.GetParameterSets(s.Data)
+		vps = addUnique(vps, vpss)
+		sps = addUnique(sps, spss)
+		pps = addUnique(pps, ppss)
+	}
+	return sps, pps, vps, nil
+}
@@ -0,0 +1,75 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+func TestUniqueParameterSets(t *testing.T) {
+	sps, _ := hex.DecodeString(sps1nalu)
+	pps, _ := hex.DecodeString(pps1nalu)
+	pps2 := []byte{0x68, 0x5b, 0xdf, 0x21}
+	idr := []byte{0x65, 0x88, 0x84, 0x00}
+	lengthPrefixed := func(nalus ...[]byte) []byte {
+		var data []byte
+		for _, nalu := range nalus {
+			lenBytes := make([]byte, 4)
+			binary.BigEndian.PutUint32(lenBytes, uint32(len(nalu)))
+			data = append(data, lenBytes...)
+			data = append(data, nalu...)
+		}
+		return data
+	}
+	sampleDatas := [][]byte{
+		lengthPrefixed(sps, pps, idr),
+		lengthPrefixed(sps, pps, idr),
+		lengthPrefixed(sps, pps2, idr),
+	}
+
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	err := init.Moov.Trak.SetAVCDescriptor("avc3", [][]byte{sps}, [][]byte{pps}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for i, data := range sampleDatas {
+		frag, err := CreateFragment(uint32(i+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frag.AddFullSample(FullSample{
+			Sample:     NewSample(SyncSampleFlags, 3000, uint32(len(data)), 0),
+			DecodeTime: uint64(i * 3000),
+			Data:       data,
+		})
+		if err = frag.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotSPS, gotPPS, gotVPS, err := f.UniqueParameterSets(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotSPS) != 1 || !bytes.Equal(gotSPS[0], sps) {
+		t.Errorf("got %d SPS instead of 1 unique", len(gotSPS))
+	}
+	if len(gotPPS) != 2 || !bytes.Equal(gotPPS[0], pps) || !bytes.Equal(gotPPS[1], pps2) {
+		t.Errorf("got %d PPS instead of 2 unique", len(gotPPS))
+	}
+	if len(gotVPS) != 0 {
+		t.Errorf("got %d VPS for AVC track", len(gotVPS))
+	}
+	if _, _, _, err = f.UniqueParameterSets(2, nil); err == nil {
+		t.Errorf("expected error for non-existing track")
+	}
+}
@@ -0,0 +1,103 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// PcmCBox - PCM Configuration Box (pcmC), ISO/IEC 23003-5 Section 5.2
+//
+// Contained in : Uncompressed Audio Sample Entry (ipcm for integer and fpcm for floating-point samples)
+type PcmCBox struct {
+	Version       byte
+	Flags         uint32
+	FormatFlags   byte // Bit 0 (least significant) set for little-endian samples
+	PCMSampleSize byte // Bits per sample
+}
+
+// pcmLittleEndianFlag - format_flags bit for little-endian sample format
+const pcmLittleEndianFlag = 0x01
+
+// CreatePcmC - create pcmC box with bit depth and endianness
+func CreatePcmC(pcmSampleSize byte, littleEndian bool) *PcmCBox {
+	b := &PcmCBox{PCMSampleSize: pcmSampleSize}
+	if littleEndian {
+		b.FormatFlags = pcmLittleEndianFlag
+	}
+	return b
+}
+
+// DecodePcmC - box-specific decode
+func DecodePcmC(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodePcmCSR(hdr, startPos, sr)
+}
+
+// DecodePcmCSR - box-specific decode
+func DecodePcmCSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := PcmCBox{
+		Version:       byte(versionAndFlags >> 24),
+		Flags:         versionAndFlags & flagsMask,
+		FormatFlags:   sr.ReadUint8(),
+		PCMSampleSize: sr.ReadUint8(),
+	}
+	return &b, sr.AccError()
+}
+
+// BitDepth - number of bits per PCM sample
+func (b *PcmCBox) BitDepth() int {
+	return int(b.PCMSampleSize)
+}
+
+// IsLittleEndian - true if samples are little-endian, otherwise they are big-endian
+func (b *PcmCBox) IsLittleEndian() bool {
+	return b.FormatFlags&pcmLittleEndianFlag != 0
+}
+
+// Type - box type
+func (b *PcmCBox) Type() string {
+	return "pcmC"
+}
+
+// Size - calculated size of box
+func (b *PcmCBox) Size() uint64 {
+	return boxHeaderSize + 6
+}
+
+// Encode - write box to w
+func (b *PcmCBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *PcmCBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint8(b.FormatFlags)
+	sw.WriteUint8(b.PCMSampleSize)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *PcmCBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - formatFlags: %d (littleEndian=%t)", b.FormatFlags, b.IsLittleEndian())
+	bd.write(" - pcmSampleSize: %d", b.PCMSampleSize)
+	return bd.err
+}
@@ -0,0 +1,120 @@
+package mp4
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// rechunkChunk - a new chunk of consecutive samples from one track
+type rechunkChunk struct {
+	trakNr    int
+	startTime uint64 // decode time of first sample in track timescale
+	timescale uint32
+	data      [][]byte
+	offset    uint64 // offset relative to mdat payload start
+}
+
+// Rechunk - regroup the samples of a progressive file into chunks of approximately chunkDurMs milliseconds.
+// A new chunk is started when the accumulated duration reaches the target, so sample order is kept.
+// The mdat data is rewritten with the chunks of all tracks interleaved in decode time order,
+// and stsc and stco/co64 are rebuilt. The mdat must be read into memory (not lazily decoded).
+func (f *File) Rechunk(chunkDurMs uint32) error {
+	if f.isFragmented {
+		return fmt.Errorf("only available for progressive files")
+	}
+	if f.Moov == nil || f.Mdat == nil {
+		return fmt.Errorf("no moov or mdat box")
+	}
+	if f.Mdat.IsLazy() {
+		return fmt.Errorf("lazy mdat not supported")
+	}
+	if chunkDurMs == 0 {
+		return fmt.Errorf("chunk duration must be positive")
+	}
+	var chunks []*rechunkChunk
+	samplesPerChunk := make([][]uint32, len(f.Moov.Traks))
+	for trakNr, trak := range f.Moov.Traks {
+		stsc := trak.Mdia.Minf.Stbl.Stsc
+		if len(stsc.SampleDescriptionID) > 0 {
+			return fmt.Errorf("track %d: multiple sample descriptions not supported", trak.Tkhd.TrackID)
+		}
+		samples, err := f.fullSamplesForTrack(trak.Tkhd.TrackID, nil)
+		if err != nil {
+			return fmt.Errorf("track %d: %w", trak.Tkhd.TrackID, err)
+		}
+		timescale := trak.Mdia.Mdhd.Timescale
+		chunkDur := uint64(chunkDurMs) * uint64(timescale) / 1000
+		var chunk *rechunkChunk
+		var accDur uint64
+		for _, s := range samples {
+			if chunk == nil || accDur >= chunkDur {
+				chunk = &rechunkChunk{trakNr: trakNr, startTime: s.DecodeTime, timescale: timescale}
+				chunks = append(chunks, chunk)
+				samplesPerChunk[trakNr] = append(samplesPerChunk[trakNr], 0)
+				accDur = 0
+			}
+			chunk.data = append(chunk.data, s.Data)
+			samplesPerChunk[trakNr][len(samplesPerChunk[trakNr])-1]++
+			accDur += uint64(s.Dur)
+		}
+	}
+	// Interleave chunks in time order. Chunks of one track are already in order
+	sort.SliceStable(chunks, func(i, j int) bool {
+		a, b := chunks[i], chunks[j]
+		return a.startTime*uint64(b.timescale) < b.startTime*uint64(a.timescale)
+	})
+	var mdatData []byte
+	for _, c := range chunks {
+		c.offset = uint64(len(mdatData))
+		for _, d := range c.data {
+			mdatData = append(mdatData, d...)
+		}
+	}
+	// Rebuild stsc and chunk offset tables before calculating offsets, since they change the moov size
+	for trakNr, trak := range f.Moov.Traks {
+		stbl := trak.Mdia.Minf.Stbl
+		stsc := stbl.Stsc
+		sdi := stsc.GetSampleDescriptionID(1)
+		stsc.FirstChunk, stsc.SamplesPerChunk = nil, nil
+		for i, nr := range samplesPerChunk[trakNr] {
+			if i == 0 || nr != samplesPerChunk[trakNr][i-1] {
+				stsc.FirstChunk = append(stsc.FirstChunk, uint32(i+1))
+				stsc.SamplesPerChunk = append(stsc.SamplesPerChunk, nr)
+			}
+		}
+		stsc.SetSingleSampleDescriptionID(sdi)
+		nrChunks := len(samplesPerChunk[trakNr])
+		if stbl.Co64 != nil {
+			stbl.Co64.ChunkOffset = make([]uint64, nrChunks)
+		} else {
+			stbl.Stco.ChunkOffset = make([]uint32, nrChunks)
+		}
+	}
+	f.Mdat.SetData(mdatData)
+	f.Mdat.DataParts = nil
+	var mdatStart uint64
+	for _, c := range f.Children {
+		if c == f.Mdat {
+			break
+		}
+		mdatStart += c.Size()
+	}
+	f.Mdat.StartPos = mdatStart
+	payloadStart := f.Mdat.PayloadAbsoluteOffset()
+	chunkNrs := make([]int, len(f.Moov.Traks))
+	for _, c := range chunks {
+		stbl := f.Moov.Traks[c.trakNr].Mdia.Minf.Stbl
+		offset := payloadStart + c.offset
+		if stbl.Co64 != nil {
+			stbl.Co64.ChunkOffset[chunkNrs[c.trakNr]] = offset
+		} else {
+			if offset > math.MaxUint32 {
+				return fmt.Errorf("chunk offset %d too big for stco", offset)
+			}
+			stbl.Stco.ChunkOffset[chunkNrs[c.trakNr]] = uint32(offset)
+		}
+		chunkNrs[c.trakNr]++
+	}
+	return nil
+}
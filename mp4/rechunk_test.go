@@ -0,0 +1,83 @@
+package mp4
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestRechunk(t *testing.T) {
+	fd, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	f, err := DecodeFile(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	origSamples := make(map[uint32][]FullSample)
+	for _, trak := range f.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		origSamples[trackID], err = f.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	chunkDurMs := uint32(500)
+	err = f.Rechunk(chunkDurMs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = f.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFile, err := DecodeFile(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trak := range decFile.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		samples, err := decFile.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		orig := origSamples[trackID]
+		if len(samples) != len(orig) {
+			t.Fatalf("track %d: got %d samples instead of %d", trackID, len(samples), len(orig))
+		}
+		for i := range samples {
+			if !bytes.Equal(samples[i].Data, orig[i].Data) {
+				t.Errorf("track %d: sample %d data differs", trackID, i+1)
+				break
+			}
+		}
+		stbl := trak.Mdia.Minf.Stbl
+		chunkDur := uint64(chunkDurMs) * uint64(trak.Mdia.Mdhd.Timescale) / 1000
+		nrChunks := uint32(len(stbl.Stco.ChunkOffset))
+		for chunkNr := uint32(1); chunkNr < nrChunks; chunkNr++ {
+			chunk := stbl.Stsc.GetChunk(chunkNr)
+			var dur, lastDur uint64
+			for nr := chunk.StartSampleNr; nr < chunk.StartSampleNr+chunk.NrSamples; nr++ {
+				lastDur = uint64(samples[nr-1].Dur)
+				dur += lastDur
+			}
+			if dur < chunkDur || dur-lastDur >= chunkDur {
+				t.Errorf("track %d chunk %d: duration %d does not match target %d", trackID, chunkNr, dur, chunkDur)
+			}
+		}
+		for i := 1; i < int(nrChunks); i++ {
+			if stbl.Stco.ChunkOffset[i] <= stbl.Stco.ChunkOffset[i-1] {
+				t.Errorf("track %d: chunk offsets not increasing", trackID)
+			}
+		}
+	}
+	// Chunks of the two tracks should be interleaved, so both first chunks come before the second ones
+	offsets0 := decFile.Moov.Traks[0].Mdia.Minf.Stbl.Stco.ChunkOffset
+	offsets1 := decFile.Moov.Traks[1].Mdia.Minf.Stbl.Stco.ChunkOffset
+	if offsets0[0] > offsets1[1] || offsets1[0] > offsets0[1] {
+		t.Errorf("chunks not interleaved")
+	}
+}
@@ -0,0 +1,94 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// RegisterBoxDecoder - register decoders for boxType, replacing any previous decoders for that type.
+// Both decoder and decoderSR should be given, since both decode paths are used.
+// Registration is not safe for concurrent use and should be done before any decoding, e.g. in an init function.
+func RegisterBoxDecoder(boxType string, decoder BoxDecoder, decoderSR BoxDecoderSR) {
+	decoders[boxType] = decoder
+	decodersSR[boxType] = decoderSR
+}
+
+// RegisterContainerType - register boxType as a container box, so that its children are decoded
+// with the standard box decoders into a GenericContainerBox.
+// Registration is not safe for concurrent use and should be done before any decoding, e.g. in an init function.
+func RegisterContainerType(boxType string) {
+	RegisterBoxDecoder(boxType, DecodeGenericContainer, DecodeGenericContainerSR)
+}
+
+// GenericContainerBox - container box of a type registered by RegisterContainerType, e.g. a vendor box
+type GenericContainerBox struct {
+	Name     string
+	Children []Box
+}
+
+// NewGenericContainerBox - create an empty container box of type boxType
+func NewGenericContainerBox(boxType string) *GenericContainerBox {
+	return &GenericContainerBox{Name: boxType}
+}
+
+// AddChild - Add a child box
+func (b *GenericContainerBox) AddChild(box Box) {
+	b.Children = append(b.Children, box)
+}
+
+// DecodeGenericContainer - box-specific decode
+func DecodeGenericContainer(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.Size, r)
+	if err != nil {
+		return nil, err
+	}
+	b := GenericContainerBox{Name: hdr.Name, Children: make([]Box, 0, len(children))}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return &b, nil
+}
+
+// DecodeGenericContainerSR - box-specific decode
+func DecodeGenericContainerSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	b := GenericContainerBox{Name: hdr.Name, Children: make([]Box, 0, len(children))}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return &b, nil
+}
+
+// Type - box type
+func (b *GenericContainerBox) Type() string {
+	return b.Name
+}
+
+// Size - calculated size of box
+func (b *GenericContainerBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *GenericContainerBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write container to w
+func (b *GenericContainerBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write container to sw
+func (b *GenericContainerBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *GenericContainerBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
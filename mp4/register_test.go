@@ -0,0 +1,58 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+func TestRegisterContainerType(t *testing.T) {
+	RegisterContainerType("xxxx")
+	defer func() {
+		delete(decoders, "xxxx")
+		delete(decodersSR, "xxxx")
+	}()
+	vendor := NewGenericContainerBox("xxxx")
+	vendor.AddChild(&KindBox{SchemeURI: "urn:mpeg:dash:role:2011", Value: "main"})
+	vendor.AddChild(&FreeBox{Name: "free", notDecoded: []byte{0, 1, 2}})
+	udta := &UdtaBox{}
+	udta.AddChild(vendor)
+
+	var buf bytes.Buffer
+	if err := udta.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	for _, useSR := range []bool{false, true} {
+		var box Box
+		var err error
+		if useSR {
+			box, err = DecodeBoxSR(0, bits.NewFixedSliceReader(encoded))
+		} else {
+			box, err = DecodeBox(0, bytes.NewBuffer(encoded))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		decUdta := box.(*UdtaBox)
+		decVendor, ok := decUdta.Children[0].(*GenericContainerBox)
+		if !ok {
+			t.Fatalf("vendor box decoded as %T", decUdta.Children[0])
+		}
+		if decVendor.Type() != "xxxx" || len(decVendor.Children) != 2 {
+			t.Fatalf("got %s with %d children", decVendor.Type(), len(decVendor.Children))
+		}
+		kind, ok := decVendor.Children[0].(*KindBox)
+		if !ok || kind.Value != "main" {
+			t.Errorf("kind child not parsed")
+		}
+		var outBuf bytes.Buffer
+		if err = decUdta.Encode(&outBuf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(outBuf.Bytes(), encoded) {
+			t.Errorf("re-encoded box differs")
+		}
+	}
+}
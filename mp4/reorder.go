@@ -0,0 +1,65 @@
+package mp4
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FragmentedReorderDepth - maximum number of samples that precede a sample in decode order but
+// follow it in presentation order, for trackID in all fragments of a fragmented file.
+// Decode times are given by tfdt and the sample durations, and presentation times by the trun
+// composition time offsets. The result is 0 if there is no reordering, such as for streams without B-frames.
+func (f *File) FragmentedReorderDepth(trackID uint32) (int, error) {
+	if !f.isFragmented {
+		return 0, fmt.Errorf("only available for fragmented files")
+	}
+	trex := f.getTrex(trackID)
+	var presTimes []int64
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID != trackID {
+					continue
+				}
+				if traf.Tfdt == nil {
+					return 0, fmt.Errorf("no tfdt for track %d in fragment %d",
+						trackID, frag.Moof.Mfhd.SequenceNumber)
+				}
+				decTime := int64(traf.Tfdt.BaseMediaDecodeTime)
+				for _, trun := range traf.Truns {
+					trun.AddSampleDefaultValues(traf.Tfhd, trex)
+					for _, s := range trun.Samples {
+						presTimes = append(presTimes, decTime+int64(s.CompositionTimeOffset))
+						decTime += int64(s.Dur)
+					}
+				}
+			}
+		}
+	}
+	return reorderDepth(presTimes), nil
+}
+
+// reorderDepth - maximum number of earlier entries with larger value than an entry in presTimes (decode order).
+// A Fenwick tree over the ranks of the presentation times gives O(n log n) complexity.
+func reorderDepth(presTimes []int64) int {
+	sorted := make([]int64, len(presTimes))
+	copy(sorted, presTimes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	tree := make([]int, len(sorted)+1)
+	maxDepth := 0
+	for i, pt := range presTimes {
+		// Number of earlier samples with presentation time <= pt
+		rank := sort.Search(len(sorted), func(k int) bool { return sorted[k] > pt })
+		nrNotLater := 0
+		for r := rank; r > 0; r -= r & -r {
+			nrNotLater += tree[r]
+		}
+		if depth := i - nrNotLater; depth > maxDepth {
+			maxDepth = depth
+		}
+		for r := rank; r < len(tree); r += r & -r {
+			tree[r]++
+		}
+	}
+	return maxDepth
+}
@@ -0,0 +1,57 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFragmentedReorderDepth(t *testing.T) {
+	// Presentation order index of samples in decode order for a hierarchical B-frame GOP
+	bPyramid := []int{0, 8, 4, 2, 1, 3, 6, 5, 7}
+	noBFrames := []int{0, 1, 2, 3, 4, 5, 6, 7, 8}
+	testCases := []struct {
+		desc   string
+		gop    []int
+		wanted int
+	}{
+		{"B-pyramid", bPyramid, 3},
+		{"no B-frames", noBFrames, 0},
+	}
+	for _, tc := range testCases {
+		init := CreateEmptyInit()
+		init.AddEmptyTrack(90000, "video", "und")
+		var buf bytes.Buffer
+		if err := init.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		dur := uint32(3000)
+		for fragNr := 0; fragNr < 2; fragNr++ {
+			frag, err := CreateFragment(uint32(fragNr+1), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, presIdx := range tc.gop {
+				cto := int32(presIdx-i+1) * int32(dur)
+				frag.AddFullSample(FullSample{
+					Sample:     NewSample(NonSyncSampleFlags, dur, 1, cto),
+					DecodeTime: uint64((fragNr*len(tc.gop) + i)) * uint64(dur),
+					Data:       []byte{0},
+				})
+			}
+			if err = frag.Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		f, err := DecodeFile(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		depth, err := f.FragmentedReorderDepth(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if depth != tc.wanted {
+			t.Errorf("%s: got reorder depth %d instead of %d", tc.desc, depth, tc.wanted)
+		}
+	}
+}
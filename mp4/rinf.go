@@ -0,0 +1,84 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// RinfBox - Restricted Scheme Information Box according to ISO/IEC 14496-12 Section 8.15.3
+type RinfBox struct {
+	Frma     *FrmaBox // Mandatory
+	Schm     *SchmBox // Mandatory
+	Schi     *SchiBox // Optional
+	Children []Box
+}
+
+// AddChild - Add a child box
+func (b *RinfBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *FrmaBox:
+		b.Frma = box
+	case *SchmBox:
+		b.Schm = box
+	case *SchiBox:
+		b.Schi = box
+	}
+	b.Children = append(b.Children, child)
+}
+
+// DecodeRinf - box-specific decode
+func DecodeRinf(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.Size, r)
+	if err != nil {
+		return nil, err
+	}
+	b := RinfBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return &b, nil
+}
+
+// DecodeRinfSR - box-specific decode
+func DecodeRinfSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.Size, sr)
+	if err != nil {
+		return nil, err
+	}
+	b := RinfBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return &b, sr.AccError()
+}
+
+// Type - box type
+func (b *RinfBox) Type() string {
+	return "rinf"
+}
+
+// Size - calculated size of box
+func (b *RinfBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *RinfBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write rinf container to w
+func (b *RinfBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// Encode - write rinf container to sw
+func (b *RinfBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *RinfBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
@@ -0,0 +1,70 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// stviHex - stvi box with stereo_scheme 4 (ISO/IEC 23000-11) and side-by-side frame packing
+const stviHex = "0000001a73747669000000000000000000000004000000020003"
+
+func TestRestrictedVideoSampleEntry(t *testing.T) {
+	vps, _ := hex.DecodeString(vpsHex)
+	sps, _ := hex.DecodeString(spsHex)
+	pps, _ := hex.DecodeString(ppsHex)
+	hvcC, err := CreateHvcC([][]byte{vps}, [][]byte{sps}, [][]byte{pps}, true, true, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stviBytes, _ := hex.DecodeString(stviHex)
+	stvi, err := DecodeBox(0, bytes.NewBuffer(stviBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schi := &SchiBox{}
+	schi.AddChild(stvi)
+	rinf := &RinfBox{}
+	rinf.AddChild(&FrmaBox{DataFormat: "hvc1"})
+	rinf.AddChild(&SchmBox{SchemeType: "stvi", SchemeVersion: 0x10000})
+	rinf.AddChild(schi)
+
+	resv := CreateVisualSampleEntryBox("resv", 1920, 1080, hvcC)
+	resv.AddChild(rinf)
+	boxDiffAfterEncodeAndDecode(t, resv)
+
+	decResv := boxAfterEncodeAndDecode(t, resv).(*VisualSampleEntryBox)
+	if decResv.Type() != "resv" {
+		t.Errorf("got type %s instead of resv", decResv.Type())
+	}
+	decRinf := decResv.Rinf
+	if decRinf == nil || decRinf.Schm == nil || decRinf.Schm.SchemeType != "stvi" {
+		t.Fatalf("rinf with stvi schm not decoded")
+	}
+	if decRinf.Schi == nil || len(decRinf.Schi.Children) != 1 || decRinf.Schi.Children[0].Type() != "stvi" {
+		t.Errorf("stvi box not found in schi")
+	}
+
+	removed, err := decResv.RemoveRestriction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != decRinf {
+		t.Errorf("removed rinf is not the decoded rinf")
+	}
+	if decResv.Type() != "hvc1" || decResv.Rinf != nil {
+		t.Errorf("got type %s after removing restriction", decResv.Type())
+	}
+	for _, c := range decResv.Children {
+		if c.Type() == "rinf" {
+			t.Errorf("rinf still present after removing restriction")
+		}
+	}
+	if decResv.Size() != resv.Size()-rinf.Size() {
+		t.Errorf("got size %d instead of %d", decResv.Size(), resv.Size()-rinf.Size())
+	}
+	_, err = decResv.RemoveRestriction()
+	if err == nil {
+		t.Errorf("expected error when removing restriction from hvc1")
+	}
+}
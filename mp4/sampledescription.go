@@ -0,0 +1,33 @@
+package mp4
+
+import "fmt"
+
+// ResolveSampleEntry - sample entry in the stsd box of the init segment (or moov) used by the samples of
+// trackID in frag. The one-based sample_description_index is taken from tfhd if present there,
+// and otherwise from the default in trex.
+func (f *File) ResolveSampleEntry(trackID uint32, frag *Fragment) (Box, error) {
+	trak := f.getTrak(trackID)
+	if trak == nil {
+		return nil, fmt.Errorf("no track with trackID %d", trackID)
+	}
+	trex := f.getTrex(trackID)
+	if trex == nil {
+		return nil, fmt.Errorf("no trex for trackID %d", trackID)
+	}
+	if frag.Moof == nil {
+		return nil, fmt.Errorf("no moof in fragment")
+	}
+	traf := frag.trafForTrex(trex)
+	if traf == nil {
+		return nil, fmt.Errorf("no traf for trackID %d in fragment %d", trackID, frag.Moof.Mfhd.SequenceNumber)
+	}
+	index := trex.DefaultSampleDescriptionIndex
+	if traf.Tfhd.HasSampleDescriptionIndex() {
+		index = traf.Tfhd.SampleDescriptionIndex
+	}
+	entries := trak.Mdia.Minf.Stbl.Stsd.Children
+	if index == 0 || int(index) > len(entries) {
+		return nil, fmt.Errorf("sample description index %d outside range 1-%d", index, len(entries))
+	}
+	return entries[index-1], nil
+}
@@ -0,0 +1,68 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestResolveSampleEntry(t *testing.T) {
+	sps, _ := hex.DecodeString(sps1nalu)
+	pps, _ := hex.DecodeString(pps1nalu)
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	trak := init.Moov.Trak
+	if err := trak.SetAVCDescriptor("avc1", [][]byte{sps}, [][]byte{pps}, true); err != nil {
+		t.Fatal(err)
+	}
+	stsd := trak.Mdia.Minf.Stbl.Stsd
+	stsd.AddChild(CreateVisualSampleEntryBox("avc3", 1280, 720, nil))
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for nr := uint32(1); nr <= 2; nr++ {
+		frag, err := CreateFragment(nr, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nr == 2 { // Override the default index 1 from trex
+			tfhd := frag.Moof.Traf.Tfhd
+			tfhd.Flags |= sampleDescriptionIndexPresent
+			tfhd.SampleDescriptionIndex = 2
+		}
+		frag.AddFullSample(FullSample{
+			Sample:     NewSample(SyncSampleFlags, 3000, 1, 0),
+			DecodeTime: uint64(nr-1) * 3000,
+			Data:       []byte{0},
+		})
+		if err = frag.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frags []*Fragment
+	for _, seg := range f.Segments {
+		frags = append(frags, seg.Fragments...)
+	}
+	if len(frags) != 2 {
+		t.Fatalf("got %d fragments instead of 2", len(frags))
+	}
+	wantedTypes := []string{"avc1", "avc3"}
+	for i, frag := range frags {
+		entry, err := f.ResolveSampleEntry(1, frag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Type() != wantedTypes[i] {
+			t.Errorf("fragment %d: got sample entry %s instead of %s", i+1, entry.Type(), wantedTypes[i])
+		}
+	}
+	frags[1].Moof.Traf.Tfhd.SampleDescriptionIndex = 3
+	if _, err = f.ResolveSampleEntry(1, frags[1]); err == nil {
+		t.Errorf("expected error for sample description index outside stsd")
+	}
+}
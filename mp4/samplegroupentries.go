@@ -31,6 +31,7 @@ func init() {
 		"roll": DecodeRollSampleGroupEntry,
 		"rap ": DecodeRapSampleGroupEntry,
 		"alst": DecodeAlstSampleGroupEntry,
+		"nalm": DecodeNalmSampleGroupEntry,
 	}
 }
 
@@ -331,3 +332,137 @@ func (s *AlstSampleGroupEntry) Info(w io.Writer, specificBoxLevels, indent, inde
 	}
 	return bd.err
 }
+
+// NalmSampleGroupEntry - NAL unit map entry "nalm"
+//
+// ISO/IEC 14496-15 Ed. 5 2019 Section 9.5.2 - NALUMapEntry
+//
+// Maps the NAL units of a sample to groupIDs, which are described by sample group entries of the
+// type given by grouping_type_parameter in the corresponding sbgp box.
+// If RLE is set, each groupID applies from its one-based NALUStartNumber up to the next start number.
+type NalmSampleGroupEntry struct {
+	LargeSize        bool
+	RLE              bool
+	NALUStartNumbers []uint16 // Only used if RLE
+	GroupIDs         []uint16
+}
+
+// DecodeNalmSampleGroupEntry - decode NAL unit map Sample Group Entry
+func DecodeNalmSampleGroupEntry(name string, length uint32, sr bits.SliceReader) (SampleGroupEntry, error) {
+	entry := &NalmSampleGroupEntry{}
+	byt := sr.ReadUint8()
+	entry.LargeSize = byt&0x02 != 0
+	entry.RLE = byt&0x01 != 0
+	var entryCount int
+	if entry.LargeSize {
+		entryCount = int(sr.ReadUint16())
+	} else {
+		entryCount = int(sr.ReadUint8())
+	}
+	for i := 0; i < entryCount; i++ {
+		if entry.RLE {
+			if entry.LargeSize {
+				entry.NALUStartNumbers = append(entry.NALUStartNumbers, sr.ReadUint16())
+			} else {
+				entry.NALUStartNumbers = append(entry.NALUStartNumbers, uint16(sr.ReadUint8()))
+			}
+		}
+		entry.GroupIDs = append(entry.GroupIDs, sr.ReadUint16())
+	}
+	if sr.AccError() != nil {
+		return nil, fmt.Errorf("nalm: %w", sr.AccError())
+	}
+	if length != uint32(entry.Size()) {
+		return nil, fmt.Errorf("nalm: given length %d different from calculated size %d", length, entry.Size())
+	}
+	return entry, nil
+}
+
+// Type - GroupingType SampleGroupEntry (uint32 according to spec)
+func (s *NalmSampleGroupEntry) Type() string {
+	return "nalm"
+}
+
+// Size of sample group entry
+func (s *NalmSampleGroupEntry) Size() uint64 {
+	// flags: 1
+	// entryCount: 1 or 2
+	// NALUStartNumber: 1 or 2 per entry if RLE
+	// groupID: 2 per entry
+	fieldSize := 1
+	if s.LargeSize {
+		fieldSize = 2
+	}
+	entrySize := 2
+	if s.RLE {
+		entrySize += fieldSize
+	}
+	return uint64(1 + fieldSize + entrySize*len(s.GroupIDs))
+}
+
+// Encode SampleGroupEntry to SliceWriter
+func (s *NalmSampleGroupEntry) Encode(sw bits.SliceWriter) {
+	var byt uint8
+	if s.LargeSize {
+		byt |= 0x02
+	}
+	if s.RLE {
+		byt |= 0x01
+	}
+	sw.WriteUint8(byt)
+	if s.LargeSize {
+		sw.WriteUint16(uint16(len(s.GroupIDs)))
+	} else {
+		sw.WriteUint8(uint8(len(s.GroupIDs)))
+	}
+	for i, groupID := range s.GroupIDs {
+		if s.RLE {
+			if s.LargeSize {
+				sw.WriteUint16(s.NALUStartNumbers[i])
+			} else {
+				sw.WriteUint8(uint8(s.NALUStartNumbers[i]))
+			}
+		}
+		sw.WriteUint16(groupID)
+	}
+}
+
+// GroupID - groupID for zero-based naluIndex in sample. ok is false if the NAL unit is not mapped.
+func (s *NalmSampleGroupEntry) GroupID(naluIndex int) (groupID uint16, ok bool) {
+	if naluIndex < 0 {
+		return 0, false
+	}
+	if !s.RLE {
+		if naluIndex >= len(s.GroupIDs) {
+			return 0, false
+		}
+		return s.GroupIDs[naluIndex], true
+	}
+	naluNr := naluIndex + 1
+	for i, startNr := range s.NALUStartNumbers {
+		if int(startNr) > naluNr {
+			break
+		}
+		groupID, ok = s.GroupIDs[i], true
+	}
+	return groupID, ok
+}
+
+// Info - write box info to w
+func (s *NalmSampleGroupEntry) Info(w io.Writer, specificBoxLevels, indent, indentStep string) (err error) {
+	bd := newInfoDumper(w, indent, s, -2, 0)
+	bd.write(" * largeSize: %t", s.LargeSize)
+	bd.write(" * rle: %t", s.RLE)
+	bd.write(" * entryCount: %d", len(s.GroupIDs))
+	level := getInfoLevel(s, specificBoxLevels)
+	if level > 0 {
+		for i, groupID := range s.GroupIDs {
+			if s.RLE {
+				bd.write(" * [%d] naluStartNumber: %d groupID: %d", i+1, s.NALUStartNumbers[i], groupID)
+			} else {
+				bd.write(" * [%d] groupID: %d", i+1, groupID)
+			}
+		}
+	}
+	return bd.err
+}
@@ -0,0 +1,112 @@
+package mp4
+
+// sampleGroupDescriptionIndex - group description index for sampleNr given by the first sbgp or csgp
+// box with groupingType among boxes. ok is false if there is no such box.
+func sampleGroupDescriptionIndex(boxes []Box, groupingType string, sampleNr uint32) (index uint32, ok bool) {
+	for _, c := range boxes {
+		switch box := c.(type) {
+		case *SbgpBox:
+			if box.GroupingType == groupingType {
+				return box.GroupDescriptionIndex(sampleNr), true
+			}
+		case *CsgpBox:
+			if box.GroupingType == groupingType {
+				return box.GroupDescriptionIndex(sampleNr), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// findSgpd - first sgpd box with groupingType among boxes
+func findSgpd(boxes []Box, groupingType string) *SgpdBox {
+	for _, c := range boxes {
+		if sgpd, ok := c.(*SgpdBox); ok && sgpd.GroupingType == groupingType {
+			return sgpd
+		}
+	}
+	return nil
+}
+
+// sgpdEntry - one-based entry in sgpd
+func sgpdEntry(sgpd *SgpdBox, index uint32) (SampleGroupEntry, bool) {
+	if sgpd == nil || index == 0 || int(index) > len(sgpd.SampleGroupEntries) {
+		return nil, false
+	}
+	return sgpd.SampleGroupEntries[index-1], true
+}
+
+// SampleGroupEntry - sample group description entry of groupingType for one-based sampleNr.
+// The sample to group mapping may be given by sbgp or csgp. Samples without a mapping
+// get the default entry of a version 2 sgpd, if present.
+func (s *StblBox) SampleGroupEntry(groupingType string, sampleNr uint32) (SampleGroupEntry, bool) {
+	sgpd := findSgpd(s.Children, groupingType)
+	if sgpd == nil {
+		return nil, false
+	}
+	index, _ := sampleGroupDescriptionIndex(s.Children, groupingType, sampleNr)
+	if index == 0 {
+		index = sgpd.DefaultGroupDescriptionIndex
+	}
+	return sgpdEntry(sgpd, index)
+}
+
+// RollDistance - roll distance for one-based sampleNr from roll sample group
+func (s *StblBox) RollDistance(sampleNr uint32) (int16, bool) {
+	entry, ok := s.SampleGroupEntry("roll", sampleNr)
+	if !ok {
+		return 0, false
+	}
+	roll, ok := entry.(*RollSampleGroupEntry)
+	if !ok {
+		return 0, false
+	}
+	return roll.RollDistance, true
+}
+
+// NalLayer - groupID of zero-based naluIndex in one-based sampleNr given by the nalm sample group.
+// The groupID refers to the sample group given by grouping_type_parameter of the nalm sbgp box,
+// e.g. a temporal layer entry.
+func (s *StblBox) NalLayer(sampleNr uint32, naluIndex int) (uint16, bool) {
+	entry, ok := s.SampleGroupEntry("nalm", sampleNr)
+	if !ok {
+		return 0, false
+	}
+	nalm, ok := entry.(*NalmSampleGroupEntry)
+	if !ok {
+		return 0, false
+	}
+	return nalm.GroupID(naluIndex)
+}
+
+// SampleGroupEntry - sample group description entry of groupingType for one-based sampleNr in the fragment.
+// The sample to group mapping may be given by sbgp or csgp. Indices above 65536 refer to sgpd in the traf,
+// and other indices to sgpd in stbl, which may be nil.
+func (t *TrafBox) SampleGroupEntry(groupingType string, sampleNr uint32, stbl *StblBox) (SampleGroupEntry, bool) {
+	var stblSgpd *SgpdBox
+	if stbl != nil {
+		stblSgpd = findSgpd(stbl.Children, groupingType)
+	}
+	index, _ := sampleGroupDescriptionIndex(t.Children, groupingType, sampleNr)
+	switch {
+	case index > sbgpInsideOffset:
+		return sgpdEntry(findSgpd(t.Children, groupingType), index-sbgpInsideOffset)
+	case index == 0 && stblSgpd != nil:
+		return sgpdEntry(stblSgpd, stblSgpd.DefaultGroupDescriptionIndex)
+	default:
+		return sgpdEntry(stblSgpd, index)
+	}
+}
+
+// KeyIDForSample - key ID for one-based sampleNr in fragment given by seig sample group, if any
+func (t *TrafBox) KeyIDForSample(sampleNr uint32, stbl *StblBox) (UUID, bool) {
+	entry, ok := t.SampleGroupEntry("seig", sampleNr, stbl)
+	if !ok {
+		return nil, false
+	}
+	seig, ok := entry.(*SeigSampleGroupEntry)
+	if !ok {
+		return nil, false
+	}
+	return seig.KID, true
+}
@@ -52,6 +52,21 @@ func DecodeSbgpSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	return &b, sr.AccError()
 }
 
+// GroupDescriptionIndex - group description index for one-based sampleNr, 0 if not in any group
+func (b *SbgpBox) GroupDescriptionIndex(sampleNr uint32) uint32 {
+	if sampleNr == 0 {
+		return 0
+	}
+	sampleIdx := sampleNr - 1
+	for i, count := range b.SampleCounts {
+		if sampleIdx < count {
+			return b.GroupDescriptionIndices[i]
+		}
+		sampleIdx -= count
+	}
+	return 0
+}
+
 // Type - return box type
 func (b *SbgpBox) Type() string {
 	return "sbgp"
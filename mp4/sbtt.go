@@ -0,0 +1,150 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// SbttBox - TextSubtitleSampleEntry Box (sbtt)
+// Defined in ISO/IEC 14496-12 Section 12.6.3
+//
+// Contained in : Sample Description Box (stsd)
+type SbttBox struct {
+	ContentEncoding    string   // Optional
+	MimeFormat         string   // Mandatory
+	Btrt               *BtrtBox // Optional
+	Children           []Box
+	DataReferenceIndex uint16
+}
+
+// NewSbttBox - Create new sbtt box
+// contentEncoding is optional and mimeFormat gives the format of the samples, e.g. text/plain
+func NewSbttBox(contentEncoding, mimeFormat string) *SbttBox {
+	return &SbttBox{
+		ContentEncoding:    contentEncoding,
+		MimeFormat:         mimeFormat,
+		DataReferenceIndex: 1,
+	}
+}
+
+// AddChild - add a child box (txtC or btrt)
+func (b *SbttBox) AddChild(child Box) {
+	switch box := child.(type) {
+	case *BtrtBox:
+		b.Btrt = box
+	default:
+		// Other box
+	}
+
+	b.Children = append(b.Children, child)
+}
+
+// DecodeSbtt - Decode TextSubtitleSampleEntry (sbtt)
+func DecodeSbtt(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeSbttSR(hdr, startPos, sr)
+}
+
+// DecodeSbttSR - Decode TextSubtitleSampleEntry (sbtt)
+func DecodeSbttSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	payloadLen := hdr.payloadLen()
+	initPos := sr.GetPos()
+	remainingBytes := func() int {
+		return payloadLen - (sr.GetPos() - initPos)
+	}
+
+	b := SbttBox{}
+	// 14496-12 8.5.2.2 Sample entry (8 bytes)
+	sr.SkipBytes(6) // Skip 6 reserved bytes
+	b.DataReferenceIndex = sr.ReadUint16()
+	b.ContentEncoding = sr.ReadZeroTerminatedString(remainingBytes())
+	b.MimeFormat = sr.ReadZeroTerminatedString(remainingBytes())
+	if err := sr.AccError(); err != nil {
+		return nil, fmt.Errorf("DecodeSbtt: %w", err)
+	}
+	pos := startPos + uint64(hdr.Hdrlen+sr.GetPos()-initPos)
+	for remainingBytes() > 0 {
+		box, err := DecodeBoxSR(pos, sr)
+		if err != nil {
+			return nil, err
+		}
+		if box == nil {
+			return nil, fmt.Errorf("no sbtt child")
+		}
+		b.AddChild(box)
+		pos += box.Size()
+	}
+	return &b, sr.AccError()
+}
+
+// Type - return box type
+func (b *SbttBox) Type() string {
+	return "sbtt"
+}
+
+// Size - return calculated size
+func (b *SbttBox) Size() uint64 {
+	nrSampleEntryBytes := 8
+	totalSize := uint64(boxHeaderSize + nrSampleEntryBytes + len(b.ContentEncoding) + 1 + len(b.MimeFormat) + 1)
+	for _, child := range b.Children {
+		totalSize += child.Size()
+	}
+	return totalSize
+}
+
+// Encode - write box to w
+func (b *SbttBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *SbttBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteZeroBytes(6)
+	sw.WriteUint16(b.DataReferenceIndex)
+	sw.WriteString(b.ContentEncoding, true)
+	sw.WriteString(b.MimeFormat, true)
+
+	// Next output child boxes in order
+	for _, child := range b.Children {
+		err = child.EncodeSW(sw)
+		if err != nil {
+			return err
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write specific box info to w
+func (b *SbttBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - dataReferenceIndex: %d", b.DataReferenceIndex)
+	bd.write(" - contentEncoding: %s", b.ContentEncoding)
+	bd.write(" - mimeFormat: %s", b.MimeFormat)
+	if bd.err != nil {
+		return bd.err
+	}
+	var err error
+	for _, child := range b.Children {
+		err = child.Info(w, specificBoxLevels, indent+indentStep, indent)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
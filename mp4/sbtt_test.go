@@ -0,0 +1,24 @@
+package mp4
+
+import (
+	"testing"
+)
+
+func TestSbtt(t *testing.T) {
+	sbtt := NewSbttBox("", "text/plain")
+	boxDiffAfterEncodeAndDecode(t, sbtt)
+
+	sbttWithBtrt := NewSbttBox("utf-8", "text/plain")
+	btrt := &BtrtBox{}
+	sbttWithBtrt.AddChild(btrt)
+	if sbttWithBtrt.Btrt != btrt {
+		t.Error("Btrt link is broken")
+	}
+	boxDiffAfterEncodeAndDecode(t, sbttWithBtrt)
+
+	stsd := NewStsdBox()
+	stsd.AddChild(sbtt)
+	if stsd.Sbtt != sbtt {
+		t.Error("Sbtt link in stsd is broken")
+	}
+}
@@ -0,0 +1,61 @@
+package mp4
+
+import "fmt"
+
+// TimelineEntry - DASH SegmentTimeline S element with start time T, duration D, and repeat count R
+type TimelineEntry struct {
+	T uint64
+	D uint64
+	R int
+}
+
+// SegmentTimeline - calculate SegmentTimeline entries for trackID from media segments.
+// The start time of each segment is given by the tfdt of its first fragment, and the duration
+// by the sum of the sample durations. Consecutive segments with equal duration and
+// no gap in time are collapsed into one entry with repeat count.
+// The track is given by the trackID of trex, whose default sample duration is used if not given by trun or tfhd.
+// The segments are not changed.
+func SegmentTimeline(segs []*MediaSegment, trex *TrexBox) ([]TimelineEntry, error) {
+	trackID := trex.TrackID
+	var entries []TimelineEntry
+	for i, seg := range segs {
+		var startTime, dur uint64
+		foundTraf := false
+		for _, frag := range seg.Fragments {
+			for _, traf := range frag.Moof.Trafs {
+				if traf.Tfhd.TrackID != trackID {
+					continue
+				}
+				if !foundTraf {
+					if traf.Tfdt == nil {
+						return nil, fmt.Errorf("segment %d: no tfdt for track %d", i+1, trackID)
+					}
+					startTime = traf.Tfdt.BaseMediaDecodeTime
+					foundTraf = true
+				}
+				defaultSampleDuration := trex.DefaultSampleDuration
+				if traf.Tfhd.HasDefaultSampleDuration() {
+					defaultSampleDuration = traf.Tfhd.DefaultSampleDuration
+				}
+				for _, trun := range traf.Truns {
+					dur += trun.Duration(defaultSampleDuration)
+				}
+			}
+		}
+		if !foundTraf {
+			return nil, fmt.Errorf("segment %d: no data for track %d", i+1, trackID)
+		}
+		if dur == 0 {
+			return nil, fmt.Errorf("segment %d: zero duration for track %d", i+1, trackID)
+		}
+		if len(entries) > 0 {
+			last := &entries[len(entries)-1]
+			if last.D == dur && last.T+uint64(last.R+1)*last.D == startTime {
+				last.R++
+				continue
+			}
+		}
+		entries = append(entries, TimelineEntry{T: startTime, D: dur})
+	}
+	return entries, nil
+}
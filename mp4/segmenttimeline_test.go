@@ -0,0 +1,87 @@
+package mp4
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestSegmentTimeline(t *testing.T) {
+	trackID := uint32(2)
+	// Segment durations with a time gap before the last segment
+	durations := []uint64{2000, 2000, 2000, 1000, 2000, 2000}
+	gaps := []uint64{0, 0, 0, 0, 0, 500}
+	var segs []*MediaSegment
+	var startTime uint64 = 9000
+	for i, dur := range durations {
+		startTime += gaps[i]
+		seg := NewMediaSegment()
+		frag, err := CreateMultiTrackFragment(uint32(i+1), []uint32{1, trackID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		seg.AddFragment(frag)
+		for j := uint64(0); j < dur/500; j++ {
+			err = frag.AddFullSampleToTrack(FullSample{
+				Sample:     NewSample(SyncSampleFlags, 500, 1, 0),
+				DecodeTime: startTime + j*500,
+				Data:       []byte{0},
+			}, trackID)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		segs = append(segs, seg)
+		startTime += dur
+	}
+	entries, err := SegmentTimeline(segs, &TrexBox{TrackID: trackID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := []TimelineEntry{
+		{T: 9000, D: 2000, R: 2},
+		{T: 15000, D: 1000, R: 0},
+		{T: 16000, D: 2000, R: 0},
+		{T: 18500, D: 2000, R: 0},
+	}
+	if diff := deep.Equal(entries, wanted); diff != nil {
+		t.Error(diff)
+	}
+	_, err = SegmentTimeline(segs, &TrexBox{TrackID: 3})
+	if err == nil {
+		t.Errorf("expected error for missing track")
+	}
+}
+
+func TestSegmentTimelineTrexDefaults(t *testing.T) {
+	const sampleDur = 1024
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		frag.AddFullSample(FullSample{
+			Sample:     NewSample(SyncSampleFlags, sampleDur, 100, 0),
+			DecodeTime: uint64(i * sampleDur),
+			Data:       make([]byte, 100),
+		})
+	}
+	// Durations and flags are only given by trex
+	trun := frag.Moof.Traf.Trun
+	trun.Flags &^= TrunSampleDurationPresentFlag | TrunSampleFlagsPresentFlag
+	trex := &TrexBox{TrackID: 1, DefaultSampleDuration: sampleDur, DefaultSampleFlags: SyncSampleFlags}
+	trun.AddSampleDefaultValues(frag.Moof.Traf.Tfhd, trex)
+	origSamples := append([]Sample{}, trun.Samples...)
+	seg := NewMediaSegment()
+	seg.AddFragment(frag)
+	entries, err := SegmentTimeline([]*MediaSegment{seg}, trex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(entries, []TimelineEntry{{T: 0, D: 4 * sampleDur}}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(trun.Samples, origSamples); diff != nil {
+		t.Errorf("samples changed: %v", diff)
+	}
+}
@@ -0,0 +1,62 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// ntpEpochOffset - seconds from NTP epoch (1900-01-01) to Unix epoch (1970-01-01)
+const ntpEpochOffset = 2208988800
+
+// SegmentWriter - write media segments to an output with optional prft boxes
+type SegmentWriter struct {
+	w           io.Writer
+	EncOptimize EncOptimize
+	// EmitPrft - prepend a prft box to the first fragment of each segment
+	EmitPrft bool
+	// Clock - wall clock for prft NTP timestamps. time.Now is used if nil
+	Clock func() time.Time
+}
+
+// NewSegmentWriter - create a SegmentWriter writing to w
+func NewSegmentWriter(w io.Writer) *SegmentWriter {
+	return &SegmentWriter{
+		w:           w,
+		EncOptimize: OptimizeNone,
+	}
+}
+
+// WriteSegment - write a media segment.
+// If EmitPrft is set, a prft box with the current wall clock time and the media time
+// of the first sample of the segment is inserted before the first moof.
+func (sw *SegmentWriter) WriteSegment(seg *MediaSegment) error {
+	if sw.EmitPrft {
+		if len(seg.Fragments) == 0 {
+			return fmt.Errorf("no fragment in segment")
+		}
+		clock := sw.Clock
+		if clock == nil {
+			clock = time.Now
+		}
+		frag := seg.Fragments[0]
+		if frag.Moof == nil || frag.Moof.Traf == nil || frag.Moof.Traf.Tfdt == nil {
+			return fmt.Errorf("no tfdt in first fragment")
+		}
+		mediaTime := frag.Moof.Traf.Tfdt.BaseMediaDecodeTime
+		var version byte = 0
+		if mediaTime >= 1<<32 {
+			version = 1
+		}
+		frag.setPrft(CreatePrftBox(version, NTPTimestamp(clock()), mediaTime))
+	}
+	seg.EncOptimize = sw.EncOptimize
+	return seg.Encode(sw.w)
+}
+
+// NTPTimestamp - convert time to 64-bit NTP timestamp with 32-bit seconds and 32-bit fraction
+func NTPTimestamp(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpEpochOffset)
+	frac := (uint64(t.Nanosecond()) << 32) / 1000000000
+	return secs<<32 | frac
+}
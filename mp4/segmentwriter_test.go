@@ -0,0 +1,64 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSegmentWriterPrft(t *testing.T) {
+	const baseTime = 90000
+	wallClock := time.Date(2021, 3, 1, 12, 0, 0, 500000000, time.UTC)
+	seg := NewMediaSegment()
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg.AddFragment(frag)
+	for i := 0; i < 3; i++ {
+		frag.AddFullSample(FullSample{
+			Sample:     Sample{Flags: SyncSampleFlags, Dur: 3000, Size: 1},
+			DecodeTime: uint64(baseTime + i*3000),
+			Data:       []byte{byte(i)},
+		})
+	}
+
+	var buf bytes.Buffer
+	sw := NewSegmentWriter(&buf)
+	sw.EmitPrft = true
+	sw.Clock = func() time.Time { return wallClock }
+	err = sw.WriteSegment(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, c := range f.Children {
+		types = append(types, c.Type())
+	}
+	if len(types) != 4 || types[1] != "prft" || types[2] != "moof" {
+		t.Fatalf("got box order %v, expected prft before moof", types)
+	}
+	prft := f.Children[1].(*PrftBox)
+	if prft.MediaTime != baseTime {
+		t.Errorf("got media time %d instead of %d", prft.MediaTime, baseTime)
+	}
+	wantedNTP := uint64(wallClock.Unix()+ntpEpochOffset)<<32 | 1<<31
+	if prft.NTPTimestamp != wantedNTP {
+		t.Errorf("got NTP timestamp %x instead of %x", prft.NTPTimestamp, wantedNTP)
+	}
+
+	// Writing again should replace the prft and not add another
+	buf.Reset()
+	err = sw.WriteSegment(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seg.Fragments[0].Children) != 3 {
+		t.Errorf("got %d fragment children instead of 3", len(seg.Fragments[0].Children))
+	}
+}
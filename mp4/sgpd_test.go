@@ -11,12 +11,16 @@ func TestSgpd(t *testing.T) {
 	alstEntry := &AlstSampleGroupEntry{RollCount: 2, FirstOutputSample: 1, SampleOffset: []uint32{7000, 1234}}
 	unknownEntry := &UnknownSampleGroupEntry{Name: "tele", Data: []byte{0x80}}
 	unknownEntry2 := &UnknownSampleGroupEntry{Name: "tele", Data: []byte{0x00}}
+	nalmEntry := &NalmSampleGroupEntry{RLE: true, NALUStartNumbers: []uint16{1, 3}, GroupIDs: []uint16{1, 2}}
+	nalmEntry2 := &NalmSampleGroupEntry{LargeSize: true, GroupIDs: []uint16{1, 1, 2}}
 
 	sgpds := []*SgpdBox{
 		{Version: 1, GroupingType: "roll", DefaultLength: 2, SampleGroupEntries: []SampleGroupEntry{rollEntry}},
 		{Version: 1, GroupingType: "rap ", DefaultLength: 1, SampleGroupEntries: []SampleGroupEntry{rapEntry}},
 		{Version: 1, GroupingType: "alst", DefaultLength: 12, SampleGroupEntries: []SampleGroupEntry{alstEntry}},
 		{Version: 1, GroupingType: "tele", DefaultLength: 1, SampleGroupEntries: []SampleGroupEntry{unknownEntry, unknownEntry2}},
+		{Version: 1, GroupingType: "nalm", DescriptionLengths: []uint32{8, 9},
+			SampleGroupEntries: []SampleGroupEntry{nalmEntry, nalmEntry2}},
 	}
 
 	for _, sgpd := range sgpds {
@@ -24,3 +28,44 @@ func TestSgpd(t *testing.T) {
 	}
 
 }
+
+func TestNalLayer(t *testing.T) {
+	// Two temporal layers with groupIDs 1 and 2 described by tele entries
+	stbl := NewStblBox()
+	stbl.AddChild(&SgpdBox{Version: 1, GroupingType: "tele", DefaultLength: 1,
+		SampleGroupEntries: []SampleGroupEntry{
+			&UnknownSampleGroupEntry{Name: "tele", Data: []byte{0x00}},
+			&UnknownSampleGroupEntry{Name: "tele", Data: []byte{0x00}},
+		}})
+	stbl.AddChild(&SgpdBox{Version: 1, GroupingType: "nalm", DescriptionLengths: []uint32{8, 4},
+		SampleGroupEntries: []SampleGroupEntry{
+			&NalmSampleGroupEntry{RLE: true, NALUStartNumbers: []uint16{1, 3}, GroupIDs: []uint16{1, 2}},
+			&NalmSampleGroupEntry{GroupIDs: []uint16{2}},
+		}})
+	tele := uint32('t')<<24 | uint32('e')<<16 | uint32('l')<<8 | uint32('e')
+	stbl.AddChild(&SbgpBox{Version: 1, GroupingType: "nalm", GroupingTypeParameter: tele,
+		SampleCounts: []uint32{2, 1}, GroupDescriptionIndices: []uint32{1, 2}})
+	stbl = boxAfterEncodeAndDecode(t, stbl).(*StblBox)
+
+	testCases := []struct {
+		sampleNr  uint32
+		naluIndex int
+		groupID   uint16
+		ok        bool
+	}{
+		{1, 0, 1, true},
+		{1, 1, 1, true},
+		{2, 2, 2, true},
+		{2, 5, 2, true},
+		{3, 0, 2, true},
+		{3, 1, 0, false},
+		{4, 0, 0, false},
+	}
+	for _, tc := range testCases {
+		groupID, ok := stbl.NalLayer(tc.sampleNr, tc.naluIndex)
+		if groupID != tc.groupID || ok != tc.ok {
+			t.Errorf("sample %d nalu %d: got (%d, %t) instead of (%d, %t)",
+				tc.sampleNr, tc.naluIndex, groupID, ok, tc.groupID, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package mp4
+
+import "fmt"
+
+// SidxMismatch - sidx reference whose referenced_size differs from the size of the corresponding segment
+type SidxMismatch struct {
+	ReferenceNr    int    // One-based number of reference in sidx
+	ReferencedSize uint32 // referenced_size in sidx, 0 if there is no such reference
+	ActualSize     uint64 // Encoded size of segment, 0 if there is no such segment
+	Description    string
+}
+
+// VerifySidx - compare the referenced_size of each sidx reference with the encoded size of the
+// corresponding segment in segs. If a segment contains sidx itself, only the boxes after sidx are counted.
+// Differing sizes and references without segments, or vice versa, are reported.
+func VerifySidx(sidx *SidxBox, segs []*MediaSegment) []SidxMismatch {
+	var mismatches []SidxMismatch
+	nrRefs := len(sidx.SidxRefs)
+	for i := 0; i < nrRefs || i < len(segs); i++ {
+		m := SidxMismatch{ReferenceNr: i + 1}
+		switch {
+		case i >= len(segs):
+			m.ReferencedSize = sidx.SidxRefs[i].ReferencedSize
+			m.Description = "no segment for reference"
+		case i >= nrRefs:
+			m.ActualSize = referencedSegmentSize(sidx, segs[i])
+			m.Description = "no reference for segment"
+		default:
+			m.ReferencedSize = sidx.SidxRefs[i].ReferencedSize
+			m.ActualSize = referencedSegmentSize(sidx, segs[i])
+			if uint64(m.ReferencedSize) == m.ActualSize {
+				continue
+			}
+			m.Description = fmt.Sprintf("referenced_size %d differs from segment size %d", m.ReferencedSize, m.ActualSize)
+		}
+		mismatches = append(mismatches, m)
+	}
+	return mismatches
+}
+
+// referencedSegmentSize - size of seg, excluding sidx and the boxes before it if seg contains sidx
+func referencedSegmentSize(sidx *SidxBox, seg *MediaSegment) uint64 {
+	size := seg.Size()
+	if seg.Sidx == sidx {
+		size -= sidx.Size()
+		if seg.Styp != nil {
+			size -= seg.Styp.Size()
+		}
+	}
+	return size
+}
@@ -0,0 +1,44 @@
+package mp4
+
+import "testing"
+
+func TestVerifySidx(t *testing.T) {
+	f, err := ReadMP4File("testdata/prog_8s_dec_dashinit.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One segment per fragment
+	var segs []*MediaSegment
+	for _, frag := range f.Segments[0].Fragments {
+		seg := NewMediaSegmentWithoutStyp()
+		seg.AddFragment(frag)
+		segs = append(segs, seg)
+	}
+	if len(segs) < 2 {
+		t.Fatalf("need at least 2 fragments, got %d", len(segs))
+	}
+	sidx := &SidxBox{ReferenceID: 1, Timescale: 90000}
+	for _, seg := range segs {
+		sidx.SidxRefs = append(sidx.SidxRefs, SidxRef{ReferencedSize: uint32(seg.Size()), StartsWithSAP: 1, SAPType: 1})
+	}
+	if mismatches := VerifySidx(sidx, segs); len(mismatches) != 0 {
+		t.Errorf("got %d mismatches for correct sidx: %v", len(mismatches), mismatches)
+	}
+
+	sidx.SidxRefs[1].ReferencedSize += 4
+	mismatches := VerifySidx(sidx, segs)
+	if len(mismatches) != 1 {
+		t.Fatalf("got %d mismatches instead of 1", len(mismatches))
+	}
+	m := mismatches[0]
+	if m.ReferenceNr != 2 || uint64(m.ReferencedSize) != m.ActualSize+4 {
+		t.Errorf("unexpected mismatch %+v", m)
+	}
+
+	sidx.SidxRefs = sidx.SidxRefs[:len(sidx.SidxRefs)-1]
+	mismatches = VerifySidx(sidx, segs)
+	last := mismatches[len(mismatches)-1]
+	if last.ReferenceNr != len(segs) || last.ReferencedSize != 0 || last.ActualSize != segs[len(segs)-1].Size() {
+		t.Errorf("missing reference not reported: %+v", mismatches)
+	}
+}
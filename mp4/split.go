@@ -0,0 +1,134 @@
+package mp4
+
+import (
+	"fmt"
+)
+
+// DashRep - DASH representation of one track with init segment and media segments
+type DashRep struct {
+	Init     *InitSegment
+	Segments []*MediaSegment
+}
+
+// SplitAndFragment - split a progressive file into one fragmented representation per track.
+// The map key is the trackID in f, while every representation has trackID 1.
+// Segment boundaries are set by the first video track, which starts a new segment at the first sync sample
+// at least segmentDurMs after the previous segment start. Without video, the boundaries are
+// multiples of segmentDurMs. The other tracks start a new segment at the first sample at or after a boundary,
+// so the segments are aligned across representations. The mdat must be read into memory.
+func SplitAndFragment(f *File, segmentDurMs uint32) (map[uint32]*DashRep, error) {
+	if f.isFragmented || f.Moov == nil {
+		return nil, fmt.Errorf("only available for progressive files")
+	}
+	if f.Mdat == nil || f.Mdat.IsLazy() {
+		return nil, fmt.Errorf("mdat must be read into memory")
+	}
+	if segmentDurMs == 0 {
+		return nil, fmt.Errorf("segment duration must be positive")
+	}
+	samples := make(map[uint32][]FullSample)
+	var videoTrak *TrakBox
+	for _, trak := range f.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		trackSamples, err := f.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %w", trackID, err)
+		}
+		samples[trackID] = trackSamples
+		if videoTrak == nil && trak.Mdia.Hdlr.HandlerType == "vide" {
+			videoTrak = trak
+		}
+	}
+
+	// Segment boundaries as times in seconds given by boundaries[i] / boundaryTimescale
+	boundaryTimescale := uint64(1000)
+	var boundaries []uint64
+	if videoTrak != nil {
+		boundaryTimescale = uint64(videoTrak.Mdia.Mdhd.Timescale)
+		segDur := uint64(segmentDurMs) * boundaryTimescale / 1000
+		for i, s := range samples[videoTrak.Tkhd.TrackID] {
+			if i == 0 || (s.IsSync() && s.DecodeTime >= boundaries[len(boundaries)-1]+segDur) {
+				boundaries = append(boundaries, s.DecodeTime)
+			}
+		}
+	}
+
+	reps := make(map[uint32]*DashRep)
+	for _, trak := range f.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		init, err := createSplitInit(f.Moov, trak)
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %w", trackID, err)
+		}
+		rep := &DashRep{Init: init}
+		timescale := uint64(trak.Mdia.Mdhd.Timescale)
+		var frag *Fragment
+		segNr := -1
+		for _, s := range samples[trackID] {
+			newSegNr := segNr
+			if videoTrak != nil {
+				for newSegNr+1 < len(boundaries) &&
+					s.DecodeTime*boundaryTimescale >= boundaries[newSegNr+1]*timescale {
+					newSegNr++
+				}
+			} else {
+				newSegNr = int(s.DecodeTime * 1000 / (uint64(segmentDurMs) * timescale))
+			}
+			if frag == nil || newSegNr != segNr {
+				seg := NewMediaSegment()
+				frag, err = CreateFragment(uint32(len(rep.Segments)+1), init.Moov.Trak.Tkhd.TrackID)
+				if err != nil {
+					return nil, err
+				}
+				seg.AddFragment(frag)
+				rep.Segments = append(rep.Segments, seg)
+				segNr = newSegNr
+			}
+			frag.AddFullSample(s)
+		}
+		reps[trackID] = rep
+	}
+	return reps, nil
+}
+
+// createSplitInit - create init segment for a single-track representation of trak
+func createSplitInit(moov *MoovBox, trak *TrakBox) (*InitSegment, error) {
+	var mediaType string
+	switch hdlrType := trak.Mdia.Hdlr.HandlerType; hdlrType {
+	case "vide":
+		mediaType = "video"
+	case "soun":
+		mediaType = "audio"
+	case "subt":
+		mediaType = "subtitle"
+	case "text":
+		mediaType = "text"
+	default:
+		return nil, fmt.Errorf("handler type %q not supported", hdlrType)
+	}
+	lang := trak.Mdia.Mdhd.GetLanguage()
+	if trak.Mdia.Elng != nil {
+		lang = trak.Mdia.Elng.Language
+	}
+	init := CreateEmptyInit()
+	init.Moov.Mvhd.Timescale = moov.Mvhd.Timescale
+	init.AddEmptyTrack(trak.Mdia.Mdhd.Timescale, mediaType, lang)
+	outTrak := init.Moov.Trak
+	outTrak.Tkhd.Width, outTrak.Tkhd.Height = trak.Tkhd.Width, trak.Tkhd.Height
+	// Copy the boxes, so that the init segments do not share boxes with moov
+	for _, sampleEntry := range trak.Mdia.Minf.Stbl.Stsd.Children {
+		sampleEntryCopy, err := copyBox(sampleEntry)
+		if err != nil {
+			return nil, err
+		}
+		outTrak.Mdia.Minf.Stbl.Stsd.AddChild(sampleEntryCopy)
+	}
+	if trak.Edts != nil {
+		edts, err := copyBox(trak.Edts)
+		if err != nil {
+			return nil, err
+		}
+		outTrak.setEdts(edts.(*EdtsBox))
+	}
+	return init, nil
+}
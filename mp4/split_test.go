@@ -0,0 +1,84 @@
+package mp4
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestSplitAndFragment(t *testing.T) {
+	fd, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	f, err := DecodeFile(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reps, err := SplitAndFragment(f, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reps) != 2 {
+		t.Fatalf("got %d reps instead of 2", len(reps))
+	}
+	nrSegments := -1
+	for _, trak := range f.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		rep := reps[trackID]
+		if rep == nil {
+			t.Fatalf("no rep for track %d", trackID)
+		}
+		if nrSegments < 0 {
+			nrSegments = len(rep.Segments)
+		} else if len(rep.Segments) != nrSegments {
+			t.Errorf("track %d: got %d segments instead of %d", trackID, len(rep.Segments), nrSegments)
+		}
+		buf := bytes.Buffer{}
+		err = rep.Init.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, seg := range rep.Segments {
+			err = seg.Encode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		repFile, err := DecodeFile(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The init segment must not share boxes with the source moov
+		repStsd := rep.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd
+		for i, sampleEntry := range trak.Mdia.Minf.Stbl.Stsd.Children {
+			if repStsd.Children[i] == sampleEntry {
+				t.Errorf("track %d: sample entry %d shared with source moov", trackID, i+1)
+			}
+		}
+		if trak.Edts != nil && rep.Init.Moov.Trak.Edts == trak.Edts {
+			t.Errorf("track %d: edts shared with source moov", trackID)
+		}
+		isVideo := trak.Mdia.Hdlr.HandlerType == "vide"
+		var nrSamples uint32
+		for _, seg := range repFile.Segments {
+			for _, frag := range seg.Fragments {
+				samples, err := frag.GetFullSamples(repFile.Init.Moov.Mvex.Trex)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if isVideo && !samples[0].IsSync() {
+					t.Errorf("video fragment %d does not start with sync sample", frag.Moof.Mfhd.SequenceNumber)
+				}
+				nrSamples += uint32(len(samples))
+			}
+		}
+		if nrSamples != trak.GetNrSamples() {
+			t.Errorf("track %d: got %d samples instead of %d", trackID, nrSamples, trak.GetNrSamples())
+		}
+	}
+	if nrSegments < 2 {
+		t.Errorf("got %d segments, expected more than 1", nrSegments)
+	}
+}
@@ -0,0 +1,85 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// SratBox - Sampling Rate Box (srat), ISO/IEC 14496-12 Section 12.2.3
+//
+// Contained in : Audio Sample Entry
+//
+// Carries the full 32-bit sampling rate for rates that do not fit in the 16-bit integer part
+// of the SampleRate field of the audio sample entry, such as 96kHz and 192kHz.
+type SratBox struct {
+	Version      byte
+	Flags        uint32
+	SamplingRate uint32
+}
+
+// CreateSrat - create srat box with samplingRate
+func CreateSrat(samplingRate uint32) *SratBox {
+	return &SratBox{SamplingRate: samplingRate}
+}
+
+// DecodeSrat - box-specific decode
+func DecodeSrat(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeSratSR(hdr, startPos, sr)
+}
+
+// DecodeSratSR - box-specific decode
+func DecodeSratSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := SratBox{
+		Version:      byte(versionAndFlags >> 24),
+		Flags:        versionAndFlags & flagsMask,
+		SamplingRate: sr.ReadUint32(),
+	}
+	return &b, sr.AccError()
+}
+
+// Type - box type
+func (b *SratBox) Type() string {
+	return "srat"
+}
+
+// Size - calculated size of box
+func (b *SratBox) Size() uint64 {
+	return boxHeaderSize + 8
+}
+
+// Encode - write box to w
+func (b *SratBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *SratBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint32(b.SamplingRate)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *SratBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - samplingRate: %d", b.SamplingRate)
+	return bd.err
+}
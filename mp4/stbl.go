@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/edgeware/mp4ff/bits"
@@ -24,6 +25,8 @@ type StblBox struct {
 	Sdtp  *SdtpBox
 	Sbgp  *SbgpBox   // The first
 	Sbgps []*SbgpBox // All
+	Csgp  *CsgpBox   // The first
+	Csgps []*CsgpBox // All
 	Sgpd  *SgpdBox   // The first
 	Sgpds []*SgpdBox // All
 	Subs  *SubsBox
@@ -65,6 +68,11 @@ func (s *StblBox) AddChild(child Box) {
 			s.Sbgp = box
 		}
 		s.Sbgps = append(s.Sbgps, box)
+	case *CsgpBox:
+		if s.Csgp == nil {
+			s.Csgp = box
+		}
+		s.Csgps = append(s.Csgps, box)
 	case *SgpdBox:
 		if s.Sgpd == nil {
 			s.Sgpd = box
@@ -135,3 +143,72 @@ func (b *StblBox) EncodeSW(sw bits.SliceWriter) error {
 func (s *StblBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	return ContainerInfo(s, w, specificBoxLevels, indent, indentStep)
 }
+
+// TotalSamples - number of samples in the track as given by stsz, or by stts if there is no stsz box
+func (s *StblBox) TotalSamples() uint32 {
+	if s.Stsz != nil {
+		return s.Stsz.GetNrSamples()
+	}
+	return s.sttsSampleCount()
+}
+
+// CheckSampleCounts - check that the number of samples in stsz equals the total sample count in stts and
+// the number of samples in all chunks given by stsc and the number of chunk offsets in stco or co64.
+// The returned error lists all counts if there is a mismatch.
+func (s *StblBox) CheckSampleCounts() error {
+	if s.Stsz == nil || s.Stts == nil || s.Stsc == nil {
+		return fmt.Errorf("missing stsz, stts, or stsc box")
+	}
+	var nrChunks uint32
+	switch {
+	case s.Stco != nil:
+		nrChunks = uint32(len(s.Stco.ChunkOffset))
+	case s.Co64 != nil:
+		nrChunks = uint32(len(s.Co64.ChunkOffset))
+	default:
+		return fmt.Errorf("missing stco or co64 box")
+	}
+	stszCount := s.Stsz.GetNrSamples()
+	sttsCount := s.sttsSampleCount()
+	chunkCount, err := s.chunkSampleCount(nrChunks)
+	if err != nil {
+		return err
+	}
+	if stszCount != sttsCount || uint64(stszCount) != chunkCount {
+		return fmt.Errorf("sample count mismatch: stsz %d, stts %d, stsc/stco %d (%d chunks)",
+			stszCount, sttsCount, chunkCount, nrChunks)
+	}
+	return nil
+}
+
+// sttsSampleCount - total number of samples in stts
+func (s *StblBox) sttsSampleCount() uint32 {
+	if s.Stts == nil {
+		return 0
+	}
+	var count uint32
+	for _, c := range s.Stts.SampleCount {
+		count += c
+	}
+	return count
+}
+
+// chunkSampleCount - total number of samples in nrChunks chunks as given by stsc
+func (s *StblBox) chunkSampleCount(nrChunks uint32) (uint64, error) {
+	stsc := s.Stsc
+	var count uint64
+	for i, firstChunk := range stsc.FirstChunk {
+		if firstChunk == 0 || firstChunk > nrChunks {
+			return 0, fmt.Errorf("stsc entry %d: first chunk %d outside range 1-%d", i+1, firstChunk, nrChunks)
+		}
+		endChunk := nrChunks + 1
+		if i+1 < len(stsc.FirstChunk) {
+			endChunk = stsc.FirstChunk[i+1]
+			if endChunk <= firstChunk {
+				return 0, fmt.Errorf("stsc entry %d: first chunk %d not increasing", i+2, endChunk)
+			}
+		}
+		count += uint64(endChunk-firstChunk) * uint64(stsc.SamplesPerChunk[i])
+	}
+	return count, nil
+}
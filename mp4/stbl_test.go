@@ -0,0 +1,38 @@
+package mp4
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckSampleCounts(t *testing.T) {
+	stbl := NewStblBox()
+	stbl.AddChild(&SttsBox{SampleCount: []uint32{3, 2}, SampleTimeDelta: []uint32{1000, 2000}})
+	stbl.AddChild(&StscBox{FirstChunk: []uint32{1, 3}, SamplesPerChunk: []uint32{2, 1}})
+	stbl.AddChild(&StszBox{SampleNumber: 5, SampleUniformSize: 100})
+	stbl.AddChild(&StcoBox{ChunkOffset: []uint32{1000, 1200, 1400}})
+	if err := stbl.CheckSampleCounts(); err != nil {
+		t.Errorf("unexpected error for matching counts: %v", err)
+	}
+	if stbl.TotalSamples() != 5 {
+		t.Errorf("got %d total samples instead of 5", stbl.TotalSamples())
+	}
+
+	stbl.Stsz.SampleNumber = 6
+	err := stbl.CheckSampleCounts()
+	if err == nil || !strings.Contains(err.Error(), "stsz 6, stts 5, stsc/stco 5") {
+		t.Errorf("got error %v for stsz mismatch", err)
+	}
+	stbl.Stsz.SampleNumber = 5
+
+	stbl.Stco.ChunkOffset = append(stbl.Stco.ChunkOffset, 1600)
+	err = stbl.CheckSampleCounts()
+	if err == nil || !strings.Contains(err.Error(), "stsc/stco 6 (4 chunks)") {
+		t.Errorf("got error %v for extra chunk", err)
+	}
+
+	stbl.Stco.ChunkOffset = stbl.Stco.ChunkOffset[:1]
+	if err = stbl.CheckSampleCounts(); err == nil {
+		t.Errorf("expected error for stsc first chunk beyond chunk offsets")
+	}
+}
@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -17,3 +18,35 @@ func TestStpp(t *testing.T) {
 	stppWithoutOptionalFields := NewStppBox("The namespace", "", "")
 	boxDiffAfterEncodeAndDecode(t, stppWithoutOptionalFields)
 }
+
+func TestStppInitSegment(t *testing.T) {
+	imsc1Namespace := "http://www.w3.org/ns/ttml"
+	imsc1Schema := "http://www.w3.org/ns/ttml/profile/imsc1/text"
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(1000, "subtitle", "en")
+	trak := init.Moov.Trak
+	err := trak.SetStppDescriptor(imsc1Namespace, imsc1Schema, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	err = init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encLen := buf.Len()
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stsd := f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd
+	if stsd.Stpp == nil {
+		t.Fatal("no stpp in decoded stsd")
+	}
+	if stsd.Stpp.Namespace != imsc1Namespace || stsd.Stpp.SchemaLocation != imsc1Schema {
+		t.Errorf("got namespace %q and schemaLocation %q", stsd.Stpp.Namespace, stsd.Stpp.SchemaLocation)
+	}
+	if f.Init.Size() != uint64(encLen) {
+		t.Errorf("got size %d after decode instead of %d", f.Init.Size(), encLen)
+	}
+}
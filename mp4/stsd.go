@@ -18,10 +18,14 @@ type StsdBox struct {
 	SampleCount uint32
 	AvcX        *VisualSampleEntryBox
 	HvcX        *VisualSampleEntryBox
+	Mp4v        *VisualSampleEntryBox
 	Mp4a        *AudioSampleEntryBox
+	Mp4s        *Mp4sBox
 	AC3         *AudioSampleEntryBox
 	EC3         *AudioSampleEntryBox
 	Wvtt        *WvttBox
+	Stpp        *StppBox
+	Sbtt        *SbttBox
 	Children    []Box
 }
 
@@ -37,14 +41,22 @@ func (s *StsdBox) AddChild(box Box) {
 		s.AvcX = box.(*VisualSampleEntryBox)
 	case "hvc1", "hev1":
 		s.HvcX = box.(*VisualSampleEntryBox)
+	case "mp4v":
+		s.Mp4v = box.(*VisualSampleEntryBox)
 	case "mp4a":
 		s.Mp4a = box.(*AudioSampleEntryBox)
+	case "mp4s":
+		s.Mp4s = box.(*Mp4sBox)
 	case "ac-3":
 		s.AC3 = box.(*AudioSampleEntryBox)
 	case "ec-3":
 		s.EC3 = box.(*AudioSampleEntryBox)
 	case "wvtt":
 		s.Wvtt = box.(*WvttBox)
+	case "stpp":
+		s.Stpp = box.(*StppBox)
+	case "sbtt":
+		s.Sbtt = box.(*SbttBox)
 	}
 	s.Children = append(s.Children, box)
 	s.SampleCount++
@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/edgeware/mp4ff/bits"
@@ -17,6 +18,34 @@ type StssBox struct {
 	SampleNumber []uint32
 }
 
+// CreateStss - create stss box from sorted one-based sync sample numbers for a track with nrSamples samples.
+// Duplicates are removed. If every sample is a sync sample, nil is returned, since stss should then be omitted.
+func CreateStss(syncSampleNumbers []uint32, nrSamples uint32) (*StssBox, error) {
+	sampleNumbers := make([]uint32, 0, len(syncSampleNumbers))
+	for i, nr := range syncSampleNumbers {
+		if nr == 0 {
+			return nil, fmt.Errorf("sync sample number 0 at index %d, must be one-based", i)
+		}
+		if nr > nrSamples {
+			return nil, fmt.Errorf("sync sample number %d larger than number of samples %d", nr, nrSamples)
+		}
+		if i > 0 {
+			prev := syncSampleNumbers[i-1]
+			if nr < prev {
+				return nil, fmt.Errorf("sync sample numbers not sorted: %d after %d", nr, prev)
+			}
+			if nr == prev {
+				continue
+			}
+		}
+		sampleNumbers = append(sampleNumbers, nr)
+	}
+	if nrSamples > 0 && uint32(len(sampleNumbers)) == nrSamples {
+		return nil, nil // All samples are sync samples
+	}
+	return &StssBox{SampleNumber: sampleNumbers}, nil
+}
+
 // DecodeStss - box-specific decode
 func DecodeStss(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
@@ -72,3 +72,42 @@ func TestStssNoSamples(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateStss(t *testing.T) {
+	// GOP pattern with 3 GOPs of 4 samples, and a duplicate entry
+	stss, err := CreateStss([]uint32{1, 5, 5, 9}, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stss == nil || stss.EntryCount() != 3 {
+		t.Fatalf("expected stss with 3 entries")
+	}
+	for nr := uint32(1); nr <= 12; nr++ {
+		wanted := nr%4 == 1
+		if stss.IsSyncSample(nr) != wanted {
+			t.Errorf("sample %d: got sync %t instead of %t", nr, stss.IsSyncSample(nr), wanted)
+		}
+	}
+	boxDiffAfterEncodeAndDecode(t, stss)
+
+	stss, err = CreateStss([]uint32{1, 2, 3, 4}, 4)
+	if err != nil || stss != nil {
+		t.Errorf("expected nil stss for all sync samples")
+	}
+	// Sync samples only at the start of a longer track
+	for _, nrs := range [][]uint32{{1}, {1, 2, 3}} {
+		stss, err = CreateStss(nrs, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stss == nil || stss.EntryCount() != uint32(len(nrs)) || stss.IsSyncSample(100) {
+			t.Errorf("expected stss with %d entries for %v in 100 samples", len(nrs), nrs)
+		}
+	}
+	for _, nrs := range [][]uint32{{0, 5}, {1, 9, 5}, {1, 13}} {
+		_, err = CreateStss(nrs, 12)
+		if err == nil {
+			t.Errorf("expected error for %v", nrs)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package mp4
+
+import (
+	"fmt"
+	"math"
+)
+
+// AddSubtitleTrack - add a subtitle track with samples to a progressive file and return its track ID.
+// entryType is "wvtt", "stpp", or "sbtt" and config is the vttC WebVTT header, the stpp namespace,
+// or the sbtt mime format, respectively. The samples must be contiguous in decode time, so gaps should be
+// filled with empty samples. A start time after zero is signaled by an empty edit.
+// The track gets a cdsc track reference to the first video track, if any.
+// The sample data is appended to the mdat, which must be read into memory (not lazily decoded),
+// and the chunk offsets of the other tracks are shifted if the mdat moves.
+func (f *File) AddSubtitleTrack(entryType string, config []byte, samples []FullSample,
+	timescale uint32, lang string) (uint32, error) {
+	if f.isFragmented {
+		return 0, fmt.Errorf("only available for progressive files")
+	}
+	if f.Moov == nil || f.Mdat == nil {
+		return 0, fmt.Errorf("no moov or mdat box")
+	}
+	if f.Mdat.IsLazy() {
+		return 0, fmt.Errorf("lazy mdat not supported")
+	}
+	if len(samples) == 0 {
+		return 0, fmt.Errorf("no samples")
+	}
+	if timescale == 0 {
+		return 0, fmt.Errorf("timescale must be positive")
+	}
+	var mediaType string
+	switch entryType {
+	case "wvtt":
+		mediaType = "text"
+	case "stpp", "sbtt":
+		mediaType = "subtitle"
+	default:
+		return 0, fmt.Errorf("sample entry type %q not supported", entryType)
+	}
+
+	moov := f.Moov
+	trackID := moov.Mvhd.NextTrackID
+	for _, trak := range moov.Traks {
+		if trak.Tkhd.TrackID >= trackID {
+			trackID = trak.Tkhd.TrackID + 1
+		}
+	}
+	trak := CreateEmptyTrak(trackID, timescale, mediaType, lang)
+	var err error
+	switch entryType {
+	case "wvtt":
+		err = trak.SetWvttDescriptor(string(config))
+	case "stpp":
+		err = trak.SetStppDescriptor(string(config), "", "")
+	case "sbtt":
+		err = trak.SetSbttDescriptor("", string(config))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	stbl := trak.Mdia.Minf.Stbl
+	stts := stbl.Stts
+	stsz := stbl.Stsz
+	var data []byte
+	decodeTime := samples[0].DecodeTime
+	for i, s := range samples {
+		if s.DecodeTime != decodeTime {
+			return 0, fmt.Errorf("sample %d: decode time %d, expected %d", i+1, s.DecodeTime, decodeTime)
+		}
+		decodeTime += uint64(s.Dur)
+		if len(s.Data) != int(s.Size) {
+			return 0, fmt.Errorf("sample %d: size %d does not match data length %d", i+1, s.Size, len(s.Data))
+		}
+		nrEntries := len(stts.SampleCount)
+		if nrEntries > 0 && stts.SampleTimeDelta[nrEntries-1] == s.Dur {
+			stts.SampleCount[nrEntries-1]++
+		} else {
+			stts.SampleCount = append(stts.SampleCount, 1)
+			stts.SampleTimeDelta = append(stts.SampleTimeDelta, s.Dur)
+		}
+		stsz.SampleSize = append(stsz.SampleSize, s.Size)
+		data = append(data, s.Data...)
+	}
+	stsz.SampleNumber = uint32(len(samples))
+	stsc := stbl.Stsc
+	stsc.FirstChunk = []uint32{1}
+	stsc.SamplesPerChunk = []uint32{uint32(len(samples))}
+	stsc.SetSingleSampleDescriptionID(1)
+	stbl.Stco.ChunkOffset = []uint32{0}
+
+	mediaDur := decodeTime - samples[0].DecodeTime
+	trak.Mdia.Mdhd.Duration = mediaDur
+	movieTimescale := uint64(moov.Mvhd.Timescale)
+	startOffset := samples[0].DecodeTime * movieTimescale / uint64(timescale)
+	if startOffset > 0 {
+		trak.addEmptyEdit(startOffset)
+	}
+	trak.Tkhd.Duration = startOffset + mediaDur*movieTimescale/uint64(timescale)
+	if trak.Tkhd.Duration > moov.Mvhd.Duration {
+		moov.Mvhd.Duration = trak.Tkhd.Duration
+	}
+
+	for _, t := range moov.Traks {
+		if t.Mdia.Hdlr != nil && t.Mdia.Hdlr.HandlerType == "vide" {
+			tref := &TrefBox{}
+			tref.AddChild(&TrefTypeBox{Name: "cdsc", TrackIDs: []uint32{t.Tkhd.TrackID}})
+			trak.AddChild(tref)
+			break
+		}
+	}
+
+	oldPayloadStart := f.Mdat.PayloadAbsoluteOffset()
+	subtitleOffset := f.Mdat.DataLength()
+	moov.AddChild(trak)
+	moov.Mvhd.NextTrackID = trackID + 1
+	f.Mdat.AddSampleData(data)
+	var mdatStart uint64
+	for _, c := range f.Children {
+		if c == f.Mdat {
+			break
+		}
+		mdatStart += c.Size()
+	}
+	f.Mdat.StartPos = mdatStart
+	payloadStart := f.Mdat.PayloadAbsoluteOffset()
+	delta := int64(payloadStart) - int64(oldPayloadStart)
+	if delta != 0 {
+		for _, t := range moov.Traks {
+			if t == trak {
+				continue
+			}
+			if err := shiftChunkOffsets(t.Mdia.Minf.Stbl, delta); err != nil {
+				return 0, fmt.Errorf("track %d: %w", t.Tkhd.TrackID, err)
+			}
+		}
+	}
+	offset := payloadStart + subtitleOffset
+	if offset > math.MaxUint32 {
+		return 0, fmt.Errorf("chunk offset %d too big for stco", offset)
+	}
+	stbl.Stco.ChunkOffset[0] = uint32(offset)
+	return trackID, nil
+}
+
+// shiftChunkOffsets - add delta to all chunk offsets in stco or co64
+func shiftChunkOffsets(stbl *StblBox, delta int64) error {
+	if stbl.Co64 != nil {
+		for i, offset := range stbl.Co64.ChunkOffset {
+			stbl.Co64.ChunkOffset[i] = uint64(int64(offset) + delta)
+		}
+		return nil
+	}
+	if stbl.Stco == nil {
+		return nil
+	}
+	for i, offset := range stbl.Stco.ChunkOffset {
+		newOffset := int64(offset) + delta
+		if newOffset < 0 || newOffset > math.MaxUint32 {
+			return fmt.Errorf("chunk offset %d out of range for stco", newOffset)
+		}
+		stbl.Stco.ChunkOffset[i] = uint32(newOffset)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package mp4
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestAddSubtitleTrack(t *testing.T) {
+	fd, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	f, err := DecodeFile(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	origSamples := make(map[uint32][]FullSample)
+	for _, trak := range f.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		origSamples[trackID], err = f.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cue := &VttcBox{}
+	cue.AddChild(&PaylBox{CueText: "Hello"})
+	var cueBuf bytes.Buffer
+	if err = cue.Encode(&cueBuf); err != nil {
+		t.Fatal(err)
+	}
+	var vtteBuf bytes.Buffer
+	if err = (&VtteBox{}).Encode(&vtteBuf); err != nil {
+		t.Fatal(err)
+	}
+	var subSamples []FullSample
+	for i, data := range [][]byte{cueBuf.Bytes(), vtteBuf.Bytes(), cueBuf.Bytes()} {
+		subSamples = append(subSamples, FullSample{
+			Sample:     Sample{Flags: SyncSampleFlags, Dur: 2000, Size: uint32(len(data))},
+			DecodeTime: 1000 + uint64(i)*2000,
+			Data:       data,
+		})
+	}
+	trackID, err := f.AddSubtitleTrack("wvtt", []byte("WEBVTT"), subSamples, 1000, "swe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trackID != 3 {
+		t.Errorf("got trackID %d instead of 3", trackID)
+	}
+
+	var buf bytes.Buffer
+	if err = f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decFile, err := DecodeFile(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trak := decFile.Moov.GetTrak(trackID)
+	if trak == nil {
+		t.Fatalf("no track %d after decode", trackID)
+	}
+	if trak.Mdia.Minf.Stbl.Stsd.Wvtt == nil {
+		t.Errorf("no wvtt sample entry")
+	}
+	if trak.Mdia.Hdlr.HandlerType != "text" || trak.Mdia.Mdhd.GetLanguage() != "swe" {
+		t.Errorf("got handler %s and language %s", trak.Mdia.Hdlr.HandlerType, trak.Mdia.Mdhd.GetLanguage())
+	}
+	var tref *TrefBox
+	for _, c := range trak.Children {
+		if box, ok := c.(*TrefBox); ok {
+			tref = box
+		}
+	}
+	if tref == nil || len(tref.Children) != 1 {
+		t.Fatalf("no tref with one reference")
+	}
+	cdsc := tref.Children[0].(*TrefTypeBox)
+	if cdsc.Name != "cdsc" || len(cdsc.TrackIDs) != 1 || cdsc.TrackIDs[0] != 2 {
+		t.Errorf("got tref %s to %v instead of cdsc to video track 2", cdsc.Name, cdsc.TrackIDs)
+	}
+	if trak.Edts == nil || trak.Edts.Elst[0].Entries[0].MediaTime != -1 {
+		t.Errorf("no empty edit for subtitle start time")
+	}
+	decSamples, err := decFile.fullSamplesForTrack(trackID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decSamples) != len(subSamples) {
+		t.Fatalf("got %d subtitle samples instead of %d", len(decSamples), len(subSamples))
+	}
+	for i, s := range decSamples {
+		if s.Dur != 2000 || !bytes.Equal(s.Data, subSamples[i].Data) {
+			t.Errorf("subtitle sample %d differs", i+1)
+		}
+	}
+	for origTrackID, samples := range origSamples {
+		decSamples, err := decFile.fullSamplesForTrack(origTrackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(decSamples) != len(samples) {
+			t.Fatalf("track %d: got %d samples instead of %d", origTrackID, len(decSamples), len(samples))
+		}
+		for i := range samples {
+			if !bytes.Equal(decSamples[i].Data, samples[i].Data) {
+				t.Errorf("track %d: sample %d data differs after adding subtitle track", origTrackID, i+1)
+				break
+			}
+		}
+	}
+
+	_, err = f.AddSubtitleTrack("tx3g", nil, subSamples, 1000, "swe")
+	if err == nil {
+		t.Errorf("expected error for unsupported sample entry type")
+	}
+}
@@ -0,0 +1,105 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/avc"
+	"github.com/edgeware/mp4ff/hevc"
+)
+
+// RecomputeSyncFlags - set the sync status of all samples of an AVC or HEVC track from the NAL unit types
+// in the sample data. AVC samples with an IDR NAL unit and HEVC samples with an IRAP NAL unit (types 16-23)
+// are marked as sync samples, and all other samples as non-sync samples depending on other samples.
+// For fragmented files, the flags are written per sample in the truns, overriding tfhd and trex defaults.
+// For progressive files, stss is rebuilt and removed if all samples are sync samples. sdtp is not changed.
+// rs is needed if the mdat is lazily decoded.
+func (f *File) RecomputeSyncFlags(trackID uint32, rs io.ReadSeeker) error {
+	trak := f.getTrak(trackID)
+	if trak == nil {
+		return fmt.Errorf("no track with trackID %d", trackID)
+	}
+	stsd := trak.Mdia.Minf.Stbl.Stsd
+	var isSync func(sample []byte) bool
+	switch {
+	case stsd.AvcX != nil:
+		isSync = avc.IsIDRSample
+	case stsd.HvcX != nil:
+		isSync = hevc.IsRAPSample
+	default:
+		return fmt.Errorf("track %d is not AVC or HEVC", trackID)
+	}
+	samples, err := f.fullSamplesForTrack(trackID, rs)
+	if err != nil {
+		return err
+	}
+	syncs := make([]bool, len(samples))
+	for i, s := range samples {
+		syncs[i] = isSync(s.Data)
+	}
+	if !f.isFragmented {
+		oldMdatPositions := f.mdatPositions()
+		setStssFromSyncs(trak.Mdia.Minf.Stbl, syncs)
+		return f.moveAllChunkOffsets(oldMdatPositions)
+	}
+	trex := f.getTrex(trackID)
+	if trex == nil {
+		return fmt.Errorf("no trex for trackID %d", trackID)
+	}
+	sampleIdx := 0
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			traf := frag.trafForTrex(trex)
+			if traf == nil {
+				continue
+			}
+			oldMoofSize := frag.Moof.Size()
+			for _, trun := range traf.Truns {
+				trun.AddSampleDefaultValues(traf.Tfhd, trex)
+				for i := range trun.Samples {
+					if sampleIdx >= len(syncs) {
+						return fmt.Errorf("more samples in truns than in track %d", trackID)
+					}
+					trun.Samples[i].Flags = syncSampleFlags(trun.Samples[i].Flags, syncs[sampleIdx])
+					sampleIdx++
+				}
+				trun.RemoveFirstSampleFlags()
+				trun.Flags |= TrunSampleFlagsPresentFlag
+			}
+			frag.moveDataOffsets(int32(frag.Moof.Size() - oldMoofSize))
+		}
+	}
+	return nil
+}
+
+// syncSampleFlags - flags with non-sync bit and sample_depends_on set according to isSync
+func syncSampleFlags(flags uint32, isSync bool) uint32 {
+	sf := DecodeSampleFlags(flags)
+	sf.SampleIsNonSync = !isSync
+	if isSync {
+		sf.SampleDependsOn = 2 // Does not depend on other samples
+	} else {
+		sf.SampleDependsOn = 1 // Depends on other samples
+	}
+	return sf.Encode()
+}
+
+// setStssFromSyncs - rebuild stss with the sync samples in syncs, or remove it if all samples are sync samples
+func setStssFromSyncs(stbl *StblBox, syncs []bool) {
+	var syncNrs []uint32
+	for i, sync := range syncs {
+		if sync {
+			syncNrs = append(syncNrs, uint32(i+1))
+		}
+	}
+	if len(syncNrs) == len(syncs) {
+		stbl.Stss = nil
+		removeChildBox(&stbl.Children, "stss")
+		return
+	}
+	if stbl.Stss != nil {
+		stbl.Stss.SampleNumber = syncNrs
+		return
+	}
+	stbl.AddChild(&StssBox{SampleNumber: syncNrs})
+}
@@ -0,0 +1,226 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestRecomputeSyncFlagsFragmented(t *testing.T) {
+	sps, _ := hex.DecodeString(sps1nalu)
+	pps, _ := hex.DecodeString(pps1nalu)
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	if err := init.Moov.Trak.SetAVCDescriptor("avc1", [][]byte{sps}, [][]byte{pps}, true); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	idr := []byte{0, 0, 0, 2, 0x65, 0x88}
+	nonIDR := []byte{0, 0, 0, 2, 0x41, 0x9a}
+	// Every sample is wrongly marked as sync in the first fragment and as non-sync in the second
+	wantedSync := []bool{true, false, false, true, false, false}
+	for fragNr := 0; fragNr < 2; fragNr++ {
+		frag, err := CreateFragment(uint32(fragNr+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		flags := SyncSampleFlags
+		if fragNr == 1 {
+			flags = NonSyncSampleFlags
+		}
+		for i := 0; i < 3; i++ {
+			data := nonIDR
+			if wantedSync[fragNr*3+i] {
+				data = idr
+			}
+			frag.AddFullSample(FullSample{
+				Sample:     NewSample(flags, 3000, uint32(len(data)), 0),
+				DecodeTime: uint64(fragNr*3+i) * 3000,
+				Data:       data,
+			})
+		}
+		if err = frag.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = f.RecomputeSyncFlags(1, nil); err != nil {
+		t.Fatal(err)
+	}
+	var outBuf bytes.Buffer
+	if err = f.Encode(&outBuf); err != nil {
+		t.Fatal(err)
+	}
+	f, err = DecodeFile(&outBuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples, err := f.fullSamplesForTrack(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotSync []bool
+	for _, s := range samples {
+		gotSync = append(gotSync, s.IsSync())
+	}
+	if diff := deep.Equal(gotSync, wantedSync); diff != nil {
+		t.Errorf("sync flags differ: %v", diff)
+	}
+}
+
+func TestRecomputeSyncFlagsProgressive(t *testing.T) {
+	f, err := ReadMP4File("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trak := f.Moov.Traks[1]
+	stbl := trak.Mdia.Minf.Stbl
+	if stbl.Stsd.AvcX == nil || stbl.Stss == nil {
+		t.Fatalf("second track is not AVC with stss")
+	}
+	wantedSyncNrs := append([]uint32(nil), stbl.Stss.SampleNumber...)
+	wantedSamples := make(map[uint32][]FullSample)
+	for _, tr := range f.Moov.Traks {
+		samples, err := f.fullSamplesForTrack(tr.Tkhd.TrackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantedSamples[tr.Tkhd.TrackID] = samples
+	}
+	// Mark all samples as sync by removing stss
+	oldMdatPositions := f.mdatPositions()
+	stbl.Stss = nil
+	removeChildBox(&stbl.Children, "stss")
+	if err = f.moveAllChunkOffsets(oldMdatPositions); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.RecomputeSyncFlags(trak.Tkhd.TrackID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if stbl.Stss == nil {
+		t.Fatalf("no stss after recomputing sync flags")
+	}
+	if diff := deep.Equal(stbl.Stss.SampleNumber, wantedSyncNrs); diff != nil {
+		t.Errorf("sync sample numbers differ: %v", diff)
+	}
+	var buf bytes.Buffer
+	if err = f.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decFile, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trak := range f.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		gotSamples, err := decFile.fullSamplesForTrack(trackID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(gotSamples, wantedSamples[trackID]); diff != nil {
+			t.Errorf("track %d samples differ after encode and decode: %v", trackID, diff)
+		}
+	}
+}
+
+func TestRecomputeSyncFlagsMultiTrackFragment(t *testing.T) {
+	sps, _ := hex.DecodeString(sps1nalu)
+	pps, _ := hex.DecodeString(pps1nalu)
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.AddEmptyTrack(90000, "video", "und")
+	if err := init.Moov.Traks[0].SetAVCDescriptor("avc1", [][]byte{sps}, [][]byte{pps}, true); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	idr := []byte{0, 0, 0, 2, 0x65, 0x88}
+	nonIDR := []byte{0, 0, 0, 2, 0x41, 0x9a}
+	wantedSync := []bool{true, false, false}
+	var wantedData [][]byte
+	frag, err := CreateMultiTrackFragment(1, []uint32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, sync := range wantedSync {
+		data := nonIDR
+		if sync {
+			data = idr
+		}
+		err = frag.AddFullSampleToTrack(FullSample{
+			Sample:     NewSample(SyncSampleFlags, 3000, uint32(len(data)), 0),
+			DecodeTime: uint64(i) * 3000,
+			Data:       data,
+		}, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		data := []byte{byte(i), byte(i + 1), byte(i + 2)}
+		wantedData = append(wantedData, data)
+		err = frag.AddFullSampleToTrack(FullSample{
+			Sample:     NewSample(SyncSampleFlags, 3000, uint32(len(data)), 0),
+			DecodeTime: uint64(i) * 3000,
+			Data:       data,
+		}, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Move the flags of the first track to tfhd, so that recomputing them grows its trun
+	frag.EncOptimize = OptimizeTrun
+	if err = frag.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Segments[0].Fragments[0].Moof.Trafs[0].Trun.HasSampleFlags() {
+		t.Fatalf("first trun has sample flags before recomputing sync flags")
+	}
+	if err = f.RecomputeSyncFlags(1, nil); err != nil {
+		t.Fatal(err)
+	}
+	var outBuf bytes.Buffer
+	if err = f.Encode(&outBuf); err != nil {
+		t.Fatal(err)
+	}
+	f, err = DecodeFile(&outBuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples, err := f.fullSamplesForTrack(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotSync []bool
+	for _, s := range samples {
+		gotSync = append(gotSync, s.IsSync())
+	}
+	if diff := deep.Equal(gotSync, wantedSync); diff != nil {
+		t.Errorf("sync flags differ: %v", diff)
+	}
+	samples, err = f.fullSamplesForTrack(2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotData [][]byte
+	for _, s := range samples {
+		gotData = append(gotData, s.Data)
+	}
+	if diff := deep.Equal(gotData, wantedData); diff != nil {
+		t.Errorf("second track sample data differs: %v", diff)
+	}
+}
@@ -0,0 +1,78 @@
+package mp4
+
+import (
+	"fmt"
+	"math"
+)
+
+// TimelineSegment - part of the movie timeline of a track given by an edit list entry.
+// Movie times are in the movie timescale and media times in the media timescale of the track.
+type TimelineSegment struct {
+	MovieStart    uint64
+	MovieDuration uint64
+	IsGap         bool    // Empty edit without media
+	MediaStart    int64   // Media time at MovieStart, not set for gaps
+	MediaEnd      int64   // Media time at MovieStart + MovieDuration, not set for gaps
+	MediaRate     float64 // Not set for gaps
+}
+
+// Timeline - segments of the track in movie-timeline order as given by the edit list.
+// Empty edits give gaps, and other edits give media segments with the corresponding media time range.
+// An edit with zero duration extends to the end of the media as given by the sample table (or mdhd if no samples).
+// Without edit list, the timeline is one media segment covering all media.
+func (t *TrakBox) Timeline(movieTimescale uint32) ([]TimelineSegment, error) {
+	if movieTimescale == 0 {
+		return nil, fmt.Errorf("movie timescale is 0")
+	}
+	mediaTimescale := t.Mdia.Mdhd.Timescale
+	if mediaTimescale == 0 {
+		return nil, fmt.Errorf("media timescale is 0")
+	}
+	mediaDur := t.Mdia.Mdhd.Duration
+	if stts := t.Mdia.Minf.Stbl.Stts; stts != nil && len(stts.SampleCount) > 0 {
+		mediaDur = 0
+		for i, count := range stts.SampleCount {
+			mediaDur += uint64(count) * uint64(stts.SampleTimeDelta[i])
+		}
+	}
+	toMovie := func(mediaTime int64) uint64 {
+		return uint64(math.Round(float64(mediaTime) * float64(movieTimescale) / float64(mediaTimescale)))
+	}
+	toMedia := func(movieTime uint64) int64 {
+		return int64(math.Round(float64(movieTime) * float64(mediaTimescale) / float64(movieTimescale)))
+	}
+
+	var entries []ElstEntry
+	if t.Edts != nil {
+		for _, elst := range t.Edts.Elst {
+			entries = append(entries, elst.Entries...)
+		}
+	}
+	if len(entries) == 0 {
+		return []TimelineSegment{{MovieDuration: toMovie(int64(mediaDur)), MediaEnd: int64(mediaDur), MediaRate: 1}}, nil
+	}
+	segments := make([]TimelineSegment, 0, len(entries))
+	var movieStart uint64
+	for _, e := range entries {
+		seg := TimelineSegment{MovieStart: movieStart, MovieDuration: e.SegmentDuration}
+		if e.MediaTime < 0 {
+			seg.IsGap = true
+		} else {
+			seg.MediaStart = e.MediaTime
+			seg.MediaRate = e.MediaRate()
+			if seg.MovieDuration == 0 {
+				remaining := int64(mediaDur) - e.MediaTime
+				if remaining < 0 {
+					remaining = 0
+				}
+				seg.MovieDuration = toMovie(remaining)
+				seg.MediaEnd = seg.MediaStart + remaining
+			} else {
+				seg.MediaEnd = seg.MediaStart + int64(math.Round(float64(toMedia(seg.MovieDuration))*seg.MediaRate))
+			}
+		}
+		segments = append(segments, seg)
+		movieStart += seg.MovieDuration
+	}
+	return segments, nil
+}
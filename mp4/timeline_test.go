@@ -0,0 +1,53 @@
+package mp4
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestTimeline(t *testing.T) {
+	trak := CreateEmptyTrak(1, 90000, "video", "und")
+	stbl := trak.Mdia.Minf.Stbl
+	stbl.Stts.SampleCount = []uint32{30}
+	stbl.Stts.SampleTimeDelta = []uint32{3000}
+	movieTimescale := uint32(1000)
+
+	segments, err := trak.Timeline(movieTimescale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := []TimelineSegment{{MovieStart: 0, MovieDuration: 1000, MediaStart: 0, MediaEnd: 90000, MediaRate: 1}}
+	if diff := deep.Equal(segments, wanted); diff != nil {
+		t.Errorf("no edit list: %v", diff)
+	}
+
+	elst := &ElstBox{Entries: []ElstEntry{
+		{SegmentDuration: 500, MediaTime: -1, MediaRateInteger: 1},
+		{SegmentDuration: 900, MediaTime: 6000, MediaRateInteger: 1},
+	}}
+	edts := &EdtsBox{}
+	edts.AddChild(elst)
+	trak.setEdts(edts)
+	segments, err = trak.Timeline(movieTimescale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted = []TimelineSegment{
+		{MovieStart: 0, MovieDuration: 500, IsGap: true},
+		{MovieStart: 500, MovieDuration: 900, MediaStart: 6000, MediaEnd: 87000, MediaRate: 1},
+	}
+	if diff := deep.Equal(segments, wanted); diff != nil {
+		t.Errorf("empty edit and media: %v", diff)
+	}
+
+	// Zero duration extends to end of media
+	elst.Entries[1].SegmentDuration = 0
+	segments, err = trak.Timeline(movieTimescale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if segments[1].MovieDuration != 933 || segments[1].MediaEnd != 90000 {
+		t.Errorf("got media segment %+v, expected it to end at end of media", segments[1])
+	}
+}
@@ -2,6 +2,7 @@ package mp4
 
 import (
 	"io"
+	"math"
 
 	"github.com/edgeware/mp4ff/bits"
 )
@@ -14,6 +15,10 @@ import (
 // Volume (relevant for audio tracks) is a fixed point number (8 bits + 8 bits). Full volume is 1.0.
 // Width and Height (relevant for video tracks) are fixed point numbers (16 bits + 16 bits).
 // Video pixels are not necessarily square.
+//
+// Matrix is the transformation matrix {a, b, u, c, d, v, x, y, w} where a, b, c, d, x, and y are
+// 16.16 fixed point numbers and u, v, and w are 2.30 fixed point numbers. An all-zero Matrix is
+// written as the unity matrix.
 type TkhdBox struct {
 	Version          byte
 	Flags            uint32
@@ -24,15 +29,20 @@ type TkhdBox struct {
 	Layer            int16
 	AlternateGroup   int16 // should be int16
 	Volume           Fixed16
+	Matrix           [9]int32
 	Width, Height    Fixed32
 }
 
+// unityMatrix - unity transformation matrix according to ISO/IEC 14496-12 Section 8.3.2.2
+var unityMatrix = [9]int32{0x00010000, 0, 0, 0, 0x00010000, 0, 0, 0, 0x40000000}
+
 // CreateTkhd - create tkhd box with common settings
 func CreateTkhd() *TkhdBox {
 	return &TkhdBox{
 		Version: 0,
 		Flags:   0x000007,      // Enabled, inMovie, inPreview set
 		TrackID: DefaultTrakID, // Typically just have one track
+		Matrix:  unityMatrix,
 	}
 }
 
@@ -75,7 +85,9 @@ func DecodeTkhdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	t.AlternateGroup = sr.ReadInt16()
 	t.Volume = Fixed16(sr.ReadInt16())
 	sr.SkipBytes(2)
-	sr.SkipBytes(36) // 3x3 matrixdata
+	for i := range t.Matrix {
+		t.Matrix[i] = sr.ReadInt32()
+	}
 	t.Width = Fixed32(sr.ReadUint32())
 	t.Height = Fixed32(sr.ReadUint32())
 
@@ -131,8 +143,14 @@ func (b *TkhdBox) EncodeSW(sw bits.SliceWriter) error {
 	sw.WriteInt16(b.Layer)
 	sw.WriteInt16(b.AlternateGroup)
 	sw.WriteUint16(uint16(b.Volume))
-	sw.WriteZeroBytes(2)  // Reserved
-	sw.WriteUnityMatrix() // unity matrix according to 8.3.2.2
+	sw.WriteZeroBytes(2) // Reserved
+	matrix := b.Matrix
+	if matrix == [9]int32{} {
+		matrix = unityMatrix
+	}
+	for _, m := range matrix {
+		sw.WriteInt32(m)
+	}
 	sw.WriteUint32(uint32(b.Width))
 	sw.WriteUint32(uint32(b.Height))
 
@@ -149,5 +167,21 @@ func (b *TkhdBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	if b.Width != 0 && b.Height != 0 { // These are Fixed32 values
 		bd.write(" - Width: %s, Height: %s", b.Width, b.Height)
 	}
+	if b.Matrix != unityMatrix && b.Matrix != [9]int32{} {
+		bd.write(" - matrix: %v, rotation: %d", b.Matrix, b.RotationDegrees())
+	}
 	return bd.err
 }
+
+// RotationDegrees - clockwise display rotation given by the matrix rounded to 0, 90, 180, or 270 degrees
+func (b *TkhdBox) RotationDegrees() int {
+	if b.Matrix == [9]int32{} {
+		return 0
+	}
+	angle := math.Atan2(float64(b.Matrix[1]), float64(b.Matrix[0])) * 180 / math.Pi
+	degrees := int(math.Round(angle/90)) * 90
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees
+}
@@ -0,0 +1,29 @@
+package mp4
+
+import "math"
+
+// TracksAligned - compare the first presentation times of trackA and trackB on the movie timeline and return
+// whether they differ by at most toleranceMs, together with the offset in milliseconds of trackB relative
+// to trackA. The first presentation times are given by File.FirstPresentationTime, so empty edits and
+// the media time of the first non-empty edit are included, as well as composition time offsets.
+func TracksAligned(f *File, trackA, trackB uint32, toleranceMs float64) (bool, float64, error) {
+	startA, err := f.firstPresentationTimeMs(trackA)
+	if err != nil {
+		return false, 0, err
+	}
+	startB, err := f.firstPresentationTimeMs(trackB)
+	if err != nil {
+		return false, 0, err
+	}
+	offsetMs := startB - startA
+	return math.Abs(offsetMs) <= toleranceMs, offsetMs, nil
+}
+
+// firstPresentationTimeMs - first presentation time of trackID in milliseconds
+func (f *File) firstPresentationTimeMs(trackID uint32) (float64, error) {
+	pts, timescale, err := f.FirstPresentationTime(trackID)
+	if err != nil {
+		return 0, err
+	}
+	return float64(pts) * 1000 / float64(timescale), nil
+}
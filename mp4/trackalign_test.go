@@ -0,0 +1,65 @@
+package mp4
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestTracksAligned(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.AddEmptyTrack(48000, "audio", "und")
+	videoTrak, audioTrak := init.Moov.Traks[0], init.Moov.Traks[1]
+	// Video edit list removes the composition time offset of the first sample
+	videoEdts := &EdtsBox{}
+	videoEdts.AddChild(&ElstBox{Entries: []ElstEntry{{MediaTime: 3000, MediaRateInteger: 1}}})
+	videoTrak.setEdts(videoEdts)
+	// Audio is delayed 100ms (9000 in movie timescale 90000) by an empty edit
+	audioTrak.addEmptyEdit(9000)
+
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	videoID, audioID := videoTrak.Tkhd.TrackID, audioTrak.Tkhd.TrackID
+	frag, err := CreateMultiTrackFragment(1, []uint32{videoID, audioID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		s := FullSample{Sample: NewSample(SyncSampleFlags, 3000, 1, 3000), DecodeTime: uint64(i) * 3000, Data: []byte{0}}
+		if err = frag.AddFullSampleToTrack(s, videoID); err != nil {
+			t.Fatal(err)
+		}
+		s = FullSample{Sample: NewSample(SyncSampleFlags, 1024, 1, 0), DecodeTime: uint64(i) * 1024, Data: []byte{1}}
+		if err = frag.AddFullSampleToTrack(s, audioID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = frag.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aligned, offsetMs, err := TracksAligned(f, videoID, audioID, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aligned || math.Abs(offsetMs-100) > 1e-9 {
+		t.Errorf("got aligned=%t offset %.3fms instead of false and 100ms", aligned, offsetMs)
+	}
+	aligned, offsetMs, err = TracksAligned(f, audioID, videoID, 150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !aligned || math.Abs(offsetMs+100) > 1e-9 {
+		t.Errorf("got aligned=%t offset %.3fms instead of true and -100ms", aligned, offsetMs)
+	}
+	if _, _, err = TracksAligned(f, videoID, 7, 50); err == nil {
+		t.Errorf("expected error for unknown track")
+	}
+}
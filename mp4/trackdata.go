@@ -0,0 +1,42 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+)
+
+// ExtractTrackData - sample data of each track in a progressive file, concatenated in decode order
+// and keyed by trackID. The samples are located via stsc, stco/co64, and stsz, so interleaved
+// tracks in a shared mdat are separated. rs is needed if the mdat is lazily decoded.
+func (f *File) ExtractTrackData(rs io.ReadSeeker) (map[uint32][]byte, error) {
+	if f.isFragmented || f.Moov == nil || f.Mdat == nil {
+		return nil, fmt.Errorf("only available for progressive files")
+	}
+	trackData := make(map[uint32][]byte, len(f.Moov.Traks))
+	for _, trak := range f.Moov.Traks {
+		trackID := trak.Tkhd.TrackID
+		nrSamples := trak.GetNrSamples()
+		if nrSamples == 0 {
+			trackData[trackID] = nil
+			continue
+		}
+		ranges, err := trak.GetRangesForSampleInterval(1, nrSamples)
+		if err != nil {
+			return nil, fmt.Errorf("track %d: %w", trackID, err)
+		}
+		var totalSize uint64
+		for _, r := range ranges {
+			totalSize += r.Size
+		}
+		data := make([]byte, 0, totalSize)
+		for _, r := range ranges {
+			chunkData, err := f.Mdat.ReadData(int64(r.Offset), int64(r.Size), rs)
+			if err != nil {
+				return nil, fmt.Errorf("track %d: %w", trackID, err)
+			}
+			data = append(data, chunkData...)
+		}
+		trackData[trackID] = data
+	}
+	return trackData, nil
+}
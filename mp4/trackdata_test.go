@@ -0,0 +1,59 @@
+package mp4
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestExtractTrackData(t *testing.T) {
+	fd, err := os.Open("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	for _, mode := range []DecFileMode{DecModeNormal, DecModeLazyMdat} {
+		if _, err = fd.Seek(0, 0); err != nil {
+			t.Fatal(err)
+		}
+		f, err := DecodeFile(fd, WithDecodeMode(mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(f.Moov.Traks) != 2 {
+			t.Fatalf("expected two tracks, got %d", len(f.Moov.Traks))
+		}
+		trackData, err := f.ExtractTrackData(fd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, trak := range f.Moov.Traks {
+			stsz := trak.Mdia.Minf.Stbl.Stsz
+			wantedSize, err := stsz.GetTotalSampleSize(1, stsz.GetNrSamples())
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := trackData[trak.Tkhd.TrackID]
+			if uint64(len(data)) != wantedSize {
+				t.Errorf("track %d: got %d bytes instead of %d", trak.Tkhd.TrackID, len(data), wantedSize)
+			}
+			// Last sample must be at the end of the track data
+			lastNr := stsz.GetNrSamples()
+			samples, err := trak.GetSampleData(lastNr, lastNr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ranges, err := trak.GetRangesForSampleInterval(lastNr, lastNr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lastData, err := f.Mdat.ReadData(int64(ranges[0].Offset), int64(samples[0].Size), fd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasSuffix(data, lastData) {
+				t.Errorf("track %d: data does not end with last sample", trak.Tkhd.TrackID)
+			}
+		}
+	}
+}
@@ -18,8 +18,10 @@ type TrafBox struct {
 	Saiz     *SaizBox
 	Saio     *SaioBox
 	Sbgp     *SbgpBox
+	Csgp     *CsgpBox
 	Sgpd     *SgpdBox
 	Senc     *SencBox
+	Trik     *TrikBox
 	Trun     *TrunBox // The first TrunBox
 	Truns    []*TrunBox
 	Children []Box
@@ -122,10 +124,14 @@ func (t *TrafBox) AddChild(child Box) error {
 		t.Saio = box
 	case *SbgpBox:
 		t.Sbgp = box
+	case *CsgpBox:
+		t.Csgp = box
 	case *SgpdBox:
 		t.Sgpd = box
 	case *SencBox:
 		t.Senc = box
+	case *TrikBox:
+		t.Trik = box
 	case *TrunBox:
 		if t.Trun == nil {
 			t.Trun = box
@@ -137,6 +143,128 @@ func (t *TrafBox) AddChild(child Box) error {
 	return nil
 }
 
+// MergeTruns - merge all trun boxes into the first one.
+// Sample values which are only present in some truns are filled in from tfhd defaults, or from trex
+// if not in tfhd. trex may be nil if tfhd has all defaults needed.
+// The sample data of the truns must be contiguous in the mdat.
+func (t *TrafBox) MergeTruns(trex *TrexBox) error {
+	if len(t.Truns) <= 1 {
+		return nil
+	}
+	tfhd := t.Tfhd
+	first := t.Truns[0]
+	var mergedFlags uint32
+	var version byte
+	for i, trun := range t.Truns {
+		mergedFlags |= trun.Flags & (TrunSampleDurationPresentFlag | TrunSampleSizePresentFlag |
+			TrunSampleFlagsPresentFlag | TrunSampleCompositionTimeOffsetPresentFlag)
+		if i > 0 && trun.HasFirstSampleFlags() {
+			mergedFlags |= TrunSampleFlagsPresentFlag
+		}
+		if trun.Version > version {
+			version = trun.Version
+		}
+	}
+	defaultDur, hasDefaultDur := tfhd.DefaultSampleDuration, tfhd.HasDefaultSampleDuration()
+	defaultSize, hasDefaultSize := tfhd.DefaultSampleSize, tfhd.HasDefaultSampleSize()
+	defaultFlags, hasDefaultFlags := tfhd.DefaultSampleFlags, tfhd.HasDefaultSampleFlags()
+	if trex != nil {
+		if !hasDefaultDur {
+			defaultDur, hasDefaultDur = trex.DefaultSampleDuration, true
+		}
+		if !hasDefaultSize {
+			defaultSize, hasDefaultSize = trex.DefaultSampleSize, true
+		}
+		if !hasDefaultFlags {
+			defaultFlags, hasDefaultFlags = trex.DefaultSampleFlags, true
+		}
+	}
+	if mergedFlags&TrunSampleDurationPresentFlag != 0 && !hasDefaultDur {
+		for _, trun := range t.Truns {
+			if !trun.HasSampleDuration() {
+				return fmt.Errorf("cannot merge truns: no default sample duration in tfhd or trex")
+			}
+		}
+	}
+	if !hasDefaultSize {
+		for _, trun := range t.Truns {
+			if !trun.HasSampleSize() {
+				return fmt.Errorf("cannot merge truns: no default sample size in tfhd or trex")
+			}
+		}
+	}
+	if mergedFlags&TrunSampleFlagsPresentFlag != 0 && !hasDefaultFlags {
+		for _, trun := range t.Truns {
+			if !trun.HasSampleFlags() {
+				return fmt.Errorf("cannot merge truns: no default sample flags in tfhd or trex")
+			}
+		}
+	}
+
+	var samples []Sample
+	var nextOffset int64
+	for i, trun := range t.Truns {
+		if i > 0 && trun.HasDataOffset() && int64(trun.DataOffset) != nextOffset {
+			return fmt.Errorf("cannot merge truns: data of trun %d at offset %d instead of %d",
+				i+1, trun.DataOffset, nextOffset)
+		}
+		if i == 0 || trun.HasDataOffset() {
+			nextOffset = int64(trun.DataOffset)
+		}
+		for j, s := range trun.Samples {
+			if !trun.HasSampleDuration() {
+				s.Dur = defaultDur
+			}
+			if !trun.HasSampleSize() {
+				s.Size = defaultSize
+			}
+			if !trun.HasSampleFlags() && !(j == 0 && trun.HasFirstSampleFlags()) {
+				s.Flags = defaultFlags
+			}
+			nextOffset += int64(s.Size)
+			samples = append(samples, s)
+		}
+	}
+
+	firstSampleFlags, hasFirstSampleFlags := first.FirstSampleFlags()
+	first.RemoveFirstSampleFlags()
+	first.Version = version
+	first.Flags = first.Flags&TrunDataOffsetPresentFlag | mergedFlags
+	first.Samples = nil
+	first.sampleCount = 0
+	first.AddSamples(samples)
+	if hasFirstSampleFlags && mergedFlags&TrunSampleFlagsPresentFlag == 0 {
+		first.SetFirstSampleFlags(firstSampleFlags)
+	}
+
+	children := make([]Box, 0, len(t.Children))
+	for _, c := range t.Children {
+		if c.Type() == "trun" && c != first {
+			continue
+		}
+		children = append(children, c)
+	}
+	t.Children = children
+	t.Truns = []*TrunBox{first}
+	t.Trun = first
+	return nil
+}
+
+// insertTfdt - insert tfdt directly after tfhd
+func (t *TrafBox) insertTfdt(tfdt *TfdtBox) {
+	t.Tfdt = tfdt
+	pos := 0
+	for i, c := range t.Children {
+		if c.Type() == "tfhd" {
+			pos = i + 1
+			break
+		}
+	}
+	t.Children = append(t.Children, nil)
+	copy(t.Children[pos+1:], t.Children[pos:])
+	t.Children[pos] = tfdt
+}
+
 // Type - return box type
 func (t *TrafBox) Type() string {
 	return "traf"
@@ -273,3 +401,28 @@ func (t *TrafBox) RemoveEncryptionBoxes() uint64 {
 	t.Children = remainingChildren
 	return nrBytesRemoved
 }
+
+// presentationTimeRange - earliest presentation time and presentation end time of the samples in traf.
+// Sample values missing in trun are set from tfhd and trex. ok is false if there is no tfdt or no sample.
+func (t *TrafBox) presentationTimeRange(trex *TrexBox) (start, end int64, ok bool) {
+	if t.Tfdt == nil {
+		return 0, 0, false
+	}
+	decTime := int64(t.Tfdt.BaseMediaDecodeTime)
+	for _, trun := range t.Truns {
+		trun.AddSampleDefaultValues(t.Tfhd, trex)
+		for _, s := range trun.Samples {
+			presTime := decTime + int64(s.CompositionTimeOffset)
+			sampleEnd := presTime + int64(s.Dur)
+			if !ok || presTime < start {
+				start = presTime
+			}
+			if !ok || sampleEnd > end {
+				end = sampleEnd
+			}
+			ok = true
+			decTime += int64(s.Dur)
+		}
+	}
+	return start, end, ok
+}
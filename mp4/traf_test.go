@@ -105,3 +105,77 @@ func runEncodeDecode(t *testing.T, test testSamples, withOptimization bool) {
 			test.name, withOptimization, outSamples, test.samples)
 	}
 }
+
+func createMergeTestTraf(secondOffset int32) *TrafBox {
+	traf := &TrafBox{}
+	tfhd := &TfhdBox{TrackID: 1}
+	tfhd.Flags = defaultSampleDurationPresent | defaultSampleFlagsPresent
+	tfhd.DefaultSampleDuration = 1000
+	tfhd.DefaultSampleFlags = NonSyncSampleFlags
+	_ = traf.AddChild(tfhd)
+	trun1 := &TrunBox{Flags: TrunDataOffsetPresentFlag | TrunSampleSizePresentFlag | TrunSampleDurationPresentFlag, DataOffset: 100}
+	trun1.AddSamples([]Sample{{NonSyncSampleFlags, 1001, 10, 0}, {NonSyncSampleFlags, 1002, 20, 0}})
+	trun1.SetFirstSampleFlags(SyncSampleFlags)
+	trun1.Samples[0].Flags = SyncSampleFlags
+	trun2 := &TrunBox{Flags: TrunDataOffsetPresentFlag | TrunSampleSizePresentFlag, DataOffset: secondOffset}
+	trun2.AddSamples([]Sample{{NonSyncSampleFlags, 0, 30, 0}})
+	trun3 := &TrunBox{Flags: TrunSampleSizePresentFlag | TrunFirstSampleFlagsPresentFlag}
+	trun3.AddSamples([]Sample{{SyncSampleFlags, 0, 40, 0}, {NonSyncSampleFlags, 0, 50, 0}})
+	trun3.SetFirstSampleFlags(SyncSampleFlags)
+	for _, trun := range []*TrunBox{trun1, trun2, trun3} {
+		_ = traf.AddChild(trun)
+	}
+	return traf
+}
+
+func TestMergeTruns(t *testing.T) {
+	traf := createMergeTestTraf(130)
+	err := traf.MergeTruns(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traf.Truns) != 1 || len(traf.Children) != 2 {
+		t.Fatalf("got %d truns and %d children after merge", len(traf.Truns), len(traf.Children))
+	}
+	trun := traf.Trun
+	if trun.DataOffset != 100 {
+		t.Errorf("got data offset %d instead of 100", trun.DataOffset)
+	}
+	expected := []Sample{
+		{SyncSampleFlags, 1001, 10, 0},
+		{NonSyncSampleFlags, 1002, 20, 0},
+		{NonSyncSampleFlags, 1000, 30, 0},
+		{SyncSampleFlags, 1000, 40, 0},
+		{NonSyncSampleFlags, 1000, 50, 0},
+	}
+	if trun.SampleCount() != uint32(len(expected)) {
+		t.Errorf("got %d samples instead of %d", trun.SampleCount(), len(expected))
+	}
+	if !reflect.DeepEqual(trun.Samples, expected) {
+		t.Errorf("got samples %v instead of %v", trun.Samples, expected)
+	}
+	if !trun.HasSampleFlags() || !trun.HasSampleDuration() {
+		t.Errorf("merged trun should have sample flags and durations")
+	}
+	boxDiffAfterEncodeAndDecode(t, traf)
+
+	traf = createMergeTestTraf(140)
+	err = traf.MergeTruns(nil)
+	if err == nil {
+		t.Errorf("expected error for non-contiguous truns")
+	}
+
+	// Defaults in trex instead of tfhd
+	traf = createMergeTestTraf(130)
+	traf.Tfhd.Flags = 0
+	if err = traf.MergeTruns(nil); err == nil {
+		t.Errorf("expected error for missing defaults")
+	}
+	trex := &TrexBox{TrackID: 1, DefaultSampleDuration: 1000, DefaultSampleFlags: NonSyncSampleFlags}
+	if err = traf.MergeTruns(trex); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(traf.Trun.Samples, expected) {
+		t.Errorf("got samples %v instead of %v with trex defaults", traf.Trun.Samples, expected)
+	}
+}
@@ -3,6 +3,7 @@ package mp4
 import (
 	"fmt"
 	"io"
+	"math"
 
 	"github.com/edgeware/mp4ff/bits"
 )
@@ -121,7 +122,7 @@ func (t *TrakBox) GetSampleData(startSampleNr, endSampleNr uint32) ([]Sample, er
 		if ctts != nil {
 			cto = ctts.GetCompositionTimeOffset(nr)
 		}
-		samples[nr] = Sample{
+		samples[nr-startSampleNr] = Sample{
 			Flags:                 createSampleFlagsFromProgressiveBoxes(stss, sdtp, nr),
 			Dur:                   stts.GetDur(nr),
 			Size:                  stbl.Stsz.GetSampleSize(int(nr)),
@@ -150,6 +151,145 @@ func createSampleFlagsFromProgressiveBoxes(stss *StssBox, sdtp *SdtpBox, sampleN
 	return sampleFlags.Encode()
 }
 
+// DisplayDimensionsWithRotation - display width and height given by the dimensions of the first visual
+// sample entry with the tkhd matrix rotation applied, so that width and height are swapped for 90 and
+// 270 degree rotations. The result is 0, 0 if the track has no visual sample entry.
+func (t *TrakBox) DisplayDimensionsWithRotation() (w, h int) {
+	stsd := t.Mdia.Minf.Stbl.Stsd
+	if stsd == nil {
+		return 0, 0
+	}
+	for _, c := range stsd.Children {
+		vse, ok := c.(*VisualSampleEntryBox)
+		if !ok {
+			continue
+		}
+		w, h = int(vse.Width), int(vse.Height)
+		switch t.Tkhd.RotationDegrees() {
+		case 90, 270:
+			return h, w
+		}
+		return w, h
+	}
+	return 0, 0
+}
+
+// MaxSampleBytes - size in bytes of the largest sample in the track
+func (t *TrakBox) MaxSampleBytes() (uint32, error) {
+	stsz := t.Mdia.Minf.Stbl.Stsz
+	if stsz == nil {
+		return 0, fmt.Errorf("no stsz box")
+	}
+	if len(stsz.SampleSize) == 0 {
+		return stsz.SampleUniformSize, nil
+	}
+	var maxSize uint32
+	for _, size := range stsz.SampleSize {
+		if size > maxSize {
+			maxSize = size
+		}
+	}
+	return maxSize, nil
+}
+
+// MediaBitrate - average bitrate in bits per second of the sample data in the track, computed as the
+// total stsz sample size in bits divided by the total stts duration in seconds of the media timescale.
+// Container overhead is not included. Use File.FragmentedMediaBitrate for fragmented files.
+func (t *TrakBox) MediaBitrate() (uint32, error) {
+	samples, err := t.sampleSizesAndTimes()
+	if err != nil {
+		return 0, err
+	}
+	avg, _, err := calcBitrates(samples, t.Mdia.Mdhd.Timescale)
+	return avg, err
+}
+
+// MaxGopBytes - size in bytes of the largest GOP in the track, which approximates the decode buffer requirement.
+// A GOP starts at a sync sample given by stss and ends before the next one. Samples before the first sync sample
+// are counted as a GOP of their own. Without stss, all samples are sync samples and each GOP is one sample.
+func (t *TrakBox) MaxGopBytes() (uint32, error) {
+	stbl := t.Mdia.Minf.Stbl
+	if stbl.Stsz == nil {
+		return 0, fmt.Errorf("no stsz box")
+	}
+	if stbl.Stss == nil {
+		return t.MaxSampleBytes()
+	}
+	nrSamples := stbl.Stsz.GetNrSamples()
+	var maxSize uint64
+	gopStart := uint32(1)
+	for i := 0; i <= len(stbl.Stss.SampleNumber); i++ {
+		gopEnd := nrSamples
+		if i < len(stbl.Stss.SampleNumber) {
+			nextSync := stbl.Stss.SampleNumber[i]
+			if nextSync > nrSamples {
+				return 0, fmt.Errorf("sync sample %d outside range 1-%d", nextSync, nrSamples)
+			}
+			gopEnd = nextSync - 1
+		}
+		if gopEnd >= gopStart {
+			size, err := stbl.Stsz.GetTotalSampleSize(gopStart, gopEnd)
+			if err != nil {
+				return 0, err
+			}
+			if size > maxSize {
+				maxSize = size
+			}
+		}
+		gopStart = gopEnd + 1
+	}
+	if maxSize > math.MaxUint32 {
+		return 0, fmt.Errorf("GOP size %d too big", maxSize)
+	}
+	return uint32(maxSize), nil
+}
+
+// RetimeToFrameRate - set uniform sample durations in stts corresponding to fps in the media timescale.
+// The durations of mdhd and tkhd are scaled accordingly, as are the composition time offsets in ctts.
+// Edit lists and the mvhd duration are not changed. If timescale/fps is not an integer, the duration is
+// rounded, and drift is the absolute difference per sample from the exact duration in timescale units.
+// A non-zero drift is only a warning, and the track is retimed anyway.
+func (t *TrakBox) RetimeToFrameRate(fps float64) (drift float64, err error) {
+	if fps <= 0 {
+		return 0, fmt.Errorf("bad frame rate %f", fps)
+	}
+	stbl := t.Mdia.Minf.Stbl
+	if stbl.Stts == nil || stbl.Stsz == nil {
+		return 0, fmt.Errorf("no stts or stsz box")
+	}
+	nrSamples := stbl.Stsz.GetNrSamples()
+	if nrSamples == 0 {
+		return 0, fmt.Errorf("no samples in track")
+	}
+	mdhd := t.Mdia.Mdhd
+	exactDur := float64(mdhd.Timescale) / fps
+	sampleDur := uint32(math.Round(exactDur))
+	if sampleDur == 0 {
+		return 0, fmt.Errorf("frame rate %f too high for timescale %d", fps, mdhd.Timescale)
+	}
+	var oldTotal uint64
+	for i, count := range stbl.Stts.SampleCount {
+		oldTotal += uint64(count) * uint64(stbl.Stts.SampleTimeDelta[i])
+	}
+	newTotal := uint64(nrSamples) * uint64(sampleDur)
+	stbl.Stts.SampleCount = []uint32{nrSamples}
+	stbl.Stts.SampleTimeDelta = []uint32{sampleDur}
+	if oldTotal > 0 {
+		ratio := float64(newTotal) / float64(oldTotal)
+		if stbl.Ctts != nil {
+			for i, offset := range stbl.Ctts.SampleOffset {
+				stbl.Ctts.SampleOffset[i] = int32(math.Round(float64(offset) * ratio))
+			}
+		}
+		t.Tkhd.Duration = uint64(math.Round(float64(t.Tkhd.Duration) * ratio))
+	}
+	if newTotal > math.MaxUint32 {
+		mdhd.Version = 1
+	}
+	mdhd.Duration = newTotal
+	return math.Abs(float64(sampleDur) - exactDur), nil
+}
+
 // DataRange is a range for sample data in a file relative to file start
 type DataRange struct {
 	Offset uint64
@@ -204,3 +344,41 @@ func (t *TrakBox) GetRangesForSampleInterval(startSampleNr, endSampleNr uint32)
 	}
 	return dataRanges, nil
 }
+
+// addEmptyEdit - insert an empty edit of duration dur (movie timescale) first in the edit list.
+// An edts box is created after tkhd if not present, and an existing initial empty edit is extended.
+func (t *TrakBox) addEmptyEdit(dur uint64) {
+	emptyEdit := ElstEntry{SegmentDuration: dur, MediaTime: -1, MediaRateInteger: 1}
+	if t.Edts == nil || len(t.Edts.Elst) == 0 {
+		edts := &EdtsBox{}
+		edts.AddChild(&ElstBox{Entries: []ElstEntry{emptyEdit, {MediaTime: 0, MediaRateInteger: 1}}})
+		t.setEdts(edts)
+	} else {
+		elst := t.Edts.Elst[0]
+		if len(elst.Entries) > 0 && elst.Entries[0].MediaTime == -1 {
+			elst.Entries[0].SegmentDuration += dur
+		} else {
+			elst.Entries = append([]ElstEntry{emptyEdit}, elst.Entries...)
+		}
+	}
+	elst := t.Edts.Elst[0]
+	if elst.Entries[0].SegmentDuration > math.MaxUint32 {
+		elst.Version = 1
+	}
+}
+
+// setEdts - set edts box directly after tkhd, replacing any existing edts box
+func (t *TrakBox) setEdts(edts *EdtsBox) {
+	children := make([]Box, 0, len(t.Children)+1)
+	for _, c := range t.Children {
+		if c == t.Edts {
+			continue
+		}
+		children = append(children, c)
+		if c == t.Tkhd {
+			children = append(children, edts)
+		}
+	}
+	t.Children = children
+	t.Edts = edts
+}
@@ -0,0 +1,173 @@
+package mp4
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMaxGopBytes(t *testing.T) {
+	trak := CreateEmptyTrak(1, 90000, "video", "und")
+	stbl := trak.Mdia.Minf.Stbl
+	sizes := []uint32{1000, 100, 200, 1500, 300, 400, 500, 800, 50}
+	stbl.Stsz.SampleNumber = uint32(len(sizes))
+	stbl.Stsz.SampleSize = sizes
+	stss, err := CreateStss([]uint32{1, 4, 8}, uint32(len(sizes)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stbl.AddChild(stss)
+
+	// GOPs are samples 1-3 (1300), 4-7 (2700), and 8-9 (850)
+	gopBytes, err := trak.MaxGopBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gopBytes != 2700 {
+		t.Errorf("got max GOP bytes %d instead of 2700", gopBytes)
+	}
+	sampleBytes, err := trak.MaxSampleBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sampleBytes != 1500 {
+		t.Errorf("got max sample bytes %d instead of 1500", sampleBytes)
+	}
+
+	// Samples before first sync sample form a GOP of their own
+	stss.SampleNumber = []uint32{5}
+	gopBytes, err = trak.MaxGopBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gopBytes != 2800 {
+		t.Errorf("got max GOP bytes %d instead of 2800", gopBytes)
+	}
+
+	stss.SampleNumber = []uint32{1, 10}
+	if _, err = trak.MaxGopBytes(); err == nil {
+		t.Errorf("expected error for sync sample outside range")
+	}
+
+	// Without stss, every sample is a sync sample
+	stbl.Stss = nil
+	gopBytes, err = trak.MaxGopBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gopBytes != 1500 {
+		t.Errorf("got max GOP bytes %d without stss instead of 1500", gopBytes)
+	}
+}
+
+func TestGetSampleData(t *testing.T) {
+	trak := CreateEmptyTrak(1, 90000, "video", "und")
+	stbl := trak.Mdia.Minf.Stbl
+	sizes := []uint32{1000, 100, 200, 1500, 300}
+	stbl.Stsz.SampleNumber = uint32(len(sizes))
+	stbl.Stsz.SampleSize = sizes
+	stbl.Stts.SampleCount = []uint32{uint32(len(sizes))}
+	stbl.Stts.SampleTimeDelta = []uint32{3000}
+	// An interval not starting at the first sample, and ending at the last sample
+	samples, err := trak.GetSampleData(3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 3 {
+		t.Fatalf("got %d samples instead of 3", len(samples))
+	}
+	for i, s := range samples {
+		if s.Size != sizes[i+2] || s.Dur != 3000 {
+			t.Errorf("sample %d: got size %d and duration %d", i+3, s.Size, s.Dur)
+		}
+	}
+	if _, err = trak.GetSampleData(3, 6); err == nil {
+		t.Errorf("expected error for interval beyond last sample")
+	}
+}
+
+func TestRetimeToFrameRate(t *testing.T) {
+	trak := CreateEmptyTrak(1, 90000, "video", "und")
+	stbl := trak.Mdia.Minf.Stbl
+	nrSamples := uint32(30)
+	stbl.Stsz.SampleNumber = nrSamples
+	stbl.Stsz.SampleUniformSize = 100
+	stbl.Stts.SampleCount = []uint32{nrSamples}
+	stbl.Stts.SampleTimeDelta = []uint32{3000}
+	trak.Mdia.Mdhd.Duration = 90000
+	trak.Tkhd.Duration = 1000 // Movie timescale 1000
+
+	drift, err := trak.RetimeToFrameRate(25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if drift != 0 {
+		t.Errorf("got drift %f instead of 0", drift)
+	}
+	for nr := uint32(1); nr <= nrSamples; nr++ {
+		_, dur := stbl.Stts.GetDecodeTime(nr)
+		if dur != 3600 {
+			t.Fatalf("sample %d: got duration %d instead of 3600", nr, dur)
+		}
+	}
+	if trak.Mdia.Mdhd.Duration != 108000 {
+		t.Errorf("got mdhd duration %d instead of 108000", trak.Mdia.Mdhd.Duration)
+	}
+	if trak.Tkhd.Duration != 1200 {
+		t.Errorf("got tkhd duration %d instead of 1200", trak.Tkhd.Duration)
+	}
+
+	trak.Mdia.Mdhd.Timescale = 1000
+	drift, err = trak.RetimeToFrameRate(30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(drift-1.0/3) > 1e-9 {
+		t.Errorf("got drift %f instead of 1/3", drift)
+	}
+	if stbl.Stts.SampleTimeDelta[0] != 33 {
+		t.Errorf("got duration %d instead of 33", stbl.Stts.SampleTimeDelta[0])
+	}
+}
+
+func TestMediaBitrate(t *testing.T) {
+	trak := CreateEmptyTrak(1, 90000, "video", "und")
+	stbl := trak.Mdia.Minf.Stbl
+	if _, err := trak.MediaBitrate(); err == nil {
+		t.Errorf("expected error for track without samples")
+	}
+	stbl.Stsz.SampleNumber = 4
+	stbl.Stsz.SampleSize = []uint32{5000, 1000, 2000, 2000}
+	stbl.Stts.SampleCount = []uint32{2, 2}
+	stbl.Stts.SampleTimeDelta = []uint32{3000, 6000}
+	// 10000 bytes in 18000/90000 = 0.2s gives 80000 bits / 0.2s = 400000 bps
+	bitrate, err := trak.MediaBitrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bitrate != 400000 {
+		t.Errorf("got bitrate %d instead of 400000", bitrate)
+	}
+}
+
+func TestDisplayDimensionsWithRotation(t *testing.T) {
+	trak := CreateEmptyTrak(1, 90000, "video", "und")
+	trak.Mdia.Minf.Stbl.Stsd.AddChild(CreateVisualSampleEntryBox("avc1", 1920, 1080, nil))
+	w, h := trak.DisplayDimensionsWithRotation()
+	if w != 1920 || h != 1080 {
+		t.Errorf("got %dx%d instead of 1920x1080 without rotation", w, h)
+	}
+	// Portrait video recorded as landscape with 90-degree rotation matrix
+	trak.Tkhd.Matrix = [9]int32{0, 0x00010000, 0, -0x00010000, 0, 0, 1080 << 16, 0, 0x40000000}
+	trak = boxAfterEncodeAndDecode(t, trak).(*TrakBox)
+	if rot := trak.Tkhd.RotationDegrees(); rot != 90 {
+		t.Errorf("got rotation %d instead of 90", rot)
+	}
+	w, h = trak.DisplayDimensionsWithRotation()
+	if w != 1080 || h != 1920 {
+		t.Errorf("got %dx%d instead of 1080x1920 with 90-degree rotation", w, h)
+	}
+	trak.Tkhd.Matrix = [9]int32{0, -0x00010000, 0, 0x00010000, 0, 0, 0, 1920 << 16, 0x40000000}
+	if rot := trak.Tkhd.RotationDegrees(); rot != 270 {
+		t.Errorf("got rotation %d instead of 270", rot)
+	}
+}
@@ -0,0 +1,120 @@
+package mp4
+
+import "fmt"
+
+// TrexWarning - inefficient or suspicious use of trex default values in a fragmented file
+type TrexWarning struct {
+	TrackID        uint32
+	SequenceNumber uint32 // mfhd sequence number of the fragment, 0 for warnings about all fragments
+	Field          string // duration, size, or flags
+	Description    string
+}
+
+// trexOverrides - per-track count of fragments overriding the trex default of each field
+type trexOverrides struct {
+	nrFragments uint32
+	overrides   map[string]uint32
+}
+
+// CheckTrexUsage - check how the trex defaults of a fragmented file are used.
+// tfhd defaults equal to trex defaults, and trun per-sample values that are all equal to the applicable
+// default, are flagged as redundant. tfhd defaults that differ from trex in every fragment of a track
+// are flagged as a sign that the trex default is wrong. The first sample flags of a trun are not considered.
+func CheckTrexUsage(f *File) []TrexWarning {
+	var warnings []TrexWarning
+	if !f.isFragmented {
+		return nil
+	}
+	overrides := make(map[uint32]*trexOverrides)
+	var trackIDs []uint32
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			if frag.Moof == nil {
+				continue
+			}
+			seqNr := frag.Moof.Mfhd.SequenceNumber
+			for _, traf := range frag.Moof.Trafs {
+				tfhd := traf.Tfhd
+				trackID := tfhd.TrackID
+				trex := f.getTrex(trackID)
+				if trex == nil {
+					warnings = append(warnings, TrexWarning{TrackID: trackID, SequenceNumber: seqNr,
+						Description: "no trex for track"})
+					continue
+				}
+				to, ok := overrides[trackID]
+				if !ok {
+					to = &trexOverrides{overrides: make(map[string]uint32)}
+					overrides[trackID] = to
+					trackIDs = append(trackIDs, trackID)
+				}
+				to.nrFragments++
+				addWarning := func(field, format string, args ...interface{}) {
+					warnings = append(warnings, TrexWarning{TrackID: trackID, SequenceNumber: seqNr,
+						Field: field, Description: fmt.Sprintf(format, args...)})
+				}
+
+				defaultDur, defaultSize, defaultFlags := trex.DefaultSampleDuration, trex.DefaultSampleSize,
+					trex.DefaultSampleFlags
+				if tfhd.HasDefaultSampleDuration() {
+					if tfhd.DefaultSampleDuration == trex.DefaultSampleDuration {
+						addWarning("duration", "tfhd default sample duration %d equals trex default", defaultDur)
+					} else {
+						to.overrides["duration"]++
+					}
+					defaultDur = tfhd.DefaultSampleDuration
+				}
+				if tfhd.HasDefaultSampleSize() {
+					if tfhd.DefaultSampleSize == trex.DefaultSampleSize {
+						addWarning("size", "tfhd default sample size %d equals trex default", defaultSize)
+					} else {
+						to.overrides["size"]++
+					}
+					defaultSize = tfhd.DefaultSampleSize
+				}
+				if tfhd.HasDefaultSampleFlags() {
+					if tfhd.DefaultSampleFlags == trex.DefaultSampleFlags {
+						addWarning("flags", "tfhd default sample flags %08x equal trex default", defaultFlags)
+					} else {
+						to.overrides["flags"]++
+					}
+					defaultFlags = tfhd.DefaultSampleFlags
+				}
+
+				for i, trun := range traf.Truns {
+					if len(trun.Samples) == 0 {
+						continue
+					}
+					allDefaultDur, allDefaultSize, allDefaultFlags := true, true, true
+					for j, s := range trun.Samples {
+						allDefaultDur = allDefaultDur && s.Dur == defaultDur
+						allDefaultSize = allDefaultSize && s.Size == defaultSize
+						if j > 0 || !trun.HasFirstSampleFlags() {
+							allDefaultFlags = allDefaultFlags && s.Flags == defaultFlags
+						}
+					}
+					if trun.HasSampleDuration() && allDefaultDur {
+						addWarning("duration", "trun %d: all sample durations equal default %d", i+1, defaultDur)
+					}
+					if trun.HasSampleSize() && allDefaultSize {
+						addWarning("size", "trun %d: all sample sizes equal default %d", i+1, defaultSize)
+					}
+					if trun.HasSampleFlags() && allDefaultFlags {
+						addWarning("flags", "trun %d: all sample flags equal default %08x", i+1, defaultFlags)
+					}
+				}
+			}
+		}
+	}
+	for _, trackID := range trackIDs {
+		to := overrides[trackID]
+		for _, field := range []string{"duration", "size", "flags"} {
+			if to.nrFragments > 1 && to.overrides[field] == to.nrFragments {
+				warnings = append(warnings, TrexWarning{TrackID: trackID, Field: field,
+					Description: fmt.Sprintf("tfhd overrides trex default sample %s in all %d fragments",
+						field, to.nrFragments)})
+			}
+		}
+	}
+	return warnings
+}
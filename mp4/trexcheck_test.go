@@ -0,0 +1,64 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCheckTrexUsage(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.Moov.Mvex.Trex.DefaultSampleDuration = 3000
+	var buf bytes.Buffer
+	if err := init.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	nrFrags := 2
+	for i := 0; i < nrFrags; i++ {
+		frag, err := CreateFragment(uint32(i+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < 3; j++ {
+			frag.AddFullSample(FullSample{
+				Sample:     NewSample(NonSyncSampleFlags, 3000, uint32(j+1), 0),
+				DecodeTime: uint64((3*i + j) * 3000),
+				Data:       make([]byte, j+1),
+			})
+		}
+		// Default sample flags that differ from trex in all fragments
+		tfhd := frag.Moof.Traf.Tfhd
+		tfhd.Flags |= defaultSampleFlagsPresent
+		tfhd.DefaultSampleFlags = NonSyncSampleFlags
+		if err = frag.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings := CheckTrexUsage(f)
+	var nrDurWarnings, nrFlagWarnings, nrOverrideWarnings int
+	for _, w := range warnings {
+		switch {
+		case w.Field == "duration" && w.SequenceNumber != 0:
+			nrDurWarnings++
+		case w.Field == "flags" && w.SequenceNumber != 0:
+			nrFlagWarnings++
+		case w.Field == "flags" && w.SequenceNumber == 0:
+			nrOverrideWarnings++
+		default:
+			t.Errorf("unexpected warning %+v", w)
+		}
+	}
+	if nrDurWarnings != nrFrags {
+		t.Errorf("got %d redundant duration warnings instead of %d", nrDurWarnings, nrFrags)
+	}
+	if nrFlagWarnings != nrFrags {
+		t.Errorf("got %d redundant sample flags warnings instead of %d", nrFlagWarnings, nrFrags)
+	}
+	if nrOverrideWarnings != 1 {
+		t.Errorf("got %d override warnings instead of 1", nrOverrideWarnings)
+	}
+}
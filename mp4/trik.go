@@ -0,0 +1,114 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// TrikBox - Trick Play Box (trik - optional)
+//
+// Defined in DECE Common File Format Section 2.2.7
+// Contained in Track Fragment Box (traf)
+//
+// Table with picture type and dependency level of every sample in the track fragment
+type TrikBox struct {
+	Version byte
+	Flags   uint32
+	Entries []TrikEntry
+}
+
+// TrikEntry - picture type and dependency level of a sample
+type TrikEntry struct {
+	// PicType (2 bits) - 0: unknown, 1: IDR picture, 2: random access (non-IDR I) picture, 3: unconstrained I-picture
+	PicType uint8
+	// DependencyLevel (6 bits) - 0: unknown, 1-62: level in dependency hierarchy, 63: reserved
+	DependencyLevel uint8
+}
+
+// DecodeTrik - box-specific decode
+func DecodeTrik(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeTrikSR(hdr, startPos, sr)
+}
+
+// DecodeTrikSR - box-specific decode
+func DecodeTrikSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	version := byte(versionAndFlags >> 24)
+	flags := versionAndFlags & flagsMask
+
+	// Sample count is given by trun. Use rest of payload
+	entries := make([]TrikEntry, hdr.payloadLen()-4)
+	for i := range entries {
+		b := sr.ReadUint8()
+		entries[i] = TrikEntry{PicType: b >> 6, DependencyLevel: b & 0x3f}
+	}
+
+	return &TrikBox{
+		Version: version,
+		Flags:   flags,
+		Entries: entries,
+	}, sr.AccError()
+}
+
+// Entry - return entry for one-based sample number n. Zero entry (unknown) if out of range
+func (b *TrikBox) Entry(n uint32) TrikEntry {
+	if n == 0 || int(n) > len(b.Entries) {
+		return TrikEntry{}
+	}
+	return b.Entries[n-1]
+}
+
+// Type - return box type
+func (b *TrikBox) Type() string {
+	return "trik"
+}
+
+// Size - return calculated size
+func (b *TrikBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + len(b.Entries))
+}
+
+// Encode - write box to w
+func (b *TrikBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *TrikBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+
+	for _, entry := range b.Entries {
+		sw.WriteUint8(entry.PicType<<6 | entry.DependencyLevel&0x3f)
+	}
+
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *TrikBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	level := getInfoLevel(b, specificBoxLevels)
+	if level >= 1 {
+		for i, entry := range b.Entries {
+			bd.write(" - entry[%d]: picType=%d dependencyLevel=%d", i+1, entry.PicType, entry.DependencyLevel)
+		}
+	}
+	return bd.err
+}
@@ -0,0 +1,59 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTrik(t *testing.T) {
+	entries := []TrikEntry{
+		{PicType: 1, DependencyLevel: 1},
+		{PicType: 0, DependencyLevel: 3},
+		{PicType: 0, DependencyLevel: 2},
+		{PicType: 0, DependencyLevel: 3},
+	}
+	trik := &TrikBox{Entries: entries}
+	boxDiffAfterEncodeAndDecode(t, trik)
+
+	// Trick-play segment with trik box in traf
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range entries {
+		flags := NonSyncSampleFlags
+		if i == 0 {
+			flags = SyncSampleFlags
+		}
+		frag.AddFullSample(FullSample{
+			Sample:     NewSample(flags, 1000, 2, 0),
+			DecodeTime: uint64(i * 1000),
+			Data:       []byte{byte(i), byte(i)},
+		})
+	}
+	err = frag.Moof.Traf.AddChild(trik)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moof, err := DecodeBox(0, bytes.NewBuffer(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decTrik := moof.(*MoofBox).Traf.Trik
+	if decTrik == nil {
+		t.Fatalf("trik not decoded in traf")
+	}
+	for i := range entries {
+		if decTrik.Entry(uint32(i+1)) != entries[i] {
+			t.Errorf("entry %d: got %+v instead of %+v", i+1, decTrik.Entry(uint32(i+1)), entries[i])
+		}
+	}
+	if decTrik.Entry(uint32(len(entries)+1)) != (TrikEntry{}) {
+		t.Errorf("expected unknown entry beyond sample count")
+	}
+}
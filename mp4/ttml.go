@@ -0,0 +1,38 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+)
+
+// TTMLSample - TTML document from one stpp sample with timing in track timescale
+type TTMLSample struct {
+	PresentationTime uint64
+	Duration         uint32
+	Data             []byte // XML document
+}
+
+// ExtractTTML - extract the TTML documents of the stpp track trackID.
+// Every sample must be a complete TTML document. Use rs if the mdat of a progressive file is lazily decoded.
+func (f *File) ExtractTTML(trackID uint32, rs io.ReadSeeker) ([]TTMLSample, error) {
+	trak := f.getTrak(trackID)
+	if trak == nil {
+		return nil, fmt.Errorf("no track with trackID %d", trackID)
+	}
+	if trak.Mdia.Minf.Stbl.Stsd.Stpp == nil {
+		return nil, fmt.Errorf("track %d is not an stpp track", trackID)
+	}
+	samples, err := f.fullSamplesForTrack(trackID, rs)
+	if err != nil {
+		return nil, err
+	}
+	ttmlSamples := make([]TTMLSample, 0, len(samples))
+	for i := range samples {
+		ttmlSamples = append(ttmlSamples, TTMLSample{
+			PresentationTime: samples[i].PresentationTime(),
+			Duration:         samples[i].Dur,
+			Data:             samples[i].Data,
+		})
+	}
+	return ttmlSamples, nil
+}
@@ -0,0 +1,70 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExtractTTML(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(1000, "subtitle", "en")
+	trak := init.Moov.Trak
+	err := trak.SetStppDescriptor("http://www.w3.org/ns/ttml", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := [][]byte{
+		[]byte(`<?xml version="1.0" encoding="UTF-8"?><tt xmlns="http://www.w3.org/ns/ttml"><body><div>` +
+			`<p begin="00:00:00.000" end="00:00:02.000">First cue</p></div></body></tt>`),
+		[]byte(`<?xml version="1.0" encoding="UTF-8"?><tt xmlns="http://www.w3.org/ns/ttml"><body><div>` +
+			`<p begin="00:00:02.000" end="00:00:03.500">Second cue</p></div></body></tt>`),
+	}
+	durs := []uint32{2000, 1500}
+	frag, err := CreateFragment(1, trak.Tkhd.TrackID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decTime uint64
+	for i, doc := range docs {
+		frag.AddFullSample(FullSample{
+			Sample:     NewSample(SyncSampleFlags, durs[i], uint32(len(doc)), 0),
+			DecodeTime: decTime,
+			Data:       doc,
+		})
+		decTime += uint64(durs[i])
+	}
+	buf := bytes.Buffer{}
+	err = init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ttmlSamples, err := f.ExtractTTML(trak.Tkhd.TrackID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ttmlSamples) != len(docs) {
+		t.Fatalf("got %d samples instead of %d", len(ttmlSamples), len(docs))
+	}
+	var expectedTime uint64
+	for i, s := range ttmlSamples {
+		if !bytes.Equal(s.Data, docs[i]) {
+			t.Errorf("sample %d: got document %q", i+1, s.Data)
+		}
+		if s.PresentationTime != expectedTime || s.Duration != durs[i] {
+			t.Errorf("sample %d: got time %d and duration %d", i+1, s.PresentationTime, s.Duration)
+		}
+		expectedTime += uint64(durs[i])
+	}
+	_, err = f.ExtractTTML(2, nil)
+	if err == nil {
+		t.Errorf("expected error for non-existing track")
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"github.com/edgeware/mp4ff/hevc"
 )
 
-// VisualSampleEntryBox - Video Sample Description box (avc1/avc3)
+// VisualSampleEntryBox - Video Sample Description box (avc1/avc3/hvc1/hev1/dvh1/dvhe/mp4v/resv)
 type VisualSampleEntryBox struct {
 	name               string
 	DataReferenceIndex uint16
@@ -20,10 +20,13 @@ type VisualSampleEntryBox struct {
 	CompressorName     string
 	AvcC               *AvcCBox
 	HvcC               *HvcCBox
+	DvcC               *DvcCBox // Dolby Vision configuration (dvcC, dvvC, or dvwC)
+	Esds               *EsdsBox
 	Btrt               *BtrtBox
 	Clap               *ClapBox
 	Pasp               *PaspBox
 	Sinf               *SinfBox
+	Rinf               *RinfBox
 	Children           []Box
 }
 
@@ -60,6 +63,10 @@ func (b *VisualSampleEntryBox) AddChild(child Box) {
 		b.AvcC = box
 	case *HvcCBox:
 		b.HvcC = box
+	case *DvcCBox:
+		b.DvcC = box
+	case *EsdsBox:
+		b.Esds = box
 	case *BtrtBox:
 		b.Btrt = box
 	case *ClapBox:
@@ -68,6 +75,8 @@ func (b *VisualSampleEntryBox) AddChild(child Box) {
 		b.Pasp = box
 	case *SinfBox:
 		b.Sinf = box
+	case *RinfBox:
+		b.Rinf = box
 	}
 
 	b.Children = append(b.Children, child)
@@ -267,6 +276,26 @@ func (b *VisualSampleEntryBox) RemoveEncryption() (*SinfBox, error) {
 	return sinf, nil
 }
 
+// RemoveRestriction - remove rinf box and set type to the original format of the restricted resv entry
+func (b *VisualSampleEntryBox) RemoveRestriction() (*RinfBox, error) {
+	if b.name != "resv" {
+		return nil, fmt.Errorf("is not restricted: %s", b.name)
+	}
+	rinf := b.Rinf
+	if rinf == nil || rinf.Frma == nil {
+		return nil, fmt.Errorf("does not have rinf box with frma")
+	}
+	for i := range b.Children {
+		if b.Children[i].Type() == "rinf" {
+			b.Children = append(b.Children[:i], b.Children[i+1:]...)
+			b.Rinf = nil
+			break
+		}
+	}
+	b.name = rinf.Frma.DataFormat
+	return rinf, nil
+}
+
 // ConvertHev1ToHvc1 - contert visual sample entry box type and insert VPS, SPS, and PPS parameter sets
 func (b *VisualSampleEntryBox) ConvertHev1ToHvc1(vpss [][]byte, spss [][]byte, ppss [][]byte) error {
 	if b.Type() != "hev1" {
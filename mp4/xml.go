@@ -0,0 +1,158 @@
+package mp4
+
+import (
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// XmlBox - XML Box as defined in ISO/IEC 14496-12 2020 Section 8.11.2
+//
+// Contained in : Meta Box (meta)
+type XmlBox struct {
+	Version              byte
+	Flags                uint32
+	XML                  string // UTF-8 text
+	LacksZeroTermination bool   // Handle non-compliant case as well
+}
+
+// DecodeXml - box-specific decode
+func DecodeXml(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeXmlSR(hdr, startPos, sr)
+}
+
+// DecodeXmlSR - box-specific decode
+func DecodeXmlSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := XmlBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	rest := sr.ReadBytes(hdr.payloadLen() - 4)
+	if len(rest) > 0 && rest[len(rest)-1] == 0 { // zero-termination
+		b.XML = string(rest[:len(rest)-1])
+	} else {
+		b.XML = string(rest)
+		b.LacksZeroTermination = true
+	}
+	return &b, sr.AccError()
+}
+
+// Type - box type
+func (b *XmlBox) Type() string {
+	return "xml "
+}
+
+// Size - calculated size of box
+func (b *XmlBox) Size() uint64 {
+	size := uint64(boxHeaderSize + 4 + len(b.XML) + 1)
+	if b.LacksZeroTermination {
+		size--
+	}
+	return size
+}
+
+// Encode - write box to w
+func (b *XmlBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *XmlBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteString(b.XML, !b.LacksZeroTermination)
+	return sw.AccError()
+}
+
+// Info - write specific box information
+func (b *XmlBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - xml: %s", b.XML)
+	return bd.err
+}
+
+// BxmlBox - Binary XML Box as defined in ISO/IEC 14496-12 2020 Section 8.11.2
+//
+// Contained in : Meta Box (meta)
+type BxmlBox struct {
+	Version byte
+	Flags   uint32
+	Data    []byte // Binary encoded XML
+}
+
+// DecodeBxml - box-specific decode
+func DecodeBxml(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeBxmlSR(hdr, startPos, sr)
+}
+
+// DecodeBxmlSR - box-specific decode
+func DecodeBxmlSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := BxmlBox{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+		Data:    sr.ReadBytes(hdr.payloadLen() - 4),
+	}
+	return &b, sr.AccError()
+}
+
+// Type - box type
+func (b *BxmlBox) Type() string {
+	return "bxml"
+}
+
+// Size - calculated size of box
+func (b *BxmlBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + len(b.Data))
+}
+
+// Encode - write box to w
+func (b *BxmlBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *BxmlBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteBytes(b.Data)
+	return sw.AccError()
+}
+
+// Info - write specific box information
+func (b *BxmlBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - dataLength: %d", len(b.Data))
+	return bd.err
+}